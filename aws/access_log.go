@@ -0,0 +1,482 @@
+package aws
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+
+	cloudwatchlogsv1 "github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// accessLogDefinition declares a table of the records of a log format, such
+// as ALB access logs, that are read from CloudWatch Logs or from log files in
+// S3 or on the local file system.
+type accessLogDefinition struct {
+	Name        string
+	Description string
+
+	// The columns of the fields of a record. Columns without a transform are
+	// populated from the field of the same name.
+	Columns []*plugin.Column
+
+	// Parse splits a line of the log into its fields, keyed by column name,
+	// and returns the time of the record. Lines that are not records, e.g.
+	// the comment headers of CloudFront logs, return ok as false.
+	Parse func(line string) (fields map[string]interface{}, timestamp time.Time, ok bool)
+
+	// FilterFields are the columns whose equality quals are pushed down into
+	// CloudWatch Logs filter patterns, mapped to their JSON selector, e.g.
+	// $.action, or to "" for space-delimited formats, where the value is
+	// matched as a term.
+	FilterFields map[string]string
+}
+
+type accessLogRow struct {
+	Fields        map[string]interface{}
+	Timestamp     time.Time
+	Message       string
+	Source        string
+	LogStreamName *string
+}
+
+// Log lines, e.g. of WAF logs with large request headers, can be far longer
+// than bufio.Scanner's default limit
+const accessLogMaxLineSize = 16 * 1024 * 1024
+
+// accessLogTable generates the table for an access log definition
+func accessLogTable(def accessLogDefinition) *plugin.Table {
+	keyColumns := plugin.KeyColumnSlice{
+		{Name: "log_group_name", Require: plugin.AnyOf},
+		{Name: "bucket_name", Require: plugin.AnyOf, CacheMatch: "exact"},
+		{Name: "path", Require: plugin.AnyOf, CacheMatch: "exact"},
+		{Name: "prefix", Require: plugin.Optional, CacheMatch: "exact"},
+		{Name: "log_stream_name", Require: plugin.Optional},
+		{Name: "filter", Require: plugin.Optional, CacheMatch: "exact"},
+		{Name: "timestamp", Require: plugin.Optional, Operators: []string{">", ">=", "=", "<", "<="}},
+	}
+
+	var columns []*plugin.Column
+	for _, c := range def.Columns {
+		column := *c
+		if column.Transform == nil {
+			column.Transform = transform.FromField("Fields." + column.Name)
+		}
+		columns = append(columns, &column)
+
+		if _, ok := def.FilterFields[column.Name]; ok {
+			keyColumns = append(keyColumns, &plugin.KeyColumn{Name: column.Name, Require: plugin.Optional})
+		}
+	}
+
+	columns = append(columns, []*plugin.Column{
+		{
+			Name:        "timestamp",
+			Description: "The time of the log record.",
+			Type:        proto.ColumnType_TIMESTAMP,
+		},
+		{
+			Name:        "message",
+			Description: "The log record as written by the service.",
+			Type:        proto.ColumnType_STRING,
+		},
+		{
+			Name:        "log_group_name",
+			Description: "The name of the CloudWatch Logs log group to read the records from.",
+			Type:        proto.ColumnType_STRING,
+			Transform:   transform.FromQual("log_group_name"),
+		},
+		{
+			Name:        "log_stream_name",
+			Description: "The name of the log stream that the record was read from.",
+			Type:        proto.ColumnType_STRING,
+		},
+		{
+			Name:        "filter",
+			Description: "A CloudWatch Logs filter pattern for the records to read. Overrides the filter pattern built from the quals of the table.",
+			Type:        proto.ColumnType_STRING,
+			Transform:   transform.FromQual("filter"),
+		},
+		{
+			Name:        "bucket_name",
+			Description: "The name of the S3 bucket to read the log files from.",
+			Type:        proto.ColumnType_STRING,
+			Transform:   transform.FromQual("bucket_name"),
+		},
+		{
+			Name:        "prefix",
+			Description: "The key prefix of the log files in the S3 bucket.",
+			Type:        proto.ColumnType_STRING,
+			Transform:   transform.FromQual("prefix"),
+		},
+		{
+			Name:        "path",
+			Description: "The path of a directory on the local file system to read the log files from. It must be within one of the local_file_paths of the connection config.",
+			Type:        proto.ColumnType_STRING,
+			Transform:   transform.FromQual("path"),
+		},
+		{
+			Name:        "source",
+			Description: "The S3 key or local path of the log file that the record was read from.",
+			Type:        proto.ColumnType_STRING,
+		},
+	}...)
+
+	return &plugin.Table{
+		Name:        def.Name,
+		Description: def.Description,
+		List: &plugin.ListConfig{
+			Hydrate:    def.listRecords,
+			Tags:       map[string]string{"service": "logs", "action": "FilterLogEvents"},
+			KeyColumns: keyColumns,
+		},
+		GetMatrixItemFunc: SupportedRegionMatrix(cloudwatchlogsv1.EndpointsID),
+		Columns:           awsRegionalColumns(columns),
+	}
+}
+
+func (def accessLogDefinition) listRecords(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	if d.EqualsQualString("log_group_name") != "" {
+		return def.listLogEventRecords(ctx, d)
+	}
+	return def.listFileRecords(ctx, d)
+}
+
+// listLogEventRecords streams the records of a CloudWatch Logs log group
+func (def accessLogDefinition) listLogEventRecords(ctx context.Context, d *plugin.QueryData) (interface{}, error) {
+	// Get client
+	svc, err := CloudWatchLogsClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("accessLogDefinition.listLogEventRecords", "table", def.Name, "get_client_error", err)
+		return nil, err
+	}
+
+	params := &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName: aws.String(d.EqualsQualString("log_group_name")),
+		Limit:        aws.Int32(10000),
+	}
+
+	if d.EqualsQualString("log_stream_name") != "" {
+		params.LogStreamNames = []string{d.EqualsQualString("log_stream_name")}
+	}
+
+	if d.EqualsQualString("filter") != "" {
+		params.FilterPattern = aws.String(d.EqualsQualString("filter"))
+	} else if filterPattern := def.buildFilterPattern(d.EqualsQuals); filterPattern != "" {
+		params.FilterPattern = aws.String(filterPattern)
+	}
+
+	if d.Quals["timestamp"] != nil {
+		for _, q := range d.Quals["timestamp"].Quals {
+			tsMs := q.Value.GetTimestampValue().AsTime().UnixMilli()
+			switch q.Operator {
+			case "=":
+				params.StartTime = aws.Int64(tsMs)
+				params.EndTime = aws.Int64(tsMs)
+			case ">=", ">":
+				params.StartTime = aws.Int64(tsMs)
+			case "<", "<=":
+				params.EndTime = aws.Int64(tsMs)
+			}
+		}
+	}
+
+	paginator := cloudwatchlogs.NewFilterLogEventsPaginator(svc, params, func(o *cloudwatchlogs.FilterLogEventsPaginatorOptions) {
+		o.StopOnDuplicateToken = true
+	})
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Error("accessLogDefinition.listLogEventRecords", "table", def.Name, "api_error", err)
+			return nil, err
+		}
+
+		for _, event := range output.Events {
+			fields, timestamp, ok := def.Parse(aws.ToString(event.Message))
+			if !ok {
+				continue
+			}
+			if timestamp.IsZero() {
+				timestamp = time.UnixMilli(aws.ToInt64(event.Timestamp))
+			}
+
+			d.StreamListItem(ctx, accessLogRow{
+				Fields:        fields,
+				Timestamp:     timestamp,
+				Message:       aws.ToString(event.Message),
+				LogStreamName: event.LogStreamName,
+			})
+
+			// Context may get cancelled due to manual cancellation or if the limit has been reached
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// listFileRecords streams the records of the log files in an S3 bucket or a
+// local directory
+func (def accessLogDefinition) listFileRecords(ctx context.Context, d *plugin.QueryData) (interface{}, error) {
+	region := d.EqualsQualString(matrixKeyRegion)
+
+	// The files are read once, in the region of the bucket, or in one of the
+	// queried regions if the bucket's region is not queried
+	var sourceRegion string
+	if bucketName := d.EqualsQualString("bucket_name"); bucketName != "" {
+		bucketRegion, err := getS3BucketRegion(ctx, d, bucketName)
+		if err != nil {
+			plugin.Logger(ctx).Error("accessLogDefinition.listFileRecords", "table", def.Name, "bucket_region_error", err)
+			return nil, err
+		}
+		sourceRegion = bucketRegion
+	}
+	if sourceRegion = accessLogReadRegion(ctx, d, sourceRegion); sourceRegion != region {
+		return nil, nil
+	}
+
+	var source *fileSource
+	if d.EqualsQualString("path") != "" {
+		var err error
+		source, err = newAllowedLocalFileSource(d, d.EqualsQualString("path"))
+		if err != nil {
+			plugin.Logger(ctx).Error("accessLogDefinition.listFileRecords", "table", def.Name, "path_error", err)
+			return nil, err
+		}
+	} else {
+		svc, err := S3Client(ctx, d, region)
+		if err != nil {
+			plugin.Logger(ctx).Error("accessLogDefinition.listFileRecords", "table", def.Name, "client_error", err)
+			return nil, err
+		}
+		source = newS3FileSource(ctx, d, svc, d.EqualsQualString("bucket_name"), d.EqualsQualString("prefix"))
+	}
+
+	names, err := source.list()
+	if err != nil {
+		plugin.Logger(ctx).Error("accessLogDefinition.listFileRecords", "table", def.Name, "list_error", err)
+		return nil, err
+	}
+
+	for _, name := range names {
+		done, err := def.streamFileRecords(ctx, d, source, name)
+		if err != nil {
+			plugin.Logger(ctx).Error("accessLogDefinition.listFileRecords", "table", def.Name, "read_error", err, "source", name)
+			return nil, err
+		}
+		if done {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// streamFileRecords streams the records of a single log file, which may be
+// compressed. It returns true once no more rows are required.
+func (def accessLogDefinition) streamFileRecords(ctx context.Context, d *plugin.QueryData, source *fileSource, name string) (bool, error) {
+	reader, err := source.openDecompressed(name)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), accessLogMaxLineSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields, timestamp, ok := def.Parse(line)
+		if !ok {
+			continue
+		}
+
+		d.StreamListItem(ctx, accessLogRow{
+			Fields:    fields,
+			Timestamp: timestamp,
+			Message:   line,
+			Source:    name,
+		})
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+// accessLogReadRegion returns the region to read log files in. That is the
+// region of the files if it is queried, and otherwise the alphabetically
+// first region of the table's region matrix.
+func accessLogReadRegion(ctx context.Context, d *plugin.QueryData, sourceRegion string) string {
+	var first string
+	for _, item := range SupportedRegionMatrix(cloudwatchlogsv1.EndpointsID)(ctx, d) {
+		region := item[matrixKeyRegion].(string)
+		if region == sourceRegion {
+			return region
+		}
+		if first == "" || region < first {
+			first = region
+		}
+	}
+	return first
+}
+
+// buildFilterPattern builds a CloudWatch Logs filter pattern from the
+// equality quals of the filter fields of the table
+func (def accessLogDefinition) buildFilterPattern(equalQuals plugin.KeyColumnEqualsQualMap) string {
+	var terms, conditions []string
+	for column, selector := range def.FilterFields {
+		qual := equalQuals[column]
+		if qual == nil {
+			continue
+		}
+
+		var value string
+		quoted := true
+		switch v := qual.Value.(type) {
+		case *proto.QualValue_StringValue:
+			value = v.StringValue
+		case *proto.QualValue_InetValue:
+			value = v.InetValue.Addr
+		case *proto.QualValue_Int64Value:
+			value = strconv.FormatInt(v.Int64Value, 10)
+			quoted = false
+		default:
+			continue
+		}
+
+		if selector == "" {
+			terms = append(terms, strconv.Quote(value))
+		} else if quoted {
+			conditions = append(conditions, fmt.Sprintf("%s = %s", selector, strconv.Quote(value)))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("%s = %s", selector, value))
+		}
+	}
+
+	// Map iteration order is random, so sort to keep the pattern, which is
+	// part of the cache key of the request, stable
+	sort.Strings(terms)
+	sort.Strings(conditions)
+
+	if len(conditions) > 0 {
+		return "{ " + strings.Join(conditions, " && ") + " }"
+	}
+	return strings.Join(terms, " ")
+}
+
+//// PARSING HELPERS
+
+// splitAccessLogLine splits a space-delimited log line into its fields.
+// Fields may be "quoted", with backslash escapes, or [bracketed].
+func splitAccessLogLine(line string) []string {
+	var fields []string
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ':
+			i++
+		case '"':
+			var field strings.Builder
+			j := i + 1
+			for ; j < len(line) && line[j] != '"'; j++ {
+				if line[j] == '\\' && j+1 < len(line) {
+					j++
+				}
+				field.WriteByte(line[j])
+			}
+			// Lists of quoted values, e.g. "h2","http/1.1" in NLB logs, are a
+			// single field
+			if j+1 < len(line) && line[j+1] != ' ' {
+				k := strings.IndexByte(line[j:], ' ')
+				if k < 0 {
+					k = len(line) - j
+				}
+				fields = append(fields, line[i:j+k])
+				i = j + k
+				continue
+			}
+			fields = append(fields, field.String())
+			i = j + 1
+		case '[':
+			j := strings.IndexByte(line[i:], ']')
+			if j < 0 {
+				j = len(line) - i
+			}
+			fields = append(fields, line[i+1:i+j])
+			i += j + 1
+		default:
+			j := strings.IndexByte(line[i:], ' ')
+			if j < 0 {
+				j = len(line) - i
+			}
+			fields = append(fields, line[i:i+j])
+			i += j
+		}
+	}
+	return fields
+}
+
+// accessLogFields maps the values of a record to the field names of the log
+// format, in order. Names that are empty are skipped, and values of "-" are
+// null.
+func accessLogFields(names []string, values []string) map[string]interface{} {
+	fields := map[string]interface{}{}
+	for i, name := range names {
+		if name == "" || i >= len(values) || values[i] == "-" {
+			continue
+		}
+		fields[name] = values[i]
+	}
+	return fields
+}
+
+// splitAccessLogAddress splits the ip:port field of a record into fields,
+// e.g. client_ip and client_port
+func splitAccessLogAddress(fields map[string]interface{}, field string, ipName string, portName string) {
+	address, ok := fields[field].(string)
+	if !ok {
+		return
+	}
+	delete(fields, field)
+
+	i := strings.LastIndexByte(address, ':')
+	if i < 0 {
+		fields[ipName] = strings.Trim(address, "[]")
+		return
+	}
+	fields[ipName] = strings.Trim(address[:i], "[]")
+	fields[portName] = address[i+1:]
+}
+
+// splitAccessLogRequest splits an HTTP request line, e.g. "GET / HTTP/1.1",
+// into its method, URL and protocol version fields
+func splitAccessLogRequest(fields map[string]interface{}, field string, prefix string) {
+	request, ok := fields[field].(string)
+	if !ok {
+		return
+	}
+	parts := strings.SplitN(request, " ", 3)
+	names := []string{prefix + "method", prefix + "url", prefix + "http_version"}
+	for i, part := range parts {
+		if part != "-" && part != "" {
+			fields[names[i]] = part
+		}
+	}
+}
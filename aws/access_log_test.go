@@ -0,0 +1,266 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitAccessLogLine(t *testing.T) {
+	cases := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"plain", "a b c", []string{"a", "b", "c"}},
+		{"repeated spaces", "a  b   c ", []string{"a", "b", "c"}},
+		{"quoted", `a "GET / HTTP/1.1" c`, []string{"a", "GET / HTTP/1.1", "c"}},
+		{"escaped quote", `a "agent \"x\" 1.0" c`, []string{"a", `agent "x" 1.0`, "c"}},
+		{"empty quoted", `a "" c`, []string{"a", "", "c"}},
+		{"quoted dash", `a "-" c`, []string{"a", "-", "c"}},
+		{"quoted list", `a "h2","http/1.1" c`, []string{"a", `"h2","http/1.1"`, "c"}},
+		{"bracketed", "a [06/Feb/2019:00:00:38 +0000] c", []string{"a", "06/Feb/2019:00:00:38 +0000", "c"}},
+		{"unterminated quote", `a "GET / HTTP`, []string{"a", "GET / HTTP"}},
+		{"unterminated bracket", "a [06/Feb/2019", []string{"a", "06/Feb/2019"}},
+		{"empty", "", nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := splitAccessLogLine(c.line); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("splitAccessLogLine(%q) = %q, want %q", c.line, got, c.want)
+			}
+		})
+	}
+}
+
+type accessLogParseCase struct {
+	name          string
+	line          string
+	ok            bool
+	timestamp     time.Time
+	fields        map[string]interface{}
+	missingFields []string
+}
+
+func testAccessLogParse(t *testing.T, parse func(string) (map[string]interface{}, time.Time, bool), cases []accessLogParseCase) {
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fields, timestamp, ok := parse(c.line)
+			if ok != c.ok {
+				t.Fatalf("ok = %v, want %v", ok, c.ok)
+			}
+			if !ok {
+				return
+			}
+			if !timestamp.Equal(c.timestamp) {
+				t.Errorf("timestamp = %v, want %v", timestamp, c.timestamp)
+			}
+			for name, want := range c.fields {
+				if got := fields[name]; !reflect.DeepEqual(got, want) {
+					t.Errorf("fields[%q] = %#v, want %#v", name, got, want)
+				}
+			}
+			for _, name := range c.missingFields {
+				if got, ok := fields[name]; ok {
+					t.Errorf("fields[%q] = %#v, want no value", name, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseApplicationLoadBalancerAccessLog(t *testing.T) {
+	testAccessLogParse(t, parseApplicationLoadBalancerAccessLog, []accessLogParseCase{
+		{
+			name:      "forwarded request",
+			line:      `https 2018-07-02T22:23:00.186641Z app/my-loadbalancer/50dc6c495c0c9188 192.168.131.39:2817 10.0.0.1:80 0.086 0.048 0.037 200 200 0 57 "GET https://www.example.com:443/ HTTP/1.1" "curl/7.46.0" ECDHE-RSA-AES128-GCM-SHA256 TLSv1.2 arn:aws:elasticloadbalancing:us-east-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067 "Root=1-58337281-1d84f3d73c47ec4e58577259" "www.example.com" "arn:aws:acm:us-east-2:123456789012:certificate/12345678-1234-1234-1234-123456789012" 1 2018-07-02T22:22:48.364000Z "authenticate,forward" "-" "-" "10.0.0.1:80" "200" "-" "-"`,
+			ok:        true,
+			timestamp: time.Date(2018, 7, 2, 22, 23, 0, 186641000, time.UTC),
+			fields: map[string]interface{}{
+				"type":                 "https",
+				"client_ip":            "192.168.131.39",
+				"client_port":          "2817",
+				"target_ip":            "10.0.0.1",
+				"target_port":          "80",
+				"elb_status_code":      "200",
+				"request_method":       "GET",
+				"request_url":          "https://www.example.com:443/",
+				"request_http_version": "HTTP/1.1",
+				"user_agent":           "curl/7.46.0",
+				"domain_name":          "www.example.com",
+				"actions_executed":     "authenticate,forward",
+				"request":              "GET https://www.example.com:443/ HTTP/1.1",
+			},
+			missingFields: []string{"time", "client", "target", "redirect_url", "error_reason", "classification"},
+		},
+		{
+			name:      "request without target",
+			line:      `http 2018-07-02T22:23:00.186641Z app/my-loadbalancer/50dc6c495c0c9188 192.168.131.39:2817 - -1 -1 -1 503 - 34 366 "GET http://www.example.com:80/ HTTP/1.1" "curl/7.46.0" - - - "Root=1-58337364-23a8c76965a2ef7629b185e3" "-" "-" 0 2018-07-02T22:22:48.364000Z "forward" "-" "-" "-" "-" "-" "-"`,
+			ok:        true,
+			timestamp: time.Date(2018, 7, 2, 22, 23, 0, 186641000, time.UTC),
+			fields: map[string]interface{}{
+				"elb_status_code":         "503",
+				"request_processing_time": "-1",
+			},
+			missingFields: []string{"target_ip", "target_port", "target_status_code", "ssl_cipher", "domain_name"},
+		},
+		{
+			name: "truncated line",
+			line: `https 2018-07-02T22:23:00.186641Z app/my-loadbalancer/50dc6c495c0c9188 192.168.131.39:2817 10.0.0.1:80`,
+		},
+		{
+			name: "invalid time",
+			line: `https yesterday app/my-loadbalancer/50dc6c495c0c9188 192.168.131.39:2817 10.0.0.1:80 0.086 0.048 0.037 200 200 0 57 "GET https://www.example.com:443/ HTTP/1.1"`,
+		},
+	})
+}
+
+func TestParseNetworkLoadBalancerAccessLog(t *testing.T) {
+	testAccessLogParse(t, parseNetworkLoadBalancerAccessLog, []accessLogParseCase{
+		{
+			name:      "tls connection",
+			line:      `tls 2.0 2018-12-20T02:59:40 net/my-network-loadbalancer/c6e77e28c25b2234 g3d4b5e8bb8464cd 72.21.218.154:51341 172.100.100.185:443 5 2 98 246 - arn:aws:acm:us-east-2:671290407336:certificate/2a108f19-aded-46b0-8493-c63eb1ef4a99 - ECDHE-RSA-AES128-SHA tlsv12 - my-network-loadbalancer-c6e77e28c25b2234.elb.us-east-2.amazonaws.com h2 h2 "h2","http/1.1" 2020-04-01T08:51:42`,
+			ok:        true,
+			timestamp: time.Date(2018, 12, 20, 2, 59, 40, 0, time.UTC),
+			fields: map[string]interface{}{
+				"client_ip":                    "72.21.218.154",
+				"client_port":                  "51341",
+				"destination_ip":               "172.100.100.185",
+				"destination_port":             "443",
+				"tls_cipher":                   "ECDHE-RSA-AES128-SHA",
+				"alpn_client_preference_list":  `"h2","http/1.1"`,
+				"tls_connection_creation_time": time.Date(2020, 4, 1, 8, 51, 42, 0, time.UTC),
+			},
+			missingFields: []string{"time", "client", "destination", "incoming_tls_alert", "chosen_cert_serial", "tls_named_group"},
+		},
+		{
+			name:      "connection without tls",
+			line:      `tcp 2.0 2018-12-20T02:59:40 net/my-network-loadbalancer/c6e77e28c25b2234 g3d4b5e8bb8464cd 72.21.218.154:51341 172.100.100.185:443 5 - 98 246 - - - - - - - - - - -`,
+			ok:        true,
+			timestamp: time.Date(2018, 12, 20, 2, 59, 40, 0, time.UTC),
+			fields: map[string]interface{}{
+				"type":           "tcp",
+				"received_bytes": "98",
+			},
+			missingFields: []string{"tls_handshake_time", "tls_cipher", "alpn_client_preference_list", "tls_connection_creation_time"},
+		},
+		{
+			name: "truncated line",
+			line: `tls 2.0 2018-12-20T02:59:40 net/my-network-loadbalancer/c6e77e28c25b2234 g3d4b5e8bb8464cd`,
+		},
+	})
+}
+
+func TestParseS3ServerAccessLog(t *testing.T) {
+	testAccessLogParse(t, parseS3ServerAccessLog, []accessLogParseCase{
+		{
+			name:      "rest request",
+			line:      `79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be awsexamplebucket1 [06/Feb/2019:00:00:38 +0000] 192.0.2.3 79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be 3E57427F3EXAMPLE REST.GET.VERSIONING - "GET /awsexamplebucket1?versioning HTTP/1.1" 200 - 113 - 7 - "-" "S3Console/0.4 \"beta\"" - s9lzHYrFp76ZVxRcpX9+5cjAnEH2ROuNkd2BHfIa6UkFVdtjf5mKR3/eTPFvsiP/XV/VLi31234= SigV4 ECDHE-RSA-AES128-GCM-SHA256 AuthHeader awsexamplebucket1.s3.us-west-1.amazonaws.com TLSV1.2 arn:aws:s3:us-west-1:123456789012:accesspoint/example-AP Yes`,
+			ok:        true,
+			timestamp: time.Date(2019, 2, 6, 0, 0, 38, 0, time.UTC),
+			fields: map[string]interface{}{
+				"bucket":               "awsexamplebucket1",
+				"remote_ip":            "192.0.2.3",
+				"operation":            "REST.GET.VERSIONING",
+				"request_method":       "GET",
+				"request_url":          "/awsexamplebucket1?versioning",
+				"request_http_version": "HTTP/1.1",
+				"http_status":          "200",
+				"user_agent":           `S3Console/0.4 "beta"`,
+				"access_point_arn":     "arn:aws:s3:us-west-1:123456789012:accesspoint/example-AP",
+				"acl_required":         "Yes",
+			},
+			missingFields: []string{"time", "key", "error_code", "referer", "version_id"},
+		},
+		{
+			name:      "older record without trailing fields",
+			line:      `79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be awsexamplebucket1 [06/Feb/2019:00:00:38 +0000] 192.0.2.3 - 3E57427F3EXAMPLE REST.GET.OBJECT photos/cat.jpg "GET /awsexamplebucket1/photos/cat.jpg HTTP/1.1" 404 NoSuchKey 243`,
+			ok:        true,
+			timestamp: time.Date(2019, 2, 6, 0, 0, 38, 0, time.UTC),
+			fields: map[string]interface{}{
+				"key":        "photos/cat.jpg",
+				"error_code": "NoSuchKey",
+				"bytes_sent": "243",
+			},
+			missingFields: []string{"requester", "object_size", "tls_version"},
+		},
+		{
+			name: "truncated line",
+			line: `79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be awsexamplebucket1 [06/Feb/2019:00:00:38 +0000] 192.0.2.3`,
+		},
+	})
+}
+
+func TestParseCloudFrontAccessLog(t *testing.T) {
+	testAccessLogParse(t, parseCloudFrontAccessLog, []accessLogParseCase{
+		{
+			name:      "cache hit",
+			line:      "2019-12-04\t21:02:31\tLAX1\t392\t192.0.2.100\tGET\td111111abcdef8.cloudfront.net\t/index.html\t200\t-\tMozilla/5.0%20(Windows%20NT%2010.0)\t-\t-\tHit\tSOX4xwn4XV6Q4rgb7XiVGOHms_BGlTAC4KyHmureZmBNrjGdRLiNIQ==\td111111abcdef8.cloudfront.net\thttps\t23\t0.001\t-\tTLSv1.2\tECDHE-RSA-AES128-GCM-SHA256\tHit\tHTTP/2.0\t-\t-\t11040\t0.001\tHit\ttext/html\t78\t-\t-",
+			ok:        true,
+			timestamp: time.Date(2019, 12, 4, 21, 2, 31, 0, time.UTC),
+			fields: map[string]interface{}{
+				"edge_location":    "LAX1",
+				"client_ip":        "192.0.2.100",
+				"client_port":      "11040",
+				"method":           "GET",
+				"uri_stem":         "/index.html",
+				"status":           "200",
+				"user_agent":       "Mozilla/5.0 (Windows NT 10.0)",
+				"edge_result_type": "Hit",
+				"content_type":     "text/html",
+			},
+			missingFields: []string{"referer", "uri_query", "cookie", "forwarded_for", "range_start"},
+		},
+		{
+			name: "version header",
+			line: "#Version: 1.0",
+		},
+		{
+			name: "fields header",
+			line: "#Fields: date time x-edge-location sc-bytes c-ip cs-method cs(Host) cs-uri-stem sc-status",
+		},
+		{
+			name: "truncated line",
+			line: "2019-12-04\t21:02:31\tLAX1\t392",
+		},
+	})
+}
+
+func TestParseWafv2WebAclLog(t *testing.T) {
+	testAccessLogParse(t, parseWafv2WebAclLog, []accessLogParseCase{
+		{
+			name:      "blocked request",
+			line:      `{"timestamp":1576280412771,"formatVersion":1,"webaclId":"arn:aws:wafv2:ap-southeast-2:111122223333:regional/webacl/STMTest/1EXAMPLE","terminatingRuleId":"STMTest_SQLi_XSS","terminatingRuleType":"REGULAR","action":"BLOCK","terminatingRuleMatchDetails":[{"conditionType":"SQL_INJECTION","location":"UNKNOWN","matchedData":["10","AND","1"]}],"httpSourceName":"-","httpSourceId":"-","ruleGroupList":[],"rateBasedRuleList":null,"nonTerminatingMatchingRules":[],"responseCodeSent":403,"labels":[{"name":"awswaf:managed:aws:core-rule-set:NoUserAgent_Header"}],"httpRequest":{"clientIp":"1.1.1.1","country":"AU","headers":[{"name":"Host","value":"localhost:1989"}],"uri":"/myUri","args":"","httpVersion":"HTTP/1.1","httpMethod":"GET","requestId":"rid"}}`,
+			ok:        true,
+			timestamp: time.UnixMilli(1576280412771),
+			fields: map[string]interface{}{
+				"action":             "BLOCK",
+				"client_ip":          "1.1.1.1",
+				"country":            "AU",
+				"uri":                "/myUri",
+				"args":               "",
+				"http_method":        "GET",
+				"http_source_name":   "-",
+				"response_code_sent": int64(403),
+				"format_version":     int64(1),
+				"headers":            []interface{}{map[string]interface{}{"name": "Host", "value": "localhost:1989"}},
+				"labels":             []interface{}{map[string]interface{}{"name": "awswaf:managed:aws:core-rule-set:NoUserAgent_Header"}},
+				"rule_group_list":    []interface{}{},
+			},
+			missingFields: []string{"rate_based_rule_list", "captcha_response", "ja3_fingerprint"},
+		},
+		{
+			name: "missing timestamp",
+			line: `{"action":"ALLOW","httpRequest":{"clientIp":"1.1.1.1"}}`,
+		},
+		{
+			name: "truncated line",
+			line: `{"timestamp":1576280412771,"formatVersion":1,"action":"BLO`,
+		},
+		{
+			name: "not json",
+			line: "2019-12-04 21:02:31 ALLOW",
+		},
+	})
+}
@@ -3,7 +3,9 @@ package aws
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -11,6 +13,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
 // append the common cloudwatch metric columns onto the column list
@@ -55,6 +58,54 @@ func commonCwMetricColumns() []*plugin.Column {
 			Description: "The sum of the metric values for the data point.",
 			Type:        proto.ColumnType_DOUBLE,
 		},
+		{
+			Name:        "p50",
+			Description: "The 50th percentile (median) of the metric values for the data point. Only returned when selected.",
+			Type:        proto.ColumnType_DOUBLE,
+			Transform:   transform.FromField("ExtendedStatistics.p50"),
+		},
+		{
+			Name:        "p90",
+			Description: "The 90th percentile of the metric values for the data point. Only returned when selected.",
+			Type:        proto.ColumnType_DOUBLE,
+			Transform:   transform.FromField("ExtendedStatistics.p90"),
+		},
+		{
+			Name:        "p95",
+			Description: "The 95th percentile of the metric values for the data point. Only returned when selected.",
+			Type:        proto.ColumnType_DOUBLE,
+			Transform:   transform.FromField("ExtendedStatistics.p95"),
+		},
+		{
+			Name:        "p99",
+			Description: "The 99th percentile of the metric values for the data point. Only returned when selected.",
+			Type:        proto.ColumnType_DOUBLE,
+			Transform:   transform.FromField("ExtendedStatistics.p99"),
+		},
+		{
+			Name:        "tm90",
+			Description: "The mean of the metric values for the data point, excluding the highest 10%. Only returned when selected.",
+			Type:        proto.ColumnType_DOUBLE,
+			Transform:   transform.FromField("ExtendedStatistics.tm90"),
+		},
+		{
+			Name:        "tm99",
+			Description: "The mean of the metric values for the data point, excluding the highest 1%. Only returned when selected.",
+			Type:        proto.ColumnType_DOUBLE,
+			Transform:   transform.FromField("ExtendedStatistics.tm99"),
+		},
+		{
+			Name:        "extended_statistics",
+			Description: "A comma separated list of additional percentile or trimmed mean statistics to return in extended_statistic_values, e.g. 'p99.9,tm(10%:90%)'.",
+			Type:        proto.ColumnType_STRING,
+			Transform:   transform.FromQual("extended_statistics"),
+		},
+		{
+			Name:        "extended_statistic_values",
+			Description: "The percentile and trimmed mean statistics returned for the data point, keyed by statistic.",
+			Type:        proto.ColumnType_JSON,
+			Transform:   transform.FromField("ExtendedStatistics"),
+		},
 		{
 			Name:        "unit",
 			Description: "The standard unit for the data point.",
@@ -84,8 +135,9 @@ type CWMetricRow struct {
 	// The average of the metric values that correspond to the data point.
 	Average *float64
 
-	// The percentile statistic for the data point.
-	//ExtendedStatistics map[string]*float64 `type:"map"`
+	// The percentile and trimmed mean statistics for the data point, keyed by
+	// statistic, e.g. p99.
+	ExtendedStatistics map[string]*float64
 
 	// The maximum metric value for the data point.
 	Maximum *float64
@@ -133,67 +185,364 @@ func getCWPeriodForGranularity(granularity string) int32 {
 	return 300
 }
 
-func listCWMetricStatistics(ctx context.Context, d *plugin.QueryData, granularity string, namespace string, metricName string, dimensionName string, dimensionValue string) (*cloudwatch.GetMetricStatisticsOutput, error) {
+// getCWMetricTimeRange returns the time range and period of the statistics.
+// The range is set by the timestamp quals, or is the lookback window of the
+// granularity if there are none. The period is that of the granularity, unless
+// the range starts before CloudWatch's retention of data points with that
+// period: 1 minute data points are kept for 15 days, 5 minute data points for
+// 63 days and 1 hour data points for 455 days.
+func getCWMetricTimeRange(d *plugin.QueryData, granularity string) (time.Time, time.Time, int32) {
+	now := time.Now()
+	period := getCWPeriodForGranularity(granularity)
+
+	var startTime, endTime *time.Time
+	if d.Quals["timestamp"] != nil {
+		for _, q := range d.Quals["timestamp"].Quals {
+			timestamp := q.Value.GetTimestampValue().AsTime()
+			switch q.Operator {
+			case "=":
+				startTime, endTime = &timestamp, &timestamp
+			case ">=", ">":
+				startTime = &timestamp
+			case "<", "<=":
+				endTime = &timestamp
+			}
+		}
+	}
+
+	if endTime == nil || endTime.After(now) {
+		endTime = &now
+	}
+	if startTime == nil {
+		lookback := now.Sub(getCWStartDateForGranularity(granularity))
+		startTime = aws.Time(endTime.Add(-lookback))
+	}
+
+	switch age := now.Sub(*startTime); {
+	case age > 63*24*time.Hour && period < 3600:
+		period = 3600
+	case age > 15*24*time.Hour && period < 300:
+		period = 300
+	}
+
+	// Data points are timestamped at the start of their period, so widen the
+	// range to whole periods to include the data points at both ends
+	periodDuration := time.Duration(period) * time.Second
+	return startTime.Truncate(periodDuration), endTime.Truncate(periodDuration).Add(periodDuration), period
+}
+
+// listCWMetricStatistics streams the statistics of a metric for a single
+// dimension value. Calls made while listing a table are batched into
+// GetMetricData requests of up to 500 metric queries, so a table over many
+// resources makes a request per 100 resources rather than one per resource.
+func listCWMetricStatistics(ctx context.Context, d *plugin.QueryData, granularity string, namespace string, metricName string, unit string, dimensionName string, dimensionValue string) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	extendedStatistics := getCWExtendedStatistics(d)
+	if len(cwMetricStatistics)+len(extendedStatistics) > cwMetricDataMaxQueries {
+		return nil, fmt.Errorf("at most %d extended statistics can be requested", cwMetricDataMaxQueries-len(cwMetricStatistics))
+	}
+
+	request := &cwMetricBatchRequest{
+		namespace:          namespace,
+		metricName:         metricName,
+		unit:               unit,
+		dimensionName:      dimensionName,
+		dimensionValue:     dimensionValue,
+		extendedStatistics: extendedStatistics,
+		result:             make(chan cwMetricBatchResult, 1),
+	}
+	addCWMetricBatchRequest(ctx, d, granularity, request)
+
+	var result cwMetricBatchResult
+	select {
+	case result = <-request.result:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if result.err != nil {
+		plugin.Logger(ctx).Error("listCWMetricStatistics", "api_error", result.err)
+		return nil, result.err
+	}
+
+	for _, row := range result.rows {
+		d.StreamLeafListItem(ctx, row)
+
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Extended statistics that have their own column
+var cwExtendedStatisticColumns = []string{"p50", "p90", "p95", "p99", "tm90", "tm99"}
+
+// getCWExtendedStatistics returns the extended statistics to request: those
+// of the extended_statistics qual, and those with a column that is selected.
+func getCWExtendedStatistics(d *plugin.QueryData) []string {
+	var statistics []string
+	seen := map[string]bool{}
+	add := func(statistic string) {
+		if statistic != "" && !seen[statistic] {
+			seen[statistic] = true
+			statistics = append(statistics, statistic)
+		}
+	}
+
+	for _, statistic := range strings.Split(d.EqualsQualString("extended_statistics"), ",") {
+		add(strings.TrimSpace(statistic))
+	}
+	for _, column := range d.QueryContext.Columns {
+		for _, statistic := range cwExtendedStatisticColumns {
+			if column == statistic {
+				add(statistic)
+			}
+		}
+	}
+
+	return statistics
+}
+
+//// GETMETRICDATA BATCHING
+
+const (
+	// GetMetricData accepts at most 500 metric queries per request
+	cwMetricDataMaxQueries = 500
+
+	// How long to wait for more requests before sending a batch that is not full
+	cwMetricBatchWindow = 100 * time.Millisecond
+)
+
+// The statistics returned for each dimension value, with the GetMetricData
+// query ID suffix for each
+var cwMetricStatistics = []struct {
+	stat types.Statistic
+	id   string
+}{
+	{types.StatisticAverage, "average"},
+	{types.StatisticMaximum, "maximum"},
+	{types.StatisticMinimum, "minimum"},
+	{types.StatisticSampleCount, "sample_count"},
+	{types.StatisticSum, "sum"},
+}
+
+type cwMetricBatchRequest struct {
+	namespace          string
+	metricName         string
+	unit               string
+	dimensionName      string
+	dimensionValue     string
+	extendedStatistics []string
+	result             chan cwMetricBatchResult
+}
+
+// queries returns the number of GetMetricData queries for the request
+func (r *cwMetricBatchRequest) queries() int {
+	return len(cwMetricStatistics) + len(r.extendedStatistics)
+}
+
+type cwMetricBatchResult struct {
+	rows []*CWMetricRow
+	err  error
+}
+
+type cwMetricBatch struct {
+	requests []*cwMetricBatchRequest
+	queries  int
+	timer    *time.Timer
+}
+
+// Pending batches, keyed by the query, region and granularity. Each child
+// list call gets its own copy of the query data, but they share the query
+// context.
+var cwMetricBatches = struct {
+	sync.Mutex
+	batches map[string]*cwMetricBatch
+}{batches: map[string]*cwMetricBatch{}}
+
+// addCWMetricBatchRequest queues a request, sending the batch it is added to
+// once it is full or the batch window has passed.
+func addCWMetricBatchRequest(ctx context.Context, d *plugin.QueryData, granularity string, request *cwMetricBatchRequest) {
+	key := fmt.Sprintf("%p-%s-%s", d.QueryContext, d.EqualsQualString(matrixKeyRegion), strings.ToUpper(granularity))
+
+	cwMetricBatches.Lock()
+	defer cwMetricBatches.Unlock()
+
+	batch, ok := cwMetricBatches.batches[key]
+	if !ok {
+		batch = &cwMetricBatch{}
+		batch.timer = time.AfterFunc(cwMetricBatchWindow, func() {
+			if takeCWMetricBatch(key, batch) {
+				sendCWMetricBatch(ctx, d, granularity, batch.requests)
+			}
+		})
+		cwMetricBatches.batches[key] = batch
+	}
+
+	batch.requests = append(batch.requests, request)
+	batch.queries += request.queries()
+	if batch.queries+request.queries() > cwMetricDataMaxQueries {
+		// The queries of another request would not fit, so send the batch
+		// now rather than when the timer fires
+		batch.timer.Stop()
+		delete(cwMetricBatches.batches, key)
+		go sendCWMetricBatch(ctx, d, granularity, batch.requests)
+	}
+}
+
+// takeCWMetricBatch removes the batch from the pending batches, returning
+// false if it was already sent because it was full.
+func takeCWMetricBatch(key string, batch *cwMetricBatch) bool {
+	cwMetricBatches.Lock()
+	defer cwMetricBatches.Unlock()
+
+	if cwMetricBatches.batches[key] != batch {
+		return false
+	}
+	delete(cwMetricBatches.batches, key)
+	return true
+}
+
+// sendCWMetricBatch gets the statistics for a batch of requests with
+// GetMetricData, and sends each request the data points of its dimension
+// value.
+func sendCWMetricBatch(ctx context.Context, d *plugin.QueryData, granularity string, requests []*cwMetricBatchRequest) {
+	rows, err := getCWMetricBatch(ctx, d, granularity, requests)
+	for i, request := range requests {
+		request.result <- cwMetricBatchResult{rows: rows[i], err: err}
+	}
+}
+
+func getCWMetricBatch(ctx context.Context, d *plugin.QueryData, granularity string, requests []*cwMetricBatchRequest) ([][]*CWMetricRow, error) {
+	rows := make([][]*CWMetricRow, len(requests))
+
 	// Create Session
 	svc, err := CloudWatchClient(ctx, d)
 	if err != nil {
-		plugin.Logger(ctx).Error("listCWMetricStatistics", "connection_error", err)
-		return nil, err
+		plugin.Logger(ctx).Error("getCWMetricBatch", "connection_error", err)
+		return rows, err
 	}
 
-	endTime := time.Now()
-	startTime := getCWStartDateForGranularity(granularity)
-	period := getCWPeriodForGranularity(granularity)
+	startTime, endTime, period := getCWMetricTimeRange(d, granularity)
 
-	params := &cloudwatch.GetMetricStatisticsInput{
-		Namespace:  aws.String(namespace),
-		MetricName: aws.String(metricName),
-		StartTime:  aws.Time(startTime),
-		EndTime:    aws.Time(endTime),
-		Period:     aws.Int32(period),
-		Statistics: []types.Statistic{
-			types.StatisticAverage,
-			types.StatisticSampleCount,
-			types.StatisticSum,
-			types.StatisticMinimum,
-			types.StatisticMaximum,
-		},
-	}
-
-	if dimensionName != "" && dimensionValue != "" {
-		params.Dimensions = []types.Dimension{
-			{
-				Name:  aws.String(dimensionName),
-				Value: aws.String(dimensionValue),
-			},
+	params := &cloudwatch.GetMetricDataInput{
+		StartTime: aws.Time(startTime),
+		EndTime:   aws.Time(endTime),
+		ScanBy:    types.ScanByTimestampAscending,
+	}
+	for i, request := range requests {
+		metric := &types.Metric{
+			Namespace:  aws.String(request.namespace),
+			MetricName: aws.String(request.metricName),
+		}
+		if request.dimensionName != "" && request.dimensionValue != "" {
+			metric.Dimensions = []types.Dimension{
+				{
+					Name:  aws.String(request.dimensionName),
+					Value: aws.String(request.dimensionValue),
+				},
+			}
+		}
+		for _, statistic := range cwMetricStatistics {
+			params.MetricDataQueries = append(params.MetricDataQueries, types.MetricDataQuery{
+				Id: aws.String(fmt.Sprintf("m%d_%s", i, statistic.id)),
+				MetricStat: &types.MetricStat{
+					Metric: metric,
+					Period: aws.Int32(period),
+					Stat:   aws.String(string(statistic.stat)),
+				},
+			})
+		}
+		// Extended statistics such as tm(10%:90%) are not valid in IDs, so
+		// they are identified by their index
+		for j, statistic := range request.extendedStatistics {
+			params.MetricDataQueries = append(params.MetricDataQueries, types.MetricDataQuery{
+				Id: aws.String(fmt.Sprintf("m%d_x%d", i, j)),
+				MetricStat: &types.MetricStat{
+					Metric: metric,
+					Period: aws.Int32(period),
+					Stat:   aws.String(statistic),
+				},
+			})
 		}
 	}
 
-	stats, err := svc.GetMetricStatistics(ctx, params)
-	if err != nil {
-		plugin.Logger(ctx).Error("listCWMetricStatistics", "api_error", err)
-		return nil, err
-	}
-
-	for _, datapoint := range stats.Datapoints {
-		d.StreamLeafListItem(ctx, &CWMetricRow{
-			DimensionValue: aws.String(dimensionValue),
-			DimensionName:  aws.String(dimensionName),
-			Namespace:      aws.String(namespace),
-			MetricName:     aws.String(metricName),
-			Average:        datapoint.Average,
-			Maximum:        datapoint.Maximum,
-			Minimum:        datapoint.Minimum,
-			Timestamp:      datapoint.Timestamp,
-			SampleCount:    datapoint.SampleCount,
-			Sum:            datapoint.Sum,
-			Unit:           aws.String(fmt.Sprint(datapoint.Unit)),
+	// Data points of each request, by timestamp
+	points := make([]map[time.Time]*CWMetricRow, len(requests))
+	for i := range points {
+		points[i] = map[time.Time]*CWMetricRow{}
+	}
+
+	paginator := cloudwatch.NewGetMetricDataPaginator(svc, params)
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return rows, err
+		}
+
+		for _, result := range output.MetricDataResults {
+			var i int
+			var statisticID string
+			if _, err := fmt.Sscanf(aws.ToString(result.Id), "m%d_%s", &i, &statisticID); err != nil || i >= len(requests) {
+				continue
+			}
+			request := requests[i]
+
+			for j, timestamp := range result.Timestamps {
+				row, ok := points[i][timestamp]
+				if !ok {
+					row = &CWMetricRow{
+						DimensionValue: aws.String(request.dimensionValue),
+						DimensionName:  aws.String(request.dimensionName),
+						Namespace:      aws.String(request.namespace),
+						MetricName:     aws.String(request.metricName),
+						Timestamp:      aws.Time(timestamp),
+					}
+					if request.unit != "" {
+						row.Unit = aws.String(request.unit)
+					}
+					points[i][timestamp] = row
+				}
+				setCWMetricRowStatistic(row, request, statisticID, result.Values[j])
+			}
+		}
+	}
+
+	for i := range requests {
+		for _, row := range points[i] {
+			rows[i] = append(rows[i], row)
+		}
+		sort.Slice(rows[i], func(a, b int) bool {
+			return rows[i][a].Timestamp.Before(*rows[i][b].Timestamp)
 		})
+	}
 
-		if d.RowsRemaining(ctx) == 0 {
-			return nil, nil
+	return rows, nil
+}
+
+func setCWMetricRowStatistic(row *CWMetricRow, request *cwMetricBatchRequest, statisticID string, value float64) {
+	var extended int
+	if _, err := fmt.Sscanf(statisticID, "x%d", &extended); err == nil && extended < len(request.extendedStatistics) {
+		if row.ExtendedStatistics == nil {
+			row.ExtendedStatistics = map[string]*float64{}
 		}
+		row.ExtendedStatistics[request.extendedStatistics[extended]] = aws.Float64(value)
+		return
 	}
 
-	return nil, nil
+	switch statisticID {
+	case "average":
+		row.Average = aws.Float64(value)
+	case "maximum":
+		row.Maximum = aws.Float64(value)
+	case "minimum":
+		row.Minimum = aws.Float64(value)
+	case "sample_count":
+		row.SampleCount = aws.Float64(value)
+	case "sum":
+		row.Sum = aws.Float64(value)
+	}
 }
@@ -0,0 +1,275 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// cwMetricTableDefinition declares a table of the statistics of a single
+// CloudWatch metric, with one series per dimension value.
+type cwMetricTableDefinition struct {
+	Name        string
+	Description string
+
+	// The metric, e.g. AWS/EC2 CPUUtilization
+	Namespace  string
+	MetricName string
+
+	// The CloudWatch standard unit the metric is reported in, e.g. Percent,
+	// which is returned in the unit column. GetMetricData does not return the
+	// unit of data points, and data points are not filtered by it, so metrics
+	// that have no standard unit leave it empty and have a null unit column.
+	Unit string
+
+	// The period of the data points: 5_MIN, HOURLY or DAILY
+	Granularity string
+
+	// The (single) metric dimension, e.g. InstanceId, and the column that
+	// holds its value, e.g. instance_id. Metrics without dimensions, such as
+	// account level metrics, leave both empty.
+	DimensionName   string
+	DimensionColumn *plugin.Column
+
+	// ParentHydrate lists the resources the metric is reported for, and
+	// DimensionValue returns the dimension value of each of them. When
+	// ParentHydrate is not set the dimension values are found with
+	// ListMetrics.
+	ParentHydrate  plugin.HydrateFunc
+	DimensionValue func(item interface{}) string
+}
+
+// cwMetricTable generates the table for a metric table definition
+func cwMetricTable(def cwMetricTableDefinition) *plugin.Table {
+	if def.DimensionName != "" && def.ParentHydrate == nil {
+		def.ParentHydrate = def.listDimensionValues
+		def.DimensionValue = func(item interface{}) string { return item.(string) }
+	}
+
+	var columns []*plugin.Column
+	listConfig := &plugin.ListConfig{
+		ParentHydrate: def.ParentHydrate,
+		Hydrate:       def.listMetricStatistics,
+		Tags:          map[string]string{"service": "cloudwatch", "action": "GetMetricData"},
+		KeyColumns: plugin.KeyColumnSlice{
+			{Name: "extended_statistics", Require: plugin.Optional},
+			{Name: "timestamp", Require: plugin.Optional, Operators: []string{">", ">=", "=", "<", "<="}},
+		},
+	}
+
+	if def.DimensionName != "" {
+		column := *def.DimensionColumn
+		column.Type = proto.ColumnType_STRING
+		column.Transform = transform.FromField("DimensionValue")
+		columns = append(columns, &column)
+
+		listConfig.KeyColumns = append(listConfig.KeyColumns, &plugin.KeyColumn{Name: column.Name, Require: plugin.Optional})
+	}
+
+	return &plugin.Table{
+		Name:              def.Name,
+		Description:       def.Description,
+		List:              listConfig,
+		GetMatrixItemFunc: CloudWatchRegionsMatrix,
+		Columns:           awsRegionalColumns(cwMetricColumns(columns)),
+	}
+}
+
+func (def cwMetricTableDefinition) listMetricStatistics(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	if def.DimensionName == "" {
+		return listCWMetricStatistics(ctx, d, def.Granularity, def.Namespace, def.MetricName, def.Unit, "", "")
+	}
+
+	dimensionValue := def.DimensionValue(h.Item)
+	if qual := d.EqualsQualString(def.DimensionColumn.Name); qual != "" && qual != dimensionValue {
+		return nil, nil
+	}
+	return listCWMetricStatistics(ctx, d, def.Granularity, def.Namespace, def.MetricName, def.Unit, def.DimensionName, dimensionValue)
+}
+
+// listDimensionValues lists the values of the dimension that the metric has
+// data for in the region, for tables that have no parent resource.
+func (def cwMetricTableDefinition) listDimensionValues(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// No need to look the values up if the query asks for a single one
+	if qual := d.EqualsQualString(def.DimensionColumn.Name); qual != "" {
+		d.StreamListItem(ctx, qual)
+		return nil, nil
+	}
+
+	// Create Session
+	svc, err := CloudWatchClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("cwMetricTableDefinition.listDimensionValues", "table", def.Name, "connection_error", err)
+		return nil, err
+	}
+
+	input := &cloudwatch.ListMetricsInput{
+		Namespace:  aws.String(def.Namespace),
+		MetricName: aws.String(def.MetricName),
+		Dimensions: []types.DimensionFilter{{Name: aws.String(def.DimensionName)}},
+	}
+
+	seen := map[string]bool{}
+	paginator := cloudwatch.NewListMetricsPaginator(svc, input, func(o *cloudwatch.ListMetricsPaginatorOptions) {
+		o.StopOnDuplicateToken = true
+	})
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Error("cwMetricTableDefinition.listDimensionValues", "table", def.Name, "api_error", err)
+			return nil, err
+		}
+
+		for _, metric := range output.Metrics {
+			// Statistics are requested for the single dimension, so only
+			// metrics reported with just that dimension have data points
+			if len(metric.Dimensions) != 1 {
+				continue
+			}
+			value := aws.ToString(metric.Dimensions[0].Value)
+			if seen[value] {
+				continue
+			}
+			seen[value] = true
+			d.StreamListItem(ctx, value)
+
+			// Context may get cancelled due to manual cancellation or if the limit has been reached
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+//// USER DEFINED METRIC TABLES
+
+var cwMetricTableNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// withCloudWatchMetricTables returns the built-in tables together with the
+// metric tables declared in the cloudwatch_metric_tables option of the
+// connection config. The built-in table map is shared by all connections, so
+// it is copied rather than added to.
+func withCloudWatchMetricTables(_ context.Context, connection *plugin.Connection, builtIn map[string]*plugin.Table) (map[string]*plugin.Table, error) {
+	awsSpcConfig := GetConfig(connection)
+	if len(awsSpcConfig.CloudWatchMetricTables) == 0 {
+		return builtIn, nil
+	}
+
+	tables := make(map[string]*plugin.Table, len(builtIn)+len(awsSpcConfig.CloudWatchMetricTables))
+	for name, table := range builtIn {
+		tables[name] = table
+	}
+
+	for i, config := range awsSpcConfig.CloudWatchMetricTables {
+		def, err := cwMetricTableDefinitionFromConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("connection %s has invalid value for \"cloudwatch_metric_tables\" at index %d: %v", connection.Name, i, err)
+		}
+		if _, exists := tables[def.Name]; exists {
+			return nil, fmt.Errorf("connection %s has invalid value for \"cloudwatch_metric_tables\" at index %d: table %s already exists", connection.Name, i, def.Name)
+		}
+		tables[def.Name] = cwMetricTable(def)
+	}
+
+	return tables, nil
+}
+
+// cwMetricTableDefinitionFromConfig builds a table definition from an entry
+// of cloudwatch_metric_tables, e.g.
+//
+//	{
+//	  name           = "aws_sqs_queue_metric_approximate_age_of_oldest_message_hourly"
+//	  namespace      = "AWS/SQS"
+//	  metric_name    = "ApproximateAgeOfOldestMessage"
+//	  unit           = "Seconds"
+//	  dimension_name = "QueueName"
+//	  granularity    = "HOURLY"
+//	}
+func cwMetricTableDefinitionFromConfig(config map[string]string) (cwMetricTableDefinition, error) {
+	for key := range config {
+		switch key {
+		case "name", "description", "namespace", "metric_name", "unit", "dimension_name", "dimension_column", "granularity":
+		default:
+			return cwMetricTableDefinition{}, fmt.Errorf("unsupported attribute %q", key)
+		}
+	}
+
+	def := cwMetricTableDefinition{
+		Name:          config["name"],
+		Description:   config["description"],
+		Namespace:     config["namespace"],
+		MetricName:    config["metric_name"],
+		Unit:          config["unit"],
+		Granularity:   strings.ToUpper(config["granularity"]),
+		DimensionName: config["dimension_name"],
+	}
+
+	if !cwMetricTableNamePattern.MatchString(def.Name) {
+		return def, fmt.Errorf("name %q must be lower case letters, digits and underscores", def.Name)
+	}
+	if def.Namespace == "" || def.MetricName == "" {
+		return def, fmt.Errorf("namespace and metric_name are required")
+	}
+
+	if def.Unit != "" && !cwStandardUnit(def.Unit) {
+		return def, fmt.Errorf("unit %q is not a CloudWatch standard unit", def.Unit)
+	}
+
+	switch def.Granularity {
+	case "":
+		def.Granularity = "5_MIN"
+	case "5_MIN", "HOURLY", "DAILY":
+	default:
+		return def, fmt.Errorf("granularity %q must be one of 5_MIN, HOURLY or DAILY", def.Granularity)
+	}
+
+	if def.Description == "" {
+		def.Description = fmt.Sprintf("AWS CloudWatch Metrics - %s %s", def.Namespace, def.MetricName)
+	}
+
+	if def.DimensionName != "" {
+		columnName := config["dimension_column"]
+		if columnName == "" {
+			columnName = snakeCase(def.DimensionName)
+		}
+		if !cwMetricTableNamePattern.MatchString(columnName) {
+			return def, fmt.Errorf("dimension_column %q must be lower case letters, digits and underscores", columnName)
+		}
+		for _, column := range awsRegionalColumns(cwMetricColumns(nil)) {
+			if column.Name == columnName {
+				return def, fmt.Errorf("dimension_column %q is already a column of metric tables", columnName)
+			}
+		}
+		def.DimensionColumn = &plugin.Column{
+			Name:        columnName,
+			Description: fmt.Sprintf("The value of the %s dimension.", def.DimensionName),
+		}
+	} else if config["dimension_column"] != "" {
+		return def, fmt.Errorf("dimension_column requires dimension_name")
+	}
+
+	return def, nil
+}
+
+func cwStandardUnit(unit string) bool {
+	for _, standardUnit := range types.StandardUnit("").Values() {
+		if unit == string(standardUnit) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package aws
+
+import "testing"
+
+func TestCwMetricTableDefinitionFromConfig(t *testing.T) {
+	valid := func() map[string]string {
+		return map[string]string{
+			"name":           "aws_sqs_queue_metric_approximate_age_of_oldest_message_hourly",
+			"namespace":      "AWS/SQS",
+			"metric_name":    "ApproximateAgeOfOldestMessage",
+			"dimension_name": "QueueName",
+			"granularity":    "hourly",
+		}
+	}
+
+	def, err := cwMetricTableDefinitionFromConfig(valid())
+	if err != nil {
+		t.Fatalf("cwMetricTableDefinitionFromConfig() error = %v", err)
+	}
+	if def.Granularity != "HOURLY" {
+		t.Errorf("Granularity = %q, want HOURLY", def.Granularity)
+	}
+	if def.DimensionColumn == nil || def.DimensionColumn.Name != "queue_name" {
+		t.Errorf("DimensionColumn = %v, want queue_name", def.DimensionColumn)
+	}
+	if def.Description != "AWS CloudWatch Metrics - AWS/SQS ApproximateAgeOfOldestMessage" {
+		t.Errorf("Description = %q", def.Description)
+	}
+
+	invalid := map[string]func(config map[string]string){
+		"unsupported attribute":    func(c map[string]string) { c["period"] = "60" },
+		"name with upper case":     func(c map[string]string) { c["name"] = "AwsSqsQueue" },
+		"missing namespace":        func(c map[string]string) { delete(c, "namespace") },
+		"missing metric name":      func(c map[string]string) { delete(c, "metric_name") },
+		"unknown granularity":      func(c map[string]string) { c["granularity"] = "WEEKLY" },
+		"unknown unit":             func(c map[string]string) { c["unit"] = "Minutes" },
+		"dimension column clashes": func(c map[string]string) { c["dimension_column"] = "timestamp" },
+		"dimension column without dimension": func(c map[string]string) {
+			delete(c, "dimension_name")
+			c["dimension_column"] = "queue_name"
+		},
+	}
+	for name, change := range invalid {
+		t.Run(name, func(t *testing.T) {
+			config := valid()
+			change(config)
+			if _, err := cwMetricTableDefinitionFromConfig(config); err == nil {
+				t.Errorf("cwMetricTableDefinitionFromConfig(%v) error = nil, want an error", config)
+			}
+		})
+	}
+}
@@ -19,6 +19,17 @@ type awsConfig struct {
 	IgnoreErrorCodes      []string `hcl:"ignore_error_codes,optional"`
 	EndpointUrl           *string  `hcl:"endpoint_url"`
 	S3ForcePathStyle      *bool    `hcl:"s3_force_path_style"`
+	PricingOfferPath      *string  `hcl:"pricing_offer_path"`
+	LocalFilePaths        []string `hcl:"local_file_paths,optional"`
+
+	S3ObjectListConcurrency *int `hcl:"s3_object_list_concurrency"`
+
+	CostExplorerCacheDir             *string `hcl:"cost_explorer_cache_dir"`
+	CostExplorerCacheOpenPeriodTTL   *int    `hcl:"cost_explorer_cache_open_period_ttl"`
+	CostExplorerCacheClosedPeriodTTL *int    `hcl:"cost_explorer_cache_closed_period_ttl"`
+	CostExplorerDailyRequestLimit    *int    `hcl:"cost_explorer_daily_request_limit"`
+
+	CloudWatchMetricTables []map[string]string `hcl:"cloudwatch_metric_tables,optional"`
 }
 
 func ConfigInstance() interface{} {
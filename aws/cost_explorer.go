@@ -139,7 +139,7 @@ func streamCostAndUsage(ctx context.Context, d *plugin.QueryData, params *costex
 	}
 	// List call
 	for {
-		output, err := svc.GetCostAndUsage(ctx, params)
+		output, err := getCostAndUsage(ctx, d, svc, params)
 		if err != nil {
 			plugin.Logger(ctx).Error("streamCostAndUsage", "api_error", err)
 			return nil, err
@@ -268,6 +268,39 @@ func getCEStartDateForGranularity(granularity string) time.Time {
 	return time.Now().AddDate(0, 0, -13)
 }
 
+// getCEDateInterval returns the time period for a Cost Explorer request. The
+// search_start_time and search_end_time quals are used if they are set,
+// otherwise the period defaults to the lookback for the granularity.
+func getCEDateInterval(d *plugin.QueryData, granularity string) *types.DateInterval {
+	timeFormat := "2006-01-02"
+	if granularity == "HOURLY" {
+		timeFormat = "2006-01-02T15:04:05Z"
+	}
+
+	startTime, endTime := getCESearchTimeRange(d, getCEStartDateForGranularity(granularity))
+
+	return &types.DateInterval{
+		Start: aws.String(startTime.UTC().Format(timeFormat)),
+		End:   aws.String(endTime.UTC().Format(timeFormat)),
+	}
+}
+
+// getCESearchTimeRange returns the search_start_time and search_end_time
+// quals, falling back to defaultStart and the current time.
+func getCESearchTimeRange(d *plugin.QueryData, defaultStart time.Time) (time.Time, time.Time) {
+	startTime := defaultStart
+	endTime := time.Now()
+
+	if d.EqualsQuals["search_start_time"] != nil {
+		startTime = d.EqualsQuals["search_start_time"].GetTimestampValue().AsTime()
+	}
+	if d.EqualsQuals["search_end_time"] != nil {
+		endTime = d.EqualsQuals["search_end_time"].GetTimestampValue().AsTime()
+	}
+
+	return startTime, endTime
+}
+
 type CEQuals struct {
 	// Quals stuff
 	SearchStartTime *timestamp.Timestamp
@@ -0,0 +1,217 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// Every Cost Explorer API request is charged, so results of GetCostAndUsage
+// can be cached on disk with cost_explorer_cache_dir, and the number of
+// requests made per connection per day can be limited with
+// cost_explorer_daily_request_limit.
+
+const (
+	// Cost Explorer may still refresh data for a couple of days after a
+	// period ends, so only periods that ended before then are closed
+	costExplorerClosedPeriodDelay = 48 * time.Hour
+
+	costExplorerDefaultOpenPeriodCacheTTL   = time.Hour
+	costExplorerDefaultClosedPeriodCacheTTL = 30 * 24 * time.Hour
+)
+
+type costExplorerCacheEntry struct {
+	Expires time.Time
+	Output  *costexplorer.GetCostAndUsageOutput
+}
+
+// getCostAndUsage calls GetCostAndUsage, using the disk cache if the
+// connection has one configured.
+func getCostAndUsage(ctx context.Context, d *plugin.QueryData, svc *costexplorer.Client, params *costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error) {
+	awsSpcConfig := GetConfig(d.Connection)
+	if awsSpcConfig.CostExplorerCacheDir == nil || *awsSpcConfig.CostExplorerCacheDir == "" {
+		return svc.GetCostAndUsage(ctx, params)
+	}
+
+	cacheFile, err := costExplorerCacheFile(*awsSpcConfig.CostExplorerCacheDir, d.Connection.Name, "GetCostAndUsage", params)
+	if err != nil {
+		return nil, err
+	}
+
+	if output := readCostExplorerCacheFile(ctx, cacheFile); output != nil {
+		return output, nil
+	}
+
+	output, err := svc.GetCostAndUsage(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := costExplorerCacheEntry{
+		Expires: time.Now().Add(costExplorerCacheTTL(awsSpcConfig, params)),
+		Output:  output,
+	}
+	if err := writeCostExplorerCacheFile(cacheFile, entry); err != nil {
+		// The results are still valid, they just won't be cached
+		plugin.Logger(ctx).Warn("getCostAndUsage", "cache_write_error", err, "file", cacheFile)
+	}
+
+	return output, nil
+}
+
+// costExplorerCacheTTL returns how long results are cached for, based on
+// whether the requested period has closed.
+func costExplorerCacheTTL(awsSpcConfig awsConfig, params *costexplorer.GetCostAndUsageInput) time.Duration {
+	closed := false
+	if params.TimePeriod != nil && params.TimePeriod.End != nil {
+		// End is a date, or a timestamp for HOURLY granularity
+		end, err := time.Parse("2006-01-02", *params.TimePeriod.End)
+		if err != nil {
+			end, err = time.Parse(time.RFC3339, *params.TimePeriod.End)
+		}
+		closed = err == nil && end.Add(costExplorerClosedPeriodDelay).Before(time.Now())
+	}
+
+	if closed {
+		if awsSpcConfig.CostExplorerCacheClosedPeriodTTL != nil {
+			return time.Duration(*awsSpcConfig.CostExplorerCacheClosedPeriodTTL) * time.Second
+		}
+		return costExplorerDefaultClosedPeriodCacheTTL
+	}
+	if awsSpcConfig.CostExplorerCacheOpenPeriodTTL != nil {
+		return time.Duration(*awsSpcConfig.CostExplorerCacheOpenPeriodTTL) * time.Second
+	}
+	return costExplorerDefaultOpenPeriodCacheTTL
+}
+
+func costExplorerCacheFile(dir string, connectionName string, operation string, params interface{}) (string, error) {
+	key, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(key)
+	return filepath.Join(dir, connectionName, operation+"-"+hex.EncodeToString(hash[:])+".json"), nil
+}
+
+func readCostExplorerCacheFile(ctx context.Context, cacheFile string) *costexplorer.GetCostAndUsageOutput {
+	data, err := os.ReadFile(cacheFile)
+	if err != nil {
+		return nil
+	}
+
+	var entry costExplorerCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		plugin.Logger(ctx).Warn("readCostExplorerCacheFile", "cache_read_error", err, "file", cacheFile)
+		return nil
+	}
+	if time.Now().After(entry.Expires) {
+		return nil
+	}
+	return entry.Output
+}
+
+func writeCostExplorerCacheFile(cacheFile string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so concurrent readers never see a
+	// partially written file
+	tmpFile := fmt.Sprintf("%s.%d.tmp", cacheFile, time.Now().UnixNano())
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, cacheFile)
+}
+
+//// REQUEST BUDGET
+
+type costExplorerRequestCount struct {
+	Date     string
+	Requests int
+}
+
+var costExplorerRequestCounts = struct {
+	sync.Mutex
+	counts map[string]*costExplorerRequestCount
+}{counts: map[string]*costExplorerRequestCount{}}
+
+// costExplorerRequestBudget returns a client option that refuses requests
+// once the connection's daily request limit is reached.
+func costExplorerRequestBudget(d *plugin.QueryData) func(*costexplorer.Options) {
+	return func(o *costexplorer.Options) {
+		awsSpcConfig := GetConfig(d.Connection)
+		if awsSpcConfig.CostExplorerDailyRequestLimit == nil {
+			return
+		}
+		limit := *awsSpcConfig.CostExplorerDailyRequestLimit
+		connectionName := d.Connection.Name
+
+		budgetMiddleware := middleware.InitializeMiddlewareFunc("CostExplorerRequestBudget", func(ctx context.Context, input middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			if err := consumeCostExplorerRequest(awsSpcConfig, connectionName, limit); err != nil {
+				return middleware.InitializeOutput{}, middleware.Metadata{}, err
+			}
+			return next.HandleInitialize(ctx, input)
+		})
+
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			return stack.Initialize.Add(budgetMiddleware, middleware.Before)
+		})
+	}
+}
+
+// consumeCostExplorerRequest counts a request against the connection's budget
+// for the current UTC day. The count is kept in the cache directory, if there
+// is one, so it survives plugin restarts.
+func consumeCostExplorerRequest(awsSpcConfig awsConfig, connectionName string, limit int) error {
+	costExplorerRequestCounts.Lock()
+	defer costExplorerRequestCounts.Unlock()
+
+	today := time.Now().UTC().Format("2006-01-02")
+
+	var countFile string
+	if awsSpcConfig.CostExplorerCacheDir != nil && *awsSpcConfig.CostExplorerCacheDir != "" {
+		countFile = filepath.Join(*awsSpcConfig.CostExplorerCacheDir, connectionName, "request-count.json")
+	}
+
+	count := costExplorerRequestCounts.counts[connectionName]
+	if count == nil && countFile != "" {
+		if data, err := os.ReadFile(countFile); err == nil {
+			count = &costExplorerRequestCount{}
+			if err := json.Unmarshal(data, count); err != nil {
+				count = nil
+			}
+		}
+	}
+	if count == nil || count.Date != today {
+		count = &costExplorerRequestCount{Date: today}
+	}
+	costExplorerRequestCounts.counts[connectionName] = count
+
+	if count.Requests >= limit {
+		return fmt.Errorf("connection %s has used its daily budget of %d Cost Explorer requests (cost_explorer_daily_request_limit), further requests are refused until 00:00 UTC", connectionName, limit)
+	}
+	count.Requests++
+
+	if countFile != "" {
+		if err := writeCostExplorerCacheFile(countFile, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
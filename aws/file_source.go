@@ -0,0 +1,294 @@
+package aws
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// fileSource lists and opens data files, such as reports and logs, that are
+// read either from an S3 bucket or from the local file system.
+type fileSource struct {
+	list func() ([]string, error)
+	open func(name string) (io.ReadCloser, error)
+	// openAt opens a file for random access, e.g. to read a Parquet file
+	// footer and then only the column chunks it points to, and returns its size
+	openAt func(name string) (readerAtCloser, int64, error)
+}
+
+type readerAtCloser interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// openDecompressed opens a file, decompressing it if it is compressed with
+// gzip, zstd or bzip2. Files are not always named after their compression,
+// e.g. S3 server access logs, so it is detected from the magic bytes.
+func (s *fileSource) openDecompressed(name string) (io.ReadCloser, error) {
+	body, err := s.open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	buffered := bufio.NewReader(body)
+	magic, _ := buffered.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		return &decompressedReader{Reader: gz, closers: []io.Closer{gz, body}}, nil
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		decoder, err := zstd.NewReader(buffered)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		reader := decoder.IOReadCloser()
+		return &decompressedReader{Reader: reader, closers: []io.Closer{reader, body}}, nil
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return &decompressedReader{Reader: bzip2.NewReader(buffered), closers: []io.Closer{body}}, nil
+	}
+	return &decompressedReader{Reader: buffered, closers: []io.Closer{body}}, nil
+}
+
+// decompressedReader closes the decompressor and the file it reads from
+type decompressedReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *decompressedReader) Close() error {
+	var err error
+	for _, closer := range r.closers {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+func newS3FileSource(ctx context.Context, d *plugin.QueryData, svc *s3.Client, bucketName string, prefix string) *fileSource {
+	return &fileSource{
+		list: func() ([]string, error) {
+			input := &s3.ListObjectsV2Input{
+				Bucket: aws.String(bucketName),
+			}
+			if prefix != "" {
+				input.Prefix = aws.String(prefix)
+			}
+
+			var keys []string
+			paginator := s3.NewListObjectsV2Paginator(svc, input)
+			for paginator.HasMorePages() {
+				// apply rate limiting
+				d.WaitForListRateLimit(ctx)
+
+				output, err := paginator.NextPage(ctx)
+				if err != nil {
+					return nil, err
+				}
+				for _, object := range output.Contents {
+					keys = append(keys, aws.ToString(object.Key))
+				}
+			}
+			return keys, nil
+		},
+		open: func(key string) (io.ReadCloser, error) {
+			output, err := svc.GetObject(ctx, &s3.GetObjectInput{
+				Bucket: aws.String(bucketName),
+				Key:    aws.String(key),
+			})
+			if err != nil {
+				return nil, err
+			}
+			return output.Body, nil
+		},
+		openAt: func(key string) (readerAtCloser, int64, error) {
+			output, err := svc.HeadObject(ctx, &s3.HeadObjectInput{
+				Bucket: aws.String(bucketName),
+				Key:    aws.String(key),
+			})
+			if err != nil {
+				return nil, 0, err
+			}
+			return &s3ObjectReaderAt{ctx: ctx, svc: svc, bucketName: bucketName, key: key}, output.ContentLength, nil
+		},
+	}
+}
+
+// s3ObjectReaderAt reads ranges of an object with ranged GetObject requests
+type s3ObjectReaderAt struct {
+	ctx        context.Context
+	svc        *s3.Client
+	bucketName string
+	key        string
+}
+
+func (r *s3ObjectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	output, err := r.svc.GetObject(r.ctx, &s3.GetObjectInput{
+		Bucket: aws.String(r.bucketName),
+		Key:    aws.String(r.key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1)),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer output.Body.Close()
+
+	n, err := io.ReadFull(output.Body, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (r *s3ObjectReaderAt) Close() error {
+	return nil
+}
+
+func newLocalFileSource(root string) *fileSource {
+	return &fileSource{
+		list: func() ([]string, error) {
+			var names []string
+			err := filepath.WalkDir(root, func(name string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !entry.IsDir() {
+					names = append(names, filepath.ToSlash(name))
+				}
+				return nil
+			})
+			return names, err
+		},
+		open: func(name string) (io.ReadCloser, error) {
+			return os.Open(filepath.FromSlash(name))
+		},
+		openAt: func(name string) (readerAtCloser, int64, error) {
+			file, err := os.Open(filepath.FromSlash(name))
+			if err != nil {
+				return nil, 0, err
+			}
+			info, err := file.Stat()
+			if err != nil {
+				file.Close()
+				return nil, 0, err
+			}
+			return file, info.Size(), nil
+		},
+	}
+}
+
+// newAllowedLocalFileSource returns a file source for a local directory given
+// in a qual. The directory must be within one of the local_file_paths of the
+// connection config, so that queries cannot read any file the plugin can.
+func newAllowedLocalFileSource(d *plugin.QueryData, dir string) (*fileSource, error) {
+	if !isAllowedLocalPath(GetConfig(d.Connection).LocalFilePaths, dir) {
+		return nil, fmt.Errorf("path %s is not within the local_file_paths of the connection config", dir)
+	}
+	return newLocalFileSource(dir), nil
+}
+
+// isAllowedLocalPath returns whether a path is within one of the allowed
+// paths, once symbolic links are resolved
+func isAllowedLocalPath(allowedPaths []string, name string) bool {
+	resolved, err := resolveLocalPath(name)
+	if err != nil {
+		return false
+	}
+	for _, allowedPath := range allowedPaths {
+		root, err := resolveLocalPath(allowedPath)
+		if err != nil {
+			continue
+		}
+		relative, err := filepath.Rel(root, resolved)
+		if err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func resolveLocalPath(name string) (string, error) {
+	absolute, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(absolute)
+}
+
+// newFileSourceForPath returns a file source for a local path, or for an S3
+// bucket and prefix given as s3://bucket/prefix.
+func newFileSourceForPath(ctx context.Context, d *plugin.QueryData, location string) (*fileSource, error) {
+	if !strings.HasPrefix(location, "s3://") {
+		return newLocalFileSource(location), nil
+	}
+
+	bucketName, prefix, _ := strings.Cut(strings.TrimPrefix(location, "s3://"), "/")
+	region, err := getS3BucketRegion(ctx, d, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	svc, err := S3Client(ctx, d, region)
+	if err != nil {
+		return nil, err
+	}
+	return newS3FileSource(ctx, d, svc, bucketName, prefix), nil
+}
+
+// getS3BucketRegion returns the region of a bucket that is not necessarily
+// owned by the connection's account.
+func getS3BucketRegion(ctx context.Context, d *plugin.QueryData, bucketName string) (string, error) {
+	cacheKey := "getS3BucketRegion" + bucketName
+	if cachedData, ok := d.ConnectionManager.Cache.Get(cacheKey); ok {
+		return cachedData.(string), nil
+	}
+
+	clientRegion, err := getDefaultRegion(ctx, d, nil)
+	if err != nil {
+		return "", err
+	}
+	svc, err := S3Client(ctx, d, clientRegion)
+	if err != nil {
+		return "", err
+	}
+
+	location, err := svc.GetBucketLocation(ctx, &s3.GetBucketLocationInput{Bucket: aws.String(bucketName)})
+	if err != nil {
+		return "", err
+	}
+
+	// Buckets in us-east-1 have a LocationConstraint of null, and buckets in
+	// eu-west-1 may return the legacy EU location
+	region := string(location.LocationConstraint)
+	switch region {
+	case "":
+		region = "us-east-1"
+	case "EU":
+		region = "eu-west-1"
+	}
+
+	d.ConnectionManager.Cache.Set(cacheKey, region)
+	return region, nil
+}
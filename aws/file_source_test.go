@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsAllowedLocalPath(t *testing.T) {
+	root := t.TempDir()
+	logs := filepath.Join(root, "logs")
+	other := filepath.Join(root, "other")
+	for _, dir := range []string{filepath.Join(logs, "alb"), other} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(other, filepath.Join(logs, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name         string
+		allowedPaths []string
+		path         string
+		want         bool
+	}{
+		{"no allowed paths", nil, logs, false},
+		{"allowed path", []string{logs}, logs, true},
+		{"subdirectory", []string{logs}, filepath.Join(logs, "alb"), true},
+		{"allowed path with trailing separator", []string{logs + string(filepath.Separator)}, filepath.Join(logs, "alb"), true},
+		{"sibling directory", []string{logs}, other, false},
+		{"parent directory", []string{logs}, root, false},
+		{"dot dot", []string{logs}, filepath.Join(logs, "alb", "..", "..", "other"), false},
+		{"symbolic link out", []string{logs}, filepath.Join(logs, "escape"), false},
+		{"missing directory", []string{logs}, filepath.Join(logs, "missing"), false},
+		{"one of several", []string{filepath.Join(root, "missing"), other}, other, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := isAllowedLocalPath(c.allowedPaths, c.path); got != c.want {
+				t.Errorf("isAllowedLocalPath(%q, %q) = %v, want %v", c.allowedPaths, c.path, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,519 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+const parquetReadBufferSize = 1024 * 1024
+
+// readParquetFile reads the records of a Parquet file as maps of column name
+// to value. Values of logical types are converted to their Go equivalents:
+// timestamps and dates to time.Time, decimals to float64, lists to slices and
+// maps to maps. It stops once handle returns false.
+func readParquetFile(source *fileSource, name string, handle func(record map[string]interface{}) bool) error {
+	return readParquetFileWhere(source, name, nil, nil, func(_ int64, record map[string]interface{}) bool {
+		return handle(record)
+	})
+}
+
+// readParquetFileWhere reads the records of a Parquet file like
+// readParquetFile, with the position of each record in the file. Only the
+// columns of the given top level fields, and of the fields of filters, are
+// read; all of them are read if there are none. Row groups whose column
+// statistics show that no record has the value of a filter are skipped, so
+// handle may still be given records that do not match the filters.
+func readParquetFileWhere(source *fileSource, name string, fields []string, filters map[string]string, handle func(rowNumber int64, record map[string]interface{}) bool) error {
+	body, size, err := source.openAt(name)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// Files in S3 are read with a request per buffer, so the buffer is large
+	// enough for most pages
+	file, err := parquet.OpenFile(body, size, parquet.SkipPageIndex(true), parquet.SkipBloomFilters(true), parquet.ReadBufferSize(parquetReadBufferSize))
+	if err != nil {
+		return fmt.Errorf("failed to open Parquet file %s: %v", name, err)
+	}
+
+	schema := file.Schema()
+	var conversion parquet.Conversion
+	if projected := parquetProjectedSchema(schema, fields, filters); projected != nil {
+		conversion, err = parquet.Convert(projected, schema)
+		if err != nil {
+			return fmt.Errorf("failed to read Parquet file %s: %v", name, err)
+		}
+	}
+
+	var rowNumber int64
+	for i, rowGroup := range file.RowGroups() {
+		if parquetRowGroupExcludes(schema, file.Metadata().RowGroups[i], filters) {
+			rowNumber += rowGroup.NumRows()
+			continue
+		}
+
+		// Converted row groups only load the pages of the projected columns
+		readSchema := schema
+		if conversion != nil {
+			rowGroup = parquet.ConvertRowGroup(rowGroup, conversion)
+			readSchema = conversion.Schema()
+		}
+		more, err := readParquetRowGroup(readSchema, rowGroup, func(record map[string]interface{}) bool {
+			rowNumber++
+			return handle(rowNumber, record)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read Parquet file %s: %v", name, err)
+		}
+		if !more {
+			return nil
+		}
+	}
+	return nil
+}
+
+// parquetProjectedSchema returns the schema of the top level fields of a
+// file's schema that are either given or filtered on, or nil if the whole
+// schema is needed
+func parquetProjectedSchema(schema *parquet.Schema, fields []string, filters map[string]string) *parquet.Schema {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	wanted := map[string]bool{}
+	for _, field := range fields {
+		wanted[field] = true
+	}
+	for field := range filters {
+		wanted[field] = true
+	}
+
+	group := parquet.Group{}
+	for _, field := range schema.Fields() {
+		if wanted[field.Name()] {
+			group[field.Name()] = field
+		}
+	}
+	// Rows are still counted when none of the fields are in the file
+	if len(group) == 0 || len(group) == len(schema.Fields()) {
+		return nil
+	}
+	return parquet.NewSchema(schema.Name(), group)
+}
+
+// parquetRowGroupExcludes returns true if the minimum and maximum values of a
+// row group's columns show that none of its records have the value of one of
+// the filters. Only top level string, integer and boolean columns are
+// checked, since their values are compared like the text parquetString
+// formats them as.
+func parquetRowGroupExcludes(schema *parquet.Schema, rowGroup format.RowGroup, filters map[string]string) bool {
+	for field, value := range filters {
+		// Null values are formatted as empty text, and are not in the statistics
+		if value == "" {
+			continue
+		}
+		leaf, ok := schema.Lookup(field)
+		if !ok || leaf.MaxRepetitionLevel > 0 || leaf.ColumnIndex >= len(rowGroup.Columns) || !parquetComparable(leaf.Node) {
+			continue
+		}
+
+		typ := leaf.Node.Type()
+		statistics := rowGroup.Columns[leaf.ColumnIndex].MetaData.Statistics
+		low, lowOk := parquetStatisticsValue(typ.Kind(), statistics.MinValue)
+		high, highOk := parquetStatisticsValue(typ.Kind(), statistics.MaxValue)
+		if !lowOk || !highOk {
+			continue
+		}
+		filterValue, err := typ.ConvertValue(parquet.ValueOf(value), parquet.String().Type())
+		if err != nil {
+			continue
+		}
+		if typ.Compare(filterValue, low) < 0 || typ.Compare(filterValue, high) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// parquetComparable returns true for the leaf columns whose values are read
+// as strings, integers or booleans
+func parquetComparable(node parquet.Node) bool {
+	if !node.Leaf() {
+		return false
+	}
+	typ := node.Type()
+	switch typ.Kind() {
+	case parquet.Boolean, parquet.Int32, parquet.Int64, parquet.ByteArray:
+	default:
+		return false
+	}
+
+	if logicalType := typ.LogicalType(); logicalType != nil {
+		return logicalType.UTF8 != nil || logicalType.Integer != nil || logicalType.Enum != nil
+	}
+	convertedType := typ.ConvertedType()
+	return convertedType == nil || *convertedType == deprecated.UTF8
+}
+
+// parquetStatisticsValue decodes the plain encoded minimum or maximum value of
+// a column chunk's statistics
+func parquetStatisticsValue(kind parquet.Kind, data []byte) (parquet.Value, bool) {
+	// Kind.Value panics on values of the wrong size, and an empty minimum
+	// can't be told apart from a missing one
+	sizes := map[parquet.Kind]int{parquet.Boolean: 1, parquet.Int32: 4, parquet.Int64: 8}
+	if size, ok := sizes[kind]; len(data) == 0 || (ok && len(data) != size) {
+		return parquet.Value{}, false
+	}
+	return kind.Value(data), true
+}
+
+// readParquetRowGroup reads the rows of a row group, which follow the schema,
+// as records. Rows are read as column values and assembled from the schema,
+// since decoding them into maps with the reflection of parquet-go fails for
+// MAP columns. It returns false once handle does.
+func readParquetRowGroup(schema parquet.Node, rowGroup parquet.RowGroup, handle func(record map[string]interface{}) bool) (bool, error) {
+	rows := rowGroup.Rows()
+	defer rows.Close()
+
+	numColumns := parquetLeafCount(schema)
+	buffer := make([]parquet.Row, 64)
+	for {
+		n, err := rows.ReadRows(buffer)
+		for _, row := range buffer[:n] {
+			if !handle(parquetRecord(schema, numColumns, row)) {
+				return false, nil
+			}
+		}
+		if err == io.EOF {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// parquetRecord assembles the values of a row into a record of the schema's
+// top level fields
+func parquetRecord(schema parquet.Node, numColumns int, row parquet.Row) map[string]interface{} {
+	columns := make([][]parquet.Value, numColumns)
+	for _, value := range row {
+		if column := value.Column(); column >= 0 && column < numColumns {
+			columns[column] = append(columns[column], value)
+		}
+	}
+
+	record := map[string]interface{}{}
+	start := 0
+	for _, field := range schema.Fields() {
+		end := start + parquetLeafCount(field)
+		record[field.Name()] = parquetValue(field, parquetAssemble(field, columns[start:end], 0, 0))
+		start = end
+	}
+	return record
+}
+
+// parquetAssemble assembles the value of a field from the values of its leaf
+// columns, using their repetition and definition levels. Repeated fields are
+// assembled as slices and groups as maps of field name to value.
+func parquetAssemble(node parquet.Node, columns [][]parquet.Value, definitionLevel int, repetitionLevel int) interface{} {
+	if !node.Repeated() {
+		if node.Optional() {
+			definitionLevel++
+		}
+		return parquetAssembleOne(node, columns, definitionLevel, repetitionLevel)
+	}
+
+	definitionLevel++
+	repetitionLevel++
+	if len(columns) == 0 || len(columns[0]) == 0 || columns[0][0].DefinitionLevel() < definitionLevel {
+		return []interface{}{}
+	}
+
+	// A value at the field's repetition level starts its next element
+	elements := make([][][]parquet.Value, 0, 1)
+	for c, values := range columns {
+		e := 0
+		for i, value := range values {
+			if i > 0 && value.RepetitionLevel() == repetitionLevel {
+				e++
+			}
+			for len(elements) <= e {
+				elements = append(elements, make([][]parquet.Value, len(columns)))
+			}
+			elements[e][c] = append(elements[e][c], value)
+		}
+	}
+
+	items := make([]interface{}, 0, len(elements))
+	for _, element := range elements {
+		items = append(items, parquetAssembleOne(node, element, definitionLevel, repetitionLevel))
+	}
+	return items
+}
+
+// parquetAssembleOne assembles a single, non-repeated, value of a field
+func parquetAssembleOne(node parquet.Node, columns [][]parquet.Value, definitionLevel int, repetitionLevel int) interface{} {
+	// Values of a null field are defined below its definition level
+	if len(columns) == 0 || len(columns[0]) == 0 || columns[0][0].DefinitionLevel() < definitionLevel {
+		return nil
+	}
+
+	if node.Leaf() {
+		return parquetLeafValue(columns[0][0])
+	}
+
+	group := map[string]interface{}{}
+	start := 0
+	for _, field := range node.Fields() {
+		end := start + parquetLeafCount(field)
+		group[field.Name()] = parquetAssemble(field, columns[start:end], definitionLevel, repetitionLevel)
+		start = end
+	}
+	return group
+}
+
+// parquetLeafValue returns the Go value of a leaf column value
+func parquetLeafValue(value parquet.Value) interface{} {
+	if value.IsNull() {
+		return nil
+	}
+	switch value.Kind() {
+	case parquet.Boolean:
+		return value.Boolean()
+	case parquet.Int32:
+		return value.Int32()
+	case parquet.Int64:
+		return value.Int64()
+	case parquet.Int96:
+		return value.Int96()
+	case parquet.Float:
+		return value.Float()
+	case parquet.Double:
+		return value.Double()
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		// The buffers of values are reused for the next rows
+		return append([]byte(nil), value.ByteArray()...)
+	}
+	return nil
+}
+
+// parquetLeafCount returns the number of leaf columns of a field
+func parquetLeafCount(node parquet.Node) int {
+	if node.Leaf() {
+		return 1
+	}
+	count := 0
+	for _, field := range node.Fields() {
+		count += parquetLeafCount(field)
+	}
+	return count
+}
+
+// parquetValue converts a value read from a Parquet column to the Go value of
+// the column's logical type
+func parquetValue(node parquet.Node, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	logicalType := node.Type().LogicalType()
+	convertedType := node.Type().ConvertedType()
+	isConverted := func(t deprecated.ConvertedType) bool {
+		return convertedType != nil && *convertedType == t
+	}
+
+	switch {
+	case (logicalType != nil && logicalType.Map != nil) || isConverted(deprecated.Map):
+		return parquetMapValue(node, value)
+
+	// The LIST annotation is not always kept on the groups of a file's schema,
+	// so lists are also recognised by their single repeated field
+	case (logicalType != nil && logicalType.List != nil) || isConverted(deprecated.List),
+		!node.Leaf() && len(node.Fields()) == 1 && node.Fields()[0].Repeated():
+		return parquetListValue(node, value)
+
+	case !node.Leaf():
+		group, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		for _, field := range node.Fields() {
+			group[field.Name()] = parquetValue(field, group[field.Name()])
+		}
+		return group
+
+	case logicalType != nil && logicalType.Timestamp != nil:
+		return parquetTimestampValue(value, logicalType.Timestamp.Unit.Micros != nil, logicalType.Timestamp.Unit.Nanos != nil)
+
+	case isConverted(deprecated.TimestampMillis), isConverted(deprecated.TimestampMicros):
+		return parquetTimestampValue(value, isConverted(deprecated.TimestampMicros), false)
+
+	case (logicalType != nil && logicalType.Date != nil) || isConverted(deprecated.Date):
+		if days, ok := parquetInt64(value); ok {
+			return time.Unix(days*24*60*60, 0).UTC()
+		}
+
+	case logicalType != nil && logicalType.Decimal != nil:
+		return parquetDecimalValue(value, logicalType.Decimal.Scale)
+	}
+
+	switch v := value.(type) {
+	case deprecated.Int96:
+		return parquetInt96Time(v)
+	case []byte:
+		return string(v)
+	}
+	return value
+}
+
+// parquetListValue flattens the list, element groups of a LIST column
+func parquetListValue(node parquet.Node, value interface{}) interface{} {
+	group, ok := value.(map[string]interface{})
+	if !ok || len(node.Fields()) != 1 {
+		return value
+	}
+	repeated := node.Fields()[0]
+	items, ok := group[repeated.Name()].([]interface{})
+	if !ok {
+		return value
+	}
+
+	list := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		// The repeated group usually holds a single element field, but older
+		// writers may repeat the element itself
+		if element, ok := item.(map[string]interface{}); ok && len(repeated.Fields()) == 1 {
+			field := repeated.Fields()[0]
+			list = append(list, parquetValue(field, element[field.Name()]))
+		} else {
+			list = append(list, parquetValue(repeated, item))
+		}
+	}
+	return list
+}
+
+// parquetMapValue converts the key_value entries of a MAP column to a map of
+// key to value
+func parquetMapValue(node parquet.Node, value interface{}) interface{} {
+	group, ok := value.(map[string]interface{})
+	if !ok || len(node.Fields()) != 1 || len(node.Fields()[0].Fields()) != 2 {
+		return value
+	}
+	entriesField := node.Fields()[0]
+	entries, ok := group[entriesField.Name()].([]interface{})
+	if !ok {
+		return value
+	}
+	keyField, valueField := entriesField.Fields()[0], entriesField.Fields()[1]
+
+	result := make(map[string]interface{}, len(entries))
+	for _, item := range entries {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key := parquetString(parquetValue(keyField, entry[keyField.Name()]))
+		result[key] = parquetValue(valueField, entry[valueField.Name()])
+	}
+	return result
+}
+
+func parquetTimestampValue(value interface{}, micros bool, nanos bool) interface{} {
+	if v, ok := value.(deprecated.Int96); ok {
+		return parquetInt96Time(v)
+	}
+	ts, ok := parquetInt64(value)
+	if !ok {
+		return value
+	}
+	switch {
+	case nanos:
+		return time.Unix(0, ts).UTC()
+	case micros:
+		return time.UnixMicro(ts).UTC()
+	}
+	return time.UnixMilli(ts).UTC()
+}
+
+// parquetInt96Time converts an INT96 timestamp, written by older writers,
+// which holds the nanoseconds of the day followed by the Julian day
+func parquetInt96Time(v deprecated.Int96) time.Time {
+	nanos := int64(v[1])<<32 | int64(v[0])
+	days := int64(v[2]) - 2440588
+	return time.Unix(days*24*60*60, nanos).UTC()
+}
+
+// parquetDecimalValue converts an unscaled decimal, stored as an integer or
+// big-endian two's complement bytes, to a float64
+func parquetDecimalValue(value interface{}, scale int32) interface{} {
+	unscaled := new(big.Int)
+	switch v := value.(type) {
+	case []byte:
+		unscaled.SetBytes(v)
+		if len(v) > 0 && v[0]&0x80 != 0 {
+			unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(v)*8)))
+		}
+	case string:
+		return parquetDecimalValue([]byte(v), scale)
+	default:
+		n, ok := parquetInt64(value)
+		if !ok {
+			return value
+		}
+		unscaled.SetInt64(n)
+	}
+
+	result, _ := new(big.Float).Quo(new(big.Float).SetInt(unscaled), big.NewFloat(math.Pow10(int(scale)))).Float64()
+	return result
+}
+
+func parquetInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// parquetString formats a value read with readParquetFile as the text a
+// CSV file would hold for it
+func parquetString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		// Nested values are formatted as JSON, like a CSV export of them
+		data, err := json.Marshal(v)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,184 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+type parquetTestAddress struct {
+	City string `parquet:"city"`
+	Zip  *int32 `parquet:"zip,optional"`
+}
+
+type parquetTestRecord struct {
+	Id           int64              `parquet:"id"`
+	Name         string             `parquet:"name"`
+	Cost         *float64           `parquet:"cost,optional"`
+	UsageStart   time.Time          `parquet:"usage_start,timestamp(millisecond)"`
+	ResourceTags map[string]string  `parquet:"resource_tags"`
+	Labels       []string           `parquet:"labels,list"`
+	Address      parquetTestAddress `parquet:"address"`
+}
+
+func TestReadParquetFile(t *testing.T) {
+	cost := 1.5
+	zip := int32(98101)
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []parquetTestRecord{
+		{
+			Id:           1,
+			Name:         "first",
+			Cost:         &cost,
+			UsageStart:   start,
+			ResourceTags: map[string]string{"user:team": "data", "user:env": "prod"},
+			Labels:       []string{"a", "b"},
+			Address:      parquetTestAddress{City: "Seattle", Zip: &zip},
+		},
+		{
+			Id:         2,
+			Name:       "second",
+			UsageStart: start,
+			Address:    parquetTestAddress{City: "Paris"},
+		},
+	}
+
+	name := filepath.Join(t.TempDir(), "records.parquet")
+	file, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parquet.Write(file, rows); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var records []map[string]interface{}
+	err = readParquetFile(newLocalFileSource(filepath.Dir(name)), name, func(record map[string]interface{}) bool {
+		records = append(records, record)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("readParquetFile() error = %v", err)
+	}
+
+	want := []map[string]interface{}{
+		{
+			"id":            int64(1),
+			"name":          "first",
+			"cost":          1.5,
+			"usage_start":   start,
+			"resource_tags": map[string]interface{}{"user:team": "data", "user:env": "prod"},
+			"labels":        []interface{}{"a", "b"},
+			"address":       map[string]interface{}{"city": "Seattle", "zip": int32(98101)},
+		},
+		{
+			"id":            int64(2),
+			"name":          "second",
+			"cost":          nil,
+			"usage_start":   start,
+			"resource_tags": map[string]interface{}{},
+			"labels":        []interface{}{},
+			"address":       map[string]interface{}{"city": "Paris", "zip": nil},
+		},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("readParquetFile() records = %#v, want %#v", records, want)
+	}
+}
+
+func TestReadParquetFileStops(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "records.parquet")
+	file, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parquet.Write(file, []parquetTestRecord{{Id: 1}, {Id: 2}, {Id: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	err = readParquetFile(newLocalFileSource(filepath.Dir(name)), name, func(record map[string]interface{}) bool {
+		count++
+		return count < 2
+	})
+	if err != nil {
+		t.Fatalf("readParquetFile() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("readParquetFile() read %d records, want 2", count)
+	}
+}
+
+func TestReadParquetFileWhere(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "records.parquet")
+	file, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []parquetTestRecord
+	for i, recordName := range []string{"a", "b", "c", "d", "e", "f"} {
+		rows = append(rows, parquetTestRecord{Id: int64(i + 1), Name: recordName, Labels: []string{recordName}})
+	}
+	if err := parquet.Write(file, rows, parquet.MaxRowsPerRowGroup(2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		fields   []string
+		filters  map[string]string
+		want     []int64
+		fieldsOf []string
+	}{
+		{"all fields", nil, nil, []int64{1, 2, 3, 4, 5, 6}, []string{"address", "cost", "id", "labels", "name", "resource_tags", "usage_start"}},
+		{"projected fields", []string{"id", "missing"}, nil, []int64{1, 2, 3, 4, 5, 6}, []string{"id"}},
+		{"filtered string", []string{"id"}, map[string]string{"name": "d"}, []int64{3, 4}, []string{"id", "name"}},
+		{"filtered integer", []string{"name"}, map[string]string{"id": "5"}, []int64{5, 6}, []string{"id", "name"}},
+		{"value outside every row group", []string{"id"}, map[string]string{"name": "z"}, nil, nil},
+		{"unparsable integer", []string{"id"}, map[string]string{"id": "five"}, []int64{1, 2, 3, 4, 5, 6}, []string{"id"}},
+		{"empty value", []string{"id"}, map[string]string{"name": ""}, []int64{1, 2, 3, 4, 5, 6}, []string{"id", "name"}},
+		{"repeated field", []string{"id"}, map[string]string{"labels": "z"}, []int64{1, 2, 3, 4, 5, 6}, []string{"id", "labels"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var rowNumbers []int64
+			var fieldsOf []string
+			err := readParquetFileWhere(newLocalFileSource(filepath.Dir(name)), name, c.fields, c.filters, func(rowNumber int64, record map[string]interface{}) bool {
+				if record["id"] != nil && record["id"] != rowNumber {
+					t.Errorf("record %v has row number %d", record["id"], rowNumber)
+				}
+				rowNumbers = append(rowNumbers, rowNumber)
+				fieldsOf = fieldsOf[:0]
+				for field := range record {
+					fieldsOf = append(fieldsOf, field)
+				}
+				sort.Strings(fieldsOf)
+				return true
+			})
+			if err != nil {
+				t.Fatalf("readParquetFileWhere() error = %v", err)
+			}
+			if !reflect.DeepEqual(rowNumbers, c.want) {
+				t.Errorf("readParquetFileWhere() row numbers = %v, want %v", rowNumbers, c.want)
+			}
+			if len(c.want) > 0 && !reflect.DeepEqual(fieldsOf, c.fieldsOf) {
+				t.Errorf("readParquetFileWhere() fields = %v, want %v", fieldsOf, c.fieldsOf)
+			}
+		})
+	}
+}
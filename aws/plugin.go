@@ -83,10 +83,15 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 			"aws_backup_report_plan":                                       tableAwsBackupReportPlan(ctx),
 			"aws_backup_selection":                                         tableAwsBackupSelection(ctx),
 			"aws_backup_vault":                                             tableAwsBackupVault(ctx),
+			"aws_budgets_budget":                                           tableAwsBudgetsBudget(ctx),
+			"aws_budgets_budget_action":                                    tableAwsBudgetsBudgetAction(ctx),
+			"aws_budgets_budget_notification":                              tableAwsBudgetsBudgetNotification(ctx),
+			"aws_budgets_budget_performance_history":                       tableAwsBudgetsBudgetPerformanceHistory(ctx),
 			"aws_cloudcontrol_resource":                                    tableAwsCloudControlResource(ctx),
 			"aws_cloudformation_stack":                                     tableAwsCloudFormationStack(ctx),
 			"aws_cloudformation_stack_resource":                            tableAwsCloudFormationStackResource(ctx),
 			"aws_cloudformation_stack_set":                                 tableAwsCloudFormationStackSet(ctx),
+			"aws_cloudfront_access_log":                                    tableAwsCloudFrontAccessLog(ctx),
 			"aws_cloudfront_cache_policy":                                  tableAwsCloudFrontCachePolicy(ctx),
 			"aws_cloudfront_distribution":                                  tableAwsCloudFrontDistribution(ctx),
 			"aws_cloudfront_function":                                      tableAwsCloudFrontFunction(ctx),
@@ -99,11 +104,13 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 			"aws_cloudtrail_import":                                        tableAwsCloudtrailImport(ctx),
 			"aws_cloudtrail_lookup_event":                                  tableAwsCloudtrailLookupEvent(ctx),
 			"aws_cloudtrail_query":                                         tableAwsCloudTrailQuery(ctx),
+			"aws_cloudtrail_s3_event":                                      tableAwsCloudtrailS3Event(ctx),
 			"aws_cloudtrail_trail":                                         tableAwsCloudtrailTrail(ctx),
 			"aws_cloudtrail_trail_event":                                   tableAwsCloudtrailTrailEvent(ctx),
 			"aws_cloudwatch_alarm":                                         tableAwsCloudWatchAlarm(ctx),
 			"aws_cloudwatch_log_event":                                     tableAwsCloudwatchLogEvent(ctx),
 			"aws_cloudwatch_log_group":                                     tableAwsCloudwatchLogGroup(ctx),
+			"aws_cloudwatch_log_insights_query":                            tableAwsCloudwatchLogInsightsQuery(ctx),
 			"aws_cloudwatch_log_metric_filter":                             tableAwsCloudwatchLogMetricFilter(ctx),
 			"aws_cloudwatch_log_resource_policy":                           tableAwsCloudwatchLogResourcePolicy(ctx),
 			"aws_cloudwatch_log_stream":                                    tableAwsCloudwatchLogStream(ctx),
@@ -129,6 +136,9 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 			"aws_config_conformance_pack":                                  tableAwsConfigConformancePack(ctx),
 			"aws_config_retention_configuration":                           tableAwsConfigRetentionConfiguration(ctx),
 			"aws_config_rule":                                              tableAwsConfigRule(ctx),
+			"aws_cost_anomaly":                                             tableAwsCostAnomaly(ctx),
+			"aws_cost_anomaly_monitor":                                     tableAwsCostAnomalyMonitor(ctx),
+			"aws_cost_anomaly_subscription":                                tableAwsCostAnomalySubscription(ctx),
 			"aws_cost_by_account_daily":                                    tableAwsCostByLinkedAccountDaily(ctx),
 			"aws_cost_by_account_monthly":                                  tableAwsCostByLinkedAccountMonthly(ctx),
 			"aws_cost_by_record_type_daily":                                tableAwsCostByRecordTypeDaily(ctx),
@@ -138,9 +148,17 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 			"aws_cost_by_service_usage_type_daily":                         tableAwsCostByServiceUsageTypeDaily(ctx),
 			"aws_cost_by_service_usage_type_monthly":                       tableAwsCostByServiceUsageTypeMonthly(ctx),
 			"aws_cost_by_tag":                                              tableAwsCostByTag(ctx),
+			"aws_cost_explorer_query":                                      tableAwsCostExplorerQuery(ctx),
 			"aws_cost_forecast_daily":                                      tableAwsCostForecastDaily(ctx),
 			"aws_cost_forecast_monthly":                                    tableAwsCostForecastMonthly(ctx),
+			"aws_cost_reservation_coverage":                                tableAwsCostReservationCoverage(ctx),
+			"aws_cost_reservation_purchase_recommendation":                 tableAwsCostReservationPurchaseRecommendation(ctx),
+			"aws_cost_reservation_utilization":                             tableAwsCostReservationUtilization(ctx),
+			"aws_cost_savings_plans_coverage":                              tableAwsCostSavingsPlansCoverage(ctx),
+			"aws_cost_savings_plans_purchase_recommendation":               tableAwsCostSavingsPlansPurchaseRecommendation(ctx),
+			"aws_cost_savings_plans_utilization":                           tableAwsCostSavingsPlansUtilization(ctx),
 			"aws_cost_usage":                                               tableAwsCostAndUsage(ctx),
+			"aws_cost_usage_report":                                        tableAwsCostUsageReport(ctx),
 			"aws_dax_cluster":                                              tableAwsDaxCluster(ctx),
 			"aws_dax_parameter":                                            tableAwsDaxParameter(ctx),
 			"aws_dax_parameter_group":                                      tableAwsDaxParameterGroup(ctx),
@@ -177,6 +195,7 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 			"aws_ec2_ami":                                                  tableAwsEc2Ami(ctx),
 			"aws_ec2_ami_shared":                                           tableAwsEc2AmiShared(ctx),
 			"aws_ec2_application_load_balancer":                            tableAwsEc2ApplicationLoadBalancer(ctx),
+			"aws_ec2_application_load_balancer_access_log":                 tableAwsEc2ApplicationLoadBalancerAccessLog(ctx),
 			"aws_ec2_application_load_balancer_metric_request_count":       tableAwsEc2ApplicationLoadBalancerMetricRequestCount(ctx),
 			"aws_ec2_application_load_balancer_metric_request_count_daily": tableAwsEc2ApplicationLoadBalancerMetricRequestCountDaily(ctx),
 			"aws_ec2_autoscaling_group":                                    tableAwsEc2ASG(ctx),
@@ -186,6 +205,7 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 			"aws_ec2_gateway_load_balancer":                                tableAwsEc2GatewayLoadBalancer(ctx),
 			"aws_ec2_instance":                                             tableAwsEc2Instance(ctx),
 			"aws_ec2_instance_availability":                                tableAwsInstanceAvailability(ctx),
+			"aws_ec2_instance_cost_estimate":                               tableAwsEc2InstanceCostEstimate(ctx),
 			"aws_ec2_instance_metric_cpu_utilization":                      tableAwsEc2InstanceMetricCpuUtilization(ctx),
 			"aws_ec2_instance_metric_cpu_utilization_daily":                tableAwsEc2InstanceMetricCpuUtilizationDaily(ctx),
 			"aws_ec2_instance_metric_cpu_utilization_hourly":               tableAwsEc2InstanceMetricCpuUtilizationHourly(ctx),
@@ -199,6 +219,7 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 			"aws_ec2_managed_prefix_list_entry":                            tableAwsEc2ManagedPrefixListEntry(ctx),
 			"aws_ec2_network_interface":                                    tableAwsEc2NetworkInterface(ctx),
 			"aws_ec2_network_load_balancer":                                tableAwsEc2NetworkLoadBalancer(ctx),
+			"aws_ec2_network_load_balancer_access_log":                     tableAwsEc2NetworkLoadBalancerAccessLog(ctx),
 			"aws_ec2_network_load_balancer_metric_net_flow_count":          tableAwsEc2NetworkLoadBalancerMetricNetFlowCount(ctx),
 			"aws_ec2_network_load_balancer_metric_net_flow_count_daily":    tableAwsEc2NetworkLoadBalancerMetricNetFlowCountDaily(ctx),
 			"aws_ec2_regional_settings":                                    tableAwsEc2RegionalSettings(ctx),
@@ -404,9 +425,14 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 			"aws_s3_account_settings":                                      tableAwsS3AccountSettings(ctx),
 			"aws_s3_bucket":                                                tableAwsS3Bucket(ctx),
 			"aws_s3_bucket_intelligent_tiering_configuration":              tableAwsS3BucketIntelligentTieringConfiguration(ctx),
+			"aws_s3_bucket_inventory_configuration":                        tableAwsS3BucketInventoryConfiguration(ctx),
+			"aws_s3_inventory_object":                                      tableAwsS3InventoryObject(ctx),
 			"aws_s3_multi_region_access_point":                             tableAwsS3MultiRegionAccessPoint(ctx),
 			"aws_s3_object":                                                tableAwsS3Object(ctx),
+			"aws_s3_object_row":                                            tableAwsS3ObjectRow(ctx),
+			"aws_s3_object_select":                                         tableAwsS3ObjectSelect(ctx),
 			"aws_s3_object_version":                                        tableAwsS3ObjectVersion(ctx),
+			"aws_s3_server_access_log":                                     tableAwsS3ServerAccessLog(ctx),
 			"aws_sagemaker_app":                                            tableAwsSageMakerApp(ctx),
 			"aws_sagemaker_domain":                                         tableAwsSageMakerDomain(ctx),
 			"aws_sagemaker_endpoint_configuration":                         tableAwsSageMakerEndpointConfiguration(ctx),
@@ -481,9 +507,12 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 			"aws_vpc_nat_gateway_metric_bytes_out_to_destination":          tableAwsVpcNatGatewayMetricBytesOutToDestination(ctx),
 			"aws_vpc_network_acl":                                          tableAwsVpcNetworkACL(ctx),
 			"aws_vpc_peering_connection":                                   tableAwsVpcPeeringConnection(ctx),
+			"aws_vpc_reachability":                                         tableAwsVpcReachability(ctx),
 			"aws_vpc_route":                                                tableAwsVpcRoute(ctx),
+			"aws_vpc_route_lookup":                                         tableAwsVpcRouteLookup(ctx),
 			"aws_vpc_route_table":                                          tableAwsVpcRouteTable(ctx),
 			"aws_vpc_security_group":                                       tableAwsVpcSecurityGroup(ctx),
+			"aws_vpc_security_group_effective_ingress":                     tableAwsVpcSecurityGroupEffectiveIngress(ctx),
 			"aws_vpc_security_group_rule":                                  tableAwsVpcSecurityGroupRule(ctx),
 			"aws_vpc_subnet":                                               tableAwsVpcSubnet(ctx),
 			"aws_vpc_verified_access_endpoint":                             tableAwsVpcVerifiedAccessEndpoint(ctx),
@@ -503,6 +532,7 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 			"aws_wafv2_regex_pattern_set":                                  tableAwsWafv2RegexPatternSet(ctx),
 			"aws_wafv2_rule_group":                                         tableAwsWafv2RuleGroup(ctx),
 			"aws_wafv2_web_acl":                                            tableAwsWafv2WebAcl(ctx),
+			"aws_wafv2_web_acl_log":                                        tableAwsWafv2WebAclLog(ctx),
 			"aws_wellarchitected_answer":                                   tableAwsWellArchitectedAnswer(ctx),
 			"aws_wellarchitected_check_detail":                             tableAwsWellArchitectedCheckDetail(ctx),
 			"aws_wellarchitected_check_summary":                            tableAwsWellArchitectedCheckSummary(ctx),
@@ -522,5 +552,12 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 		},
 	}
 
+	// Connections can declare their own CloudWatch metric tables with
+	// cloudwatch_metric_tables, so each connection has its own schema
+	p.SchemaMode = plugin.SchemaModeDynamic
+	p.TableMapFunc = func(ctx context.Context, d *plugin.TableMapData) (map[string]*plugin.Table, error) {
+		return withCloudWatchMetricTables(ctx, d.Connection, p.TableMap)
+	}
+
 	return p
 }
@@ -0,0 +1,394 @@
+package aws
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// Bulk price list offer files, as downloaded from
+// https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/index.json, are
+// read from <pricing_offer_path>/<service_code>.json (or .csv), or from any
+// JSON or CSV file under <pricing_offer_path>/<service_code>/.
+
+// listPricingProductFromOfferFiles streams the price dimensions of the offer
+// files for the service_code qual that match the filters.
+func listPricingProductFromOfferFiles(ctx context.Context, d *plugin.QueryData, offerPath string, filters []types.Filter) (interface{}, error) {
+	serviceCode := d.EqualsQualString("service_code")
+
+	source, err := newFileSourceForPath(ctx, d, offerPath)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_pricing_product.listPricingProductFromOfferFiles", "source_error", err)
+		return nil, err
+	}
+
+	names, err := source.list()
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_pricing_product.listPricingProductFromOfferFiles", "list_error", err)
+		return nil, err
+	}
+
+	for _, name := range names {
+		if !isPricingOfferFile(offerPath, serviceCode, name) {
+			continue
+		}
+
+		done, err := streamPricingOfferFile(ctx, d, source, name, filters)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_pricing_product.listPricingProductFromOfferFiles", "read_error", err, "file", name)
+			return nil, fmt.Errorf("failed to read offer file %s: %v", name, err)
+		}
+		if done {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func isPricingOfferFile(offerPath string, serviceCode string, name string) bool {
+	ext := path.Ext(name)
+	if ext != ".json" && ext != ".csv" {
+		return false
+	}
+
+	root := strings.TrimSuffix(strings.TrimPrefix(offerPath, "s3://"), "/")
+	relative := strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+	if strings.HasPrefix(offerPath, "s3://") {
+		// S3 keys do not include the bucket name
+		_, prefix, _ := strings.Cut(root, "/")
+		relative = strings.TrimPrefix(strings.TrimPrefix(name, prefix), "/")
+	}
+
+	return strings.TrimSuffix(relative, ext) == serviceCode || strings.HasPrefix(relative, serviceCode+"/")
+}
+
+func streamPricingOfferFile(ctx context.Context, d *plugin.QueryData, source *fileSource, name string, filters []types.Filter) (bool, error) {
+	body, err := source.open(name)
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+
+	stream := func(item PriceOutput) bool {
+		d.StreamListItem(ctx, item)
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		return d.RowsRemaining(ctx) != 0
+	}
+
+	if path.Ext(name) == ".csv" {
+		return readPricingOfferCSV(body, filters, stream)
+	}
+	return readPricingOfferJSON(body, filters, stream)
+}
+
+// readPricingOfferJSON decodes a JSON offer file one product and one term at
+// a time, as offer files for services like EC2 are several gigabytes.
+// Products always precede terms in offer files, so only the products that
+// match the filters are kept to join with their terms. It returns true once
+// stream returns false.
+func readPricingOfferJSON(body io.Reader, filters []types.Filter, stream func(PriceOutput) bool) (bool, error) {
+	decoder := json.NewDecoder(body)
+	if err := expectPricingOfferDelim(decoder, '{'); err != nil {
+		return false, err
+	}
+
+	var version *string
+	var publicationDate *time.Time
+	var serviceCode *string
+	products := map[string]*Product{}
+
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return false, err
+		}
+
+		switch key {
+		case "version":
+			if err := decoder.Decode(&version); err != nil {
+				return false, err
+			}
+		case "publicationDate":
+			if err := decoder.Decode(&publicationDate); err != nil {
+				return false, err
+			}
+		case "offerCode":
+			if err := decoder.Decode(&serviceCode); err != nil {
+				return false, err
+			}
+		case "products":
+			if err := expectPricingOfferDelim(decoder, '{'); err != nil {
+				return false, err
+			}
+			for decoder.More() {
+				sku, err := decoder.Token()
+				if err != nil {
+					return false, err
+				}
+				var product Product
+				if err := decoder.Decode(&product); err != nil {
+					return false, err
+				}
+				if matchPricingFilters(sku.(string), &product, filters) {
+					products[sku.(string)] = &product
+				}
+			}
+			if _, err := decoder.Token(); err != nil {
+				return false, err
+			}
+		case "terms":
+			if err := expectPricingOfferDelim(decoder, '{'); err != nil {
+				return false, err
+			}
+			for decoder.More() {
+				term, err := decoder.Token()
+				if err != nil {
+					return false, err
+				}
+				if err := expectPricingOfferDelim(decoder, '{'); err != nil {
+					return false, err
+				}
+				for decoder.More() {
+					sku, err := decoder.Token()
+					if err != nil {
+						return false, err
+					}
+					product, ok := products[sku.(string)]
+					if !ok {
+						var skip json.RawMessage
+						if err := decoder.Decode(&skip); err != nil {
+							return false, err
+						}
+						continue
+					}
+
+					var offers map[string]*Offer
+					if err := decoder.Decode(&offers); err != nil {
+						return false, err
+					}
+					for _, offer := range offers {
+						for _, priceDimension := range offer.PriceDimensions {
+							if !stream(PriceOutput{
+								Product:         product,
+								ServiceCode:     serviceCode,
+								Version:         version,
+								PublicationDate: publicationDate,
+								Offer:           &OfferOutput{PriceDimension: priceDimension, Term: term.(string), EffectiveDate: offer.EffectiveDate, OfferTermCode: offer.OfferTermCode, TermAttributes: offer.TermAttributes},
+							}) {
+								return true, nil
+							}
+						}
+					}
+				}
+				if _, err := decoder.Token(); err != nil {
+					return false, err
+				}
+			}
+			if _, err := decoder.Token(); err != nil {
+				return false, err
+			}
+		default:
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func expectPricingOfferDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("unexpected token %v, expected %v", token, delim)
+	}
+	return nil
+}
+
+// Columns of a CSV offer file that describe the offer rather than the product
+var pricingOfferCSVColumns = map[string]bool{
+	"SKU": true, "OfferTermCode": true, "RateCode": true, "TermType": true, "PriceDescription": true,
+	"EffectiveDate": true, "StartingRange": true, "EndingRange": true, "Unit": true, "PricePerUnit": true,
+	"Currency": true, "LeaseContractLength": true, "PurchaseOption": true, "OfferingClass": true, "Product Family": true,
+}
+
+// readPricingOfferCSV reads a CSV offer file, which has one row per price
+// dimension after a short preamble of offer metadata. It returns true once
+// stream returns false.
+func readPricingOfferCSV(body io.Reader, filters []types.Filter, stream func(PriceOutput) bool) (bool, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+
+	var version *string
+	var publicationDate *time.Time
+	var serviceCode *string
+
+	// The preamble is a list of name, value rows ending at the header row
+	var header []string
+	for header == nil {
+		record, err := reader.Read()
+		if err != nil {
+			return false, err
+		}
+		if record[0] == "SKU" {
+			header = append([]string{}, record...)
+			continue
+		}
+		if len(record) < 2 {
+			continue
+		}
+		switch record[0] {
+		case "Version":
+			version = aws.String(record[1])
+		case "Publication Date":
+			if t, err := time.Parse(time.RFC3339, record[1]); err == nil {
+				publicationDate = &t
+			}
+		case "OfferCode":
+			serviceCode = aws.String(record[1])
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		values := map[string]string{}
+		product := &Product{Attributes: map[string]*string{}}
+		for i, value := range record {
+			if i >= len(header) || value == "" {
+				continue
+			}
+			values[header[i]] = value
+			if !pricingOfferCSVColumns[header[i]] {
+				product.Attributes[pricingOfferAttributeName(header[i])] = aws.String(value)
+			}
+		}
+		if values["Product Family"] != "" {
+			product.ProductFamily = aws.String(values["Product Family"])
+		}
+
+		if !matchPricingFilters(values["SKU"], product, filters) {
+			continue
+		}
+
+		offer := &OfferOutput{
+			Term:          values["TermType"],
+			OfferTermCode: pricingOfferOptionalString(values["OfferTermCode"]),
+			PriceDimension: &PriceDimension{
+				Description:  pricingOfferOptionalString(values["PriceDescription"]),
+				Unit:         pricingOfferOptionalString(values["Unit"]),
+				BeginRange:   pricingOfferOptionalString(values["StartingRange"]),
+				EndRange:     pricingOfferOptionalString(values["EndingRange"]),
+				RateCode:     pricingOfferOptionalString(values["RateCode"]),
+				PricePerUnit: map[string]*string{values["Currency"]: aws.String(values["PricePerUnit"])},
+			},
+			TermAttributes: &TermAttributes{
+				PurchaseOption:      pricingOfferOptionalString(values["PurchaseOption"]),
+				LeaseContractLength: pricingOfferOptionalString(values["LeaseContractLength"]),
+				OfferingClass:       pricingOfferOptionalString(values["OfferingClass"]),
+			},
+		}
+		if t, err := time.Parse("2006-01-02", values["EffectiveDate"]); err == nil {
+			offer.EffectiveDate = &t
+		}
+
+		if !stream(PriceOutput{
+			Product:         product,
+			ServiceCode:     serviceCode,
+			Version:         version,
+			PublicationDate: publicationDate,
+			Offer:           offer,
+		}) {
+			return true, nil
+		}
+	}
+}
+
+// pricingOfferAttributeName converts a CSV column to the attribute name used
+// by the Pricing API and JSON offer files. Single word columns are lower
+// cased, e.g. CapacityStatus to capacitystatus and ECU to ecu, and the words
+// of other columns are joined in lower camel case without punctuation, e.g.
+// "EBS Optimized" to ebsOptimized and "Pre Installed S/W" to preInstalledSw.
+func pricingOfferAttributeName(column string) string {
+	var b strings.Builder
+	for i, word := range strings.Fields(column) {
+		word = strings.Map(func(r rune) rune {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return -1
+			}
+			return unicode.ToLower(r)
+		}, word)
+		if i > 0 && word != "" {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			word = string(runes)
+		}
+		b.WriteString(word)
+	}
+	return b.String()
+}
+
+func pricingOfferOptionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
+
+// matchPricingFilters applies filters built by buildPricingFilter to a
+// product. Like the TERM_MATCH filters of the Pricing API, the sku and
+// productFamily fields can be filtered on as well as the attributes.
+func matchPricingFilters(sku string, product *Product, filters []types.Filter) bool {
+	for _, filter := range filters {
+		var value string
+		switch field := aws.ToString(filter.Field); strings.ToLower(field) {
+		case "sku":
+			value = sku
+		case "productfamily":
+			value = aws.ToString(product.ProductFamily)
+		default:
+			value = aws.ToString(pricingProductAttribute(product, field))
+		}
+		if !strings.EqualFold(value, aws.ToString(filter.Value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// pricingProductAttribute returns the attribute of a product with the given
+// name, ignoring case like the Pricing API does.
+func pricingProductAttribute(product *Product, name string) *string {
+	if value, ok := product.Attributes[name]; ok {
+		return value
+	}
+	for attribute, value := range product.Attributes {
+		if strings.EqualFold(attribute, name) {
+			return value
+		}
+	}
+	return nil
+}
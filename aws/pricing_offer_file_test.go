@@ -0,0 +1,177 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+)
+
+func TestPricingOfferAttributeName(t *testing.T) {
+	cases := map[string]string{
+		"Instance Type":                "instanceType",
+		"CapacityStatus":               "capacitystatus",
+		"usageType":                    "usagetype",
+		"EBS Optimized":                "ebsOptimized",
+		"ECU":                          "ecu",
+		"GPU":                          "gpu",
+		"GPU Memory":                   "gpuMemory",
+		"vCPU":                         "vcpu",
+		"Intel AVX Available":          "intelAvxAvailable",
+		"Intel AVX2 Available":         "intelAvx2Available",
+		"Volume API Name":              "volumeApiName",
+		"Max IOPS/volume":              "maxIopsvolume",
+		"Instance Capacity - 10xlarge": "instanceCapacity10xlarge",
+		"Pre Installed S/W":            "preInstalledSw",
+	}
+
+	for column, want := range cases {
+		t.Run(column, func(t *testing.T) {
+			if got := pricingOfferAttributeName(column); got != want {
+				t.Errorf("pricingOfferAttributeName(%q) = %q, want %q", column, got, want)
+			}
+		})
+	}
+}
+
+func TestMatchPricingFilters(t *testing.T) {
+	product := &Product{
+		ProductFamily: aws.String("Compute Instance"),
+		Attributes: map[string]*string{
+			"instanceType":   aws.String("m5.large"),
+			"capacitystatus": aws.String("Used"),
+			"regionCode":     aws.String("us-east-1"),
+		},
+	}
+
+	cases := []struct {
+		name    string
+		filters map[string]string
+		want    bool
+	}{
+		{"no filters", nil, true},
+		{"attribute", map[string]string{"instanceType": "m5.large"}, true},
+		{"attribute value case", map[string]string{"instanceType": "M5.LARGE"}, true},
+		{"attribute name case", map[string]string{"capacityStatus": "Used"}, true},
+		{"sku", map[string]string{"sku": "ABC123"}, true},
+		{"product family", map[string]string{"productFamily": "compute instance"}, true},
+		{"product family name case", map[string]string{"ProductFamily": "Compute Instance"}, true},
+		{"all filters", map[string]string{"instanceType": "m5.large", "regionCode": "us-east-1"}, true},
+		{"mismatched value", map[string]string{"instanceType": "m5.xlarge"}, false},
+		{"one mismatched filter", map[string]string{"instanceType": "m5.large", "regionCode": "eu-west-1"}, false},
+		{"missing attribute", map[string]string{"operatingSystem": "Linux"}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var filters []types.Filter
+			for field, value := range c.filters {
+				filters = append(filters, types.Filter{Field: aws.String(field), Type: types.FilterTypeTermMatch, Value: aws.String(value)})
+			}
+			if got := matchPricingFilters("ABC123", product, filters); got != c.want {
+				t.Errorf("matchPricingFilters(%v) = %v, want %v", c.filters, got, c.want)
+			}
+		})
+	}
+}
+
+const testPricingOfferCSV = `"FormatVersion","v1.0"
+"Disclaimer","This pricing list is for informational purposes only."
+"Publication Date","2024-03-01T12:00:00Z"
+"Version","20240301120000"
+"OfferCode","AmazonEC2"
+"Truncated"
+"SKU","OfferTermCode","RateCode","TermType","PriceDescription","EffectiveDate","StartingRange","EndingRange","Unit","PricePerUnit","Currency","LeaseContractLength","PurchaseOption","OfferingClass","Product Family","serviceCode","Instance Type","CapacityStatus","EBS Optimized","Pre Installed S/W"
+"ABC123","JRTCKXETXF","ABC123.JRTCKXETXF.6YS6EN2CT7","OnDemand","$0.096 per On Demand Linux m5.large Instance Hour","2024-03-01","0","Inf","Hrs","0.0960000000","USD","","","","Compute Instance","AmazonEC2","m5.large","Used","Yes","NA"
+"DEF456","JRTCKXETXF","DEF456.JRTCKXETXF.6YS6EN2CT7","OnDemand","$0.192 per On Demand Linux m5.xlarge Instance Hour","2024-03-01","0","Inf","Hrs","0.1920000000","USD","","","","Compute Instance","AmazonEC2","m5.xlarge","Used","Yes","NA"
+"ABC123","4NA7Y494T4","ABC123.4NA7Y494T4.6YS6EN2CT7","Reserved","Linux/UNIX (Amazon VPC), m5.large reserved instance applied","2024-03-01","","","Hrs","0.0600000000","USD","1yr","No Upfront","standard","Compute Instance","AmazonEC2","m5.large","Used","Yes","NA"
+`
+
+func TestReadPricingOfferCSV(t *testing.T) {
+	filters := []types.Filter{
+		{Field: aws.String("instanceType"), Type: types.FilterTypeTermMatch, Value: aws.String("m5.large")},
+		{Field: aws.String("capacityStatus"), Type: types.FilterTypeTermMatch, Value: aws.String("used")},
+	}
+
+	var items []PriceOutput
+	done, err := readPricingOfferCSV(strings.NewReader(testPricingOfferCSV), filters, func(item PriceOutput) bool {
+		items = append(items, item)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("readPricingOfferCSV() error = %v", err)
+	}
+	if done {
+		t.Errorf("readPricingOfferCSV() done = true, want false")
+	}
+	if len(items) != 2 {
+		t.Fatalf("readPricingOfferCSV() returned %d items, want 2", len(items))
+	}
+
+	item := items[0]
+	if got := aws.ToString(item.ServiceCode); got != "AmazonEC2" {
+		t.Errorf("ServiceCode = %q, want AmazonEC2", got)
+	}
+	if got := aws.ToString(item.Version); got != "20240301120000" {
+		t.Errorf("Version = %q, want 20240301120000", got)
+	}
+	if item.PublicationDate == nil || item.PublicationDate.Format("2006-01-02") != "2024-03-01" {
+		t.Errorf("PublicationDate = %v, want 2024-03-01", item.PublicationDate)
+	}
+	if got := aws.ToString(item.Product.ProductFamily); got != "Compute Instance" {
+		t.Errorf("ProductFamily = %q, want Compute Instance", got)
+	}
+	for name, want := range map[string]string{
+		"servicecode":    "AmazonEC2",
+		"instanceType":   "m5.large",
+		"capacitystatus": "Used",
+		"ebsOptimized":   "Yes",
+		"preInstalledSw": "NA",
+	} {
+		if got := aws.ToString(item.Product.Attributes[name]); got != want {
+			t.Errorf("Attributes[%q] = %q, want %q", name, got, want)
+		}
+	}
+	if _, ok := item.Product.Attributes["sku"]; ok {
+		t.Errorf("Attributes has offer column sku")
+	}
+
+	if item.Offer.Term != "OnDemand" {
+		t.Errorf("Term = %q, want OnDemand", item.Offer.Term)
+	}
+	if got := aws.ToString(item.Offer.PriceDimension.PricePerUnit["USD"]); got != "0.0960000000" {
+		t.Errorf("PricePerUnit[USD] = %q, want 0.0960000000", got)
+	}
+	if got := aws.ToString(item.Offer.PriceDimension.Unit); got != "Hrs" {
+		t.Errorf("Unit = %q, want Hrs", got)
+	}
+	if item.Offer.TermAttributes.LeaseContractLength != nil {
+		t.Errorf("LeaseContractLength = %q, want nil", aws.ToString(item.Offer.TermAttributes.LeaseContractLength))
+	}
+
+	reserved := items[1]
+	if reserved.Offer.Term != "Reserved" {
+		t.Errorf("Term = %q, want Reserved", reserved.Offer.Term)
+	}
+	if got := aws.ToString(reserved.Offer.TermAttributes.LeaseContractLength); got != "1yr" {
+		t.Errorf("LeaseContractLength = %q, want 1yr", got)
+	}
+	if got := aws.ToString(reserved.Offer.TermAttributes.PurchaseOption); got != "No Upfront" {
+		t.Errorf("PurchaseOption = %q, want No Upfront", got)
+	}
+}
+
+func TestReadPricingOfferCSVStops(t *testing.T) {
+	count := 0
+	done, err := readPricingOfferCSV(strings.NewReader(testPricingOfferCSV), nil, func(PriceOutput) bool {
+		count++
+		return false
+	})
+	if err != nil {
+		t.Fatalf("readPricingOfferCSV() error = %v", err)
+	}
+	if !done || count != 1 {
+		t.Errorf("readPricingOfferCSV() done = %v after %d items, want true after 1", done, count)
+	}
+}
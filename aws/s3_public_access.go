@@ -0,0 +1,361 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3PublicAccess is the effective public access to a bucket, with the
+// reasons that access is or is not public.
+type s3PublicAccess struct {
+	PublicRead         bool
+	PublicReadReasons  []string
+	PublicWrite        bool
+	PublicWriteReasons []string
+	PublicList         bool
+	PublicListReasons  []string
+}
+
+// s3PublicAccessSettings are the settings of a bucket and its account that
+// public access is evaluated from.
+type s3PublicAccessSettings struct {
+	BucketName string
+	Grants     []types.Grant
+	Policy     string
+
+	// ACLs are disabled when object ownership is BucketOwnerEnforced
+	AclsDisabled bool
+
+	BucketIgnorePublicAcls       bool
+	BucketRestrictPublicBuckets  bool
+	AccountIgnorePublicAcls      bool
+	AccountRestrictPublicBuckets bool
+}
+
+// The kinds of access that are evaluated, with the bucket ACL permissions and
+// policy actions that grant them. The bucket ACL grants no access to the
+// objects themselves, which is granted by object ACLs instead.
+// aclResource is the resource, of the bucket or its objects, that ACL grants
+// of the kind apply to.
+var s3PublicAccessKinds = []struct {
+	name           string
+	aclPermissions []types.Permission
+	aclResource    string
+	actions        []string
+}{
+	{"read", nil, "arn:aws:s3:::%s/*", []string{"s3:getobject", "s3:getobjectversion"}},
+	{"write", []types.Permission{types.PermissionWrite, types.PermissionFullControl}, "arn:aws:s3:::%s/*", []string{"s3:putobject", "s3:deleteobject", "s3:deleteobjectversion"}},
+	{"list", []types.Permission{types.PermissionRead, types.PermissionFullControl}, "arn:aws:s3:::%s", []string{"s3:listbucket", "s3:listbucketversions"}},
+}
+
+// Condition keys that limit a statement to known accounts, networks or
+// services. S3 does not consider statements with any of them to be public.
+var s3NonPublicConditionKeys = map[string]bool{
+	"aws:principalaccount":      true,
+	"aws:principalarn":          true,
+	"aws:principalorgid":        true,
+	"aws:principalorgpaths":     true,
+	"aws:sourceaccount":         true,
+	"aws:sourcearn":             true,
+	"aws:sourceip":              true,
+	"aws:sourceorgid":           true,
+	"aws:sourceorgpaths":        true,
+	"aws:sourceowner":           true,
+	"aws:sourcevpc":             true,
+	"aws:sourcevpce":            true,
+	"aws:userid":                true,
+	"s3:dataaccesspointarn":     true,
+	"s3:dataaccesspointaccount": true,
+}
+
+const (
+	s3AllUsersGroupUri           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	s3AuthenticatedUsersGroupUri = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+// evaluateS3PublicAccess combines the bucket ACL, the bucket policy and the
+// bucket and account public access blocks into the effective public access
+// to the bucket. An explicit public Deny in the policy overrides the grants of
+// the resources it covers.
+func evaluateS3PublicAccess(settings s3PublicAccessSettings) (*s3PublicAccess, error) {
+	var statements Statements
+	if settings.Policy != "" {
+		var policy Policy
+		if err := json.Unmarshal([]byte(settings.Policy), &policy); err != nil {
+			return nil, fmt.Errorf("failed to parse policy of bucket %s: %v", settings.BucketName, err)
+		}
+		statements = policy.Statements
+	}
+
+	access := &s3PublicAccess{}
+	for _, kind := range s3PublicAccessKinds {
+		aclResource := fmt.Sprintf(kind.aclResource, settings.BucketName)
+		public, reasons := evaluateS3PublicAccessKind(settings, statements, kind.aclPermissions, aclResource, kind.actions)
+		switch kind.name {
+		case "read":
+			access.PublicRead, access.PublicReadReasons = public, reasons
+		case "write":
+			access.PublicWrite, access.PublicWriteReasons = public, reasons
+		case "list":
+			access.PublicList, access.PublicListReasons = public, reasons
+		}
+	}
+	return access, nil
+}
+
+// s3PublicGrant is an ACL grant or policy statement that gives public access
+// to the resources it applies to.
+type s3PublicGrant struct {
+	reason    string
+	resources []string
+}
+
+func evaluateS3PublicAccessKind(settings s3PublicAccessSettings, statements Statements, aclPermissions []types.Permission, aclResource string, actions []string) (bool, []string) {
+	var grants []s3PublicGrant
+	var blocks []string
+
+	// ACL grants to everyone, or to any authenticated AWS user
+	for _, grant := range settings.Grants {
+		if grant.Grantee == nil || grant.Grantee.Type != types.TypeGroup {
+			continue
+		}
+		var group string
+		switch strings.TrimSpace(aclGranteeUri(grant.Grantee)) {
+		case s3AllUsersGroupUri:
+			group = "AllUsers"
+		case s3AuthenticatedUsersGroupUri:
+			group = "AuthenticatedUsers"
+		default:
+			continue
+		}
+		for _, permission := range aclPermissions {
+			if grant.Permission != permission {
+				continue
+			}
+			reason := fmt.Sprintf("ACL grants %s to %s", permission, group)
+			switch {
+			case settings.AclsDisabled:
+				blocks = append(blocks, reason+", but ACLs are disabled by BucketOwnerEnforced object ownership")
+			case settings.AccountIgnorePublicAcls:
+				blocks = append(blocks, reason+", but it is ignored by the account public access block (IgnorePublicAcls)")
+			case settings.BucketIgnorePublicAcls:
+				blocks = append(blocks, reason+", but it is ignored by the bucket public access block (IgnorePublicAcls)")
+			default:
+				grants = append(grants, s3PublicGrant{reason: reason, resources: []string{aclResource}})
+			}
+		}
+	}
+
+	// Policy statements that apply to everyone, without limiting conditions
+	var denies []Statement
+	var denyReasons []string
+	for i, statement := range statements {
+		if !s3StatementIsPublic(statement) {
+			continue
+		}
+		action, ok := s3StatementMatchesActions(statement, actions)
+		if !ok {
+			continue
+		}
+		if statement.Effect == "Deny" {
+			// Conditional denies, e.g. of requests without TLS, leave other
+			// requests allowed
+			if len(statement.Condition) == 0 {
+				denies = append(denies, statement)
+				denyReasons = append(denyReasons, fmt.Sprintf("Policy statement %s denies %s to everyone", s3StatementName(statement, i), action))
+			}
+			continue
+		}
+		reason := fmt.Sprintf("Policy statement %s allows %s to everyone", s3StatementName(statement, i), action)
+		switch {
+		case settings.AccountRestrictPublicBuckets:
+			blocks = append(blocks, reason+", but it is restricted by the account public access block (RestrictPublicBuckets)")
+		case settings.BucketRestrictPublicBuckets:
+			blocks = append(blocks, reason+", but it is restricted by the bucket public access block (RestrictPublicBuckets)")
+		default:
+			// An Allow with NotResource applies to resources that can't be
+			// listed, so only a Deny of all resources covers it
+			resources := []string(statement.Resource)
+			if len(statement.NotResource) > 0 {
+				resources = []string{"*"}
+			}
+			grants = append(grants, s3PublicGrant{reason: reason, resources: resources})
+		}
+	}
+
+	// A Deny only overrides the grants of resources that it covers. Denies of
+	// other resources leave the grants in place, and are kept as reasons.
+	var public []string
+	for _, grant := range grants {
+		overridden := false
+		for i, deny := range denies {
+			if s3StatementCoversResources(deny, grant.resources) {
+				blocks = append(blocks, fmt.Sprintf("%s, but %s, which overrides it", grant.reason, lowerFirst(denyReasons[i])))
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			public = append(public, grant.reason)
+		}
+	}
+	if len(public) > 0 {
+		for _, deny := range denyReasons {
+			public = append(public, deny+", which does not cover all the resources of the grants")
+		}
+		return true, public
+	}
+	return false, blocks
+}
+
+// s3StatementCoversResources returns true if a statement applies to all of
+// the resources, which may be wildcard patterns themselves.
+func s3StatementCoversResources(statement Statement, resources []string) bool {
+	for _, resource := range resources {
+		if len(statement.NotResource) > 0 {
+			// The resource is covered if none of it can be excluded
+			for _, excluded := range statement.NotResource {
+				if s3ResourceMatches(excluded, resource) || s3ResourceMatches(resource, excluded) {
+					return false
+				}
+			}
+			continue
+		}
+
+		covered := false
+		for _, pattern := range statement.Resource {
+			if s3ResourceMatches(pattern, resource) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// s3ResourceMatches returns true if a resource matches a policy resource
+// pattern, in which * matches any characters, including /, and ? matches any
+// single character.
+func s3ResourceMatches(pattern string, resource string) bool {
+	expression := regexp.QuoteMeta(pattern)
+	expression = strings.ReplaceAll(expression, `\*`, ".*")
+	expression = strings.ReplaceAll(expression, `\?`, ".")
+	matched, err := regexp.MatchString("^"+expression+"$", resource)
+	return err == nil && matched
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func aclGranteeUri(grantee *types.Grantee) string {
+	if grantee.URI == nil {
+		return ""
+	}
+	return *grantee.URI
+}
+
+// s3StatementIsPublic returns true if a statement applies to any principal,
+// and is not limited by a condition to known accounts, networks or services.
+func s3StatementIsPublic(statement Statement) bool {
+	if len(statement.NotPrincipal) > 0 {
+		// NotPrincipal with Allow applies to everyone else
+		return statement.Effect == "Allow"
+	}
+
+	public := false
+	for principalType, values := range statement.Principal {
+		if principalType != "AWS" && principalType != "*" {
+			continue
+		}
+		for _, value := range principalValues(values) {
+			if value == "*" {
+				public = true
+			}
+		}
+	}
+	if !public {
+		return false
+	}
+
+	for _, condition := range statement.Condition {
+		keys, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, values := range keys {
+			if !s3NonPublicConditionKeys[key] {
+				continue
+			}
+			// A wildcard value matches everyone, so doesn't limit the statement
+			for _, value := range principalValues(values) {
+				if !strings.Contains(value, "*") {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// principalValues returns the values of a canonical principal or condition,
+// which are []string once unmarshalled
+func principalValues(values interface{}) []string {
+	switch typed := values.(type) {
+	case []string:
+		return typed
+	case []interface{}:
+		var strs []string
+		for _, v := range typed {
+			strs = append(strs, fmt.Sprint(v))
+		}
+		return strs
+	case string:
+		return []string{typed}
+	}
+	return nil
+}
+
+// s3StatementMatchesActions returns the first of the actions that a
+// statement applies to. Actions in canonical policies are lower case.
+func s3StatementMatchesActions(statement Statement, actions []string) (string, bool) {
+	for _, action := range actions {
+		if len(statement.NotAction) > 0 {
+			if !actionMatchesAny(action, statement.NotAction) {
+				return action, true
+			}
+			continue
+		}
+		if actionMatchesAny(action, statement.Action) {
+			return action, true
+		}
+	}
+	return "", false
+}
+
+func actionMatchesAny(action string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, action); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func s3StatementName(statement Statement, index int) string {
+	if statement.Sid != "" {
+		return fmt.Sprintf("%q", statement.Sid)
+	}
+	return fmt.Sprintf("#%d", index+1)
+}
@@ -0,0 +1,159 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestEvaluateS3PublicAccess(t *testing.T) {
+	allUsers := func(permission types.Permission) types.Grant {
+		return types.Grant{
+			Grantee:    &types.Grantee{Type: types.TypeGroup, URI: aws.String(s3AllUsersGroupUri)},
+			Permission: permission,
+		}
+	}
+
+	cases := []struct {
+		name     string
+		settings s3PublicAccessSettings
+		read     bool
+		write    bool
+		list     bool
+		// A substring of one of the reasons for the read access, if set
+		readReason string
+		// A substring of one of the reasons for the list access, if set
+		listReason string
+	}{
+		{
+			name:     "private bucket",
+			settings: s3PublicAccessSettings{},
+		},
+		{
+			name:       "ACL grants list and write to everyone",
+			settings:   s3PublicAccessSettings{Grants: []types.Grant{allUsers(types.PermissionFullControl)}},
+			write:      true,
+			list:       true,
+			listReason: "ACL grants FULL_CONTROL to AllUsers",
+		},
+		{
+			name: "ACL grant ignored by the account public access block",
+			settings: s3PublicAccessSettings{
+				Grants:                  []types.Grant{allUsers(types.PermissionRead)},
+				AccountIgnorePublicAcls: true,
+			},
+			listReason: "ignored by the account public access block",
+		},
+		{
+			name: "ACL grant with ACLs disabled",
+			settings: s3PublicAccessSettings{
+				Grants:       []types.Grant{allUsers(types.PermissionRead)},
+				AclsDisabled: true,
+			},
+			listReason: "ACLs are disabled",
+		},
+		{
+			name: "policy allows read to everyone",
+			settings: s3PublicAccessSettings{
+				Policy: `{"Statement":[{"Sid":"PublicRead","Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"}]}`,
+			},
+			read:       true,
+			readReason: `Policy statement "PublicRead" allows s3:getobject to everyone`,
+		},
+		{
+			name: "public policy restricted by the bucket public access block",
+			settings: s3PublicAccessSettings{
+				Policy:                      `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:*","Resource":"arn:aws:s3:::b/*"}]}`,
+				BucketRestrictPublicBuckets: true,
+			},
+			readReason: "restricted by the bucket public access block",
+		},
+		{
+			name: "condition on source IP is not public",
+			settings: s3PublicAccessSettings{
+				Policy: `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*","Condition":{"IpAddress":{"aws:SourceIp":"203.0.113.0/24"}}}]}`,
+			},
+		},
+		{
+			name: "deny of the granted resources overrides the allow",
+			settings: s3PublicAccessSettings{
+				Policy: `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"},{"Effect":"Deny","Principal":"*","Action":"s3:*","Resource":["arn:aws:s3:::b","arn:aws:s3:::b/*"]}]}`,
+			},
+			readReason: "which overrides it",
+		},
+		{
+			name: "deny of other resources keeps the allow",
+			settings: s3PublicAccessSettings{
+				Policy: `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"},{"Effect":"Deny","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/private/*"}]}`,
+			},
+			read:       true,
+			readReason: "which does not cover all the resources of the grants",
+		},
+		{
+			name: "deny with NotResource excluding the granted resources keeps the allow",
+			settings: s3PublicAccessSettings{
+				Policy: `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/public/*"},{"Effect":"Deny","Principal":"*","Action":"s3:GetObject","NotResource":"arn:aws:s3:::b/public/*"}]}`,
+			},
+			read: true,
+		},
+		{
+			name: "deny with NotResource of other resources overrides the allow",
+			settings: s3PublicAccessSettings{
+				Policy: `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/public/*"},{"Effect":"Deny","Principal":"*","Action":"s3:GetObject","NotResource":"arn:aws:s3:::b/other/*"}]}`,
+			},
+			readReason: "which overrides it",
+		},
+		{
+			name: "conditional deny does not override the allow",
+			settings: s3PublicAccessSettings{
+				Policy: `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"},{"Effect":"Deny","Principal":"*","Action":"s3:*","Resource":"arn:aws:s3:::b/*","Condition":{"Bool":{"aws:SecureTransport":"false"}}}]}`,
+			},
+			read: true,
+		},
+		{
+			name: "deny of objects does not override an ACL grant of the bucket listing",
+			settings: s3PublicAccessSettings{
+				Grants: []types.Grant{allUsers(types.PermissionRead)},
+				Policy: `{"Statement":[{"Effect":"Deny","Principal":"*","Action":"s3:*","Resource":"arn:aws:s3:::b/*"}]}`,
+			},
+			list:       true,
+			listReason: "ACL grants READ to AllUsers",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.settings.BucketName = "b"
+			access, err := evaluateS3PublicAccess(tc.settings)
+			if err != nil {
+				t.Fatalf("evaluateS3PublicAccess() error = %v", err)
+			}
+			if access.PublicRead != tc.read || access.PublicWrite != tc.write || access.PublicList != tc.list {
+				t.Errorf("evaluateS3PublicAccess() read, write, list = %v, %v, %v, want %v, %v, %v", access.PublicRead, access.PublicWrite, access.PublicList, tc.read, tc.write, tc.list)
+			}
+			if tc.readReason != "" && !containsReason(access.PublicReadReasons, tc.readReason) {
+				t.Errorf("evaluateS3PublicAccess() read reasons = %q, want one containing %q", access.PublicReadReasons, tc.readReason)
+			}
+			if tc.listReason != "" && !containsReason(access.PublicListReasons, tc.listReason) {
+				t.Errorf("evaluateS3PublicAccess() list reasons = %q, want one containing %q", access.PublicListReasons, tc.listReason)
+			}
+		})
+	}
+}
+
+func TestEvaluateS3PublicAccessInvalidPolicy(t *testing.T) {
+	if _, err := evaluateS3PublicAccess(s3PublicAccessSettings{BucketName: "b", Policy: "{"}); err == nil {
+		t.Error("evaluateS3PublicAccess() error = nil, want an error for an invalid policy")
+	}
+}
+
+func containsReason(reasons []string, substring string) bool {
+	for _, reason := range reasons {
+		if strings.Contains(reason, substring) {
+			return true
+		}
+	}
+	return false
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/auditmanager"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/backup"
+	"github.com/aws/aws-sdk-go-v2/service/budgets"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
@@ -365,6 +366,16 @@ func BackupClient(ctx context.Context, d *plugin.QueryData) (*backup.Client, err
 	return backup.NewFromConfig(*cfg), nil
 }
 
+func BudgetsClient(ctx context.Context, d *plugin.QueryData) (*budgets.Client, error) {
+	// AWS Budgets is a global service that operates from a single region
+	// (budgets.amazonaws.com), so use the default region like Cost Explorer.
+	cfg, err := getClientForDefaultRegion(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	return budgets.NewFromConfig(*cfg), nil
+}
+
 func CloudControlClient(ctx context.Context, d *plugin.QueryData) (*cloudcontrol.Client, error) {
 	// CloudControl returns GeneralServiceException in a lot of situations, which
 	// AWS SDK treats as retryable. This is frustrating because we end up retrying
@@ -540,7 +551,7 @@ func CostExplorerClient(ctx context.Context, d *plugin.QueryData) (*costexplorer
 	if err != nil {
 		return nil, err
 	}
-	return costexplorer.NewFromConfig(*cfg), nil
+	return costexplorer.NewFromConfig(*cfg, costExplorerRequestBudget(d)), nil
 }
 
 func DatabaseMigrationClient(ctx context.Context, d *plugin.QueryData) (*databasemigrationservice.Client, error) {
@@ -0,0 +1,242 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/budgets"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsBudgetsBudget(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_budgets_budget",
+		Description: "AWS Budgets Budget",
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("budget_name"),
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"NotFoundException"}),
+			},
+			Hydrate: getBudgetsBudget,
+			Tags:    map[string]string{"service": "budgets", "action": "ViewBudget"},
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listBudgetsBudgets,
+			Tags:    map[string]string{"service": "budgets", "action": "ViewBudget"},
+			// An account with no budgets returns NotFoundException
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"NotFoundException"}),
+			},
+		},
+		Columns: awsGlobalRegionColumns([]*plugin.Column{
+			{
+				Name:        "budget_name",
+				Description: "The name of the budget.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "budget_type",
+				Description: "Whether this budget tracks costs, usage, RI utilization, RI coverage, Savings Plans utilization, or Savings Plans coverage.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "time_unit",
+				Description: "The length of time until a budget resets the actual and forecasted spend. Possible values are: DAILY|MONTHLY|QUARTERLY|ANNUALLY.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "limit_amount",
+				Description: "The total amount of cost, usage, RI utilization, RI coverage, Savings Plans utilization, or Savings Plans coverage that you want to track with your budget.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("BudgetLimit.Amount"),
+			},
+			{
+				Name:        "limit_unit",
+				Description: "The unit of measurement that's used for the budget limit, such as dollars or GB.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("BudgetLimit.Unit"),
+			},
+			{
+				Name:        "actual_spend_amount",
+				Description: "The amount of cost, usage, RI units, or Savings Plans units that you used in the current period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("CalculatedSpend.ActualSpend.Amount"),
+			},
+			{
+				Name:        "actual_spend_unit",
+				Description: "The unit of measurement of the actual spend.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("CalculatedSpend.ActualSpend.Unit"),
+			},
+			{
+				Name:        "forecasted_spend_amount",
+				Description: "The amount of cost, usage, RI units, or Savings Plans units that you're forecasted to use in the current period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("CalculatedSpend.ForecastedSpend.Amount"),
+			},
+			{
+				Name:        "forecasted_spend_unit",
+				Description: "The unit of measurement of the forecasted spend.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("CalculatedSpend.ForecastedSpend.Unit"),
+			},
+			{
+				Name:        "time_period_start",
+				Description: "The start date for the budget.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("TimePeriod.Start"),
+			},
+			{
+				Name:        "time_period_end",
+				Description: "The end date for the budget. If no end date was set, the budget runs indefinitely.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("TimePeriod.End"),
+			},
+			{
+				Name:        "last_updated_time",
+				Description: "The last time that you updated this budget.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "cost_filters",
+				Description: "The cost filters, such as Region, Service, LinkedAccount, Tag, or CostCategory, that are applied to a budget.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "cost_types",
+				Description: "The types of costs, such as refunds, credits and taxes, that are included in this COST budget.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "auto_adjust_data",
+				Description: "The parameters that determine the budget amount for an auto-adjusting budget.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "planned_budget_limits",
+				Description: "A map containing multiple budget limits, keyed by the start time of each period.",
+				Type:        proto.ColumnType_JSON,
+			},
+
+			// Steampipe standard columns
+			{
+				Name:        "title",
+				Description: resourceInterfaceDescription("title"),
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("BudgetName"),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listBudgetsBudgets(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// Create session
+	svc, err := BudgetsClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_budgets_budget.listBudgetsBudgets", "client_error", err)
+		return nil, err
+	}
+
+	accountID, err := getBudgetsAccountId(ctx, d, h)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reduce the basic request limit down if the user has only requested a small number of rows
+	maxLimit := int32(100)
+	if d.QueryContext.Limit != nil {
+		limit := int32(*d.QueryContext.Limit)
+		if limit < maxLimit {
+			if limit < 1 {
+				maxLimit = 1
+			} else {
+				maxLimit = limit
+			}
+		}
+	}
+
+	input := &budgets.DescribeBudgetsInput{
+		AccountId:  aws.String(accountID),
+		MaxResults: aws.Int32(maxLimit),
+	}
+
+	paginator := budgets.NewDescribeBudgetsPaginator(svc, input, func(o *budgets.DescribeBudgetsPaginatorOptions) {
+		o.Limit = maxLimit
+		o.StopOnDuplicateToken = true
+	})
+
+	// List call
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_budgets_budget.listBudgetsBudgets", "api_error", err)
+			return nil, err
+		}
+
+		for _, budget := range output.Budgets {
+			d.StreamListItem(ctx, budget)
+
+			// Context may get cancelled due to manual cancellation or if the limit has been reached
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getBudgetsBudget(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	budgetName := d.EqualsQualString("budget_name")
+
+	// Empty check
+	if budgetName == "" {
+		return nil, nil
+	}
+
+	// Create session
+	svc, err := BudgetsClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_budgets_budget.getBudgetsBudget", "client_error", err)
+		return nil, err
+	}
+
+	accountID, err := getBudgetsAccountId(ctx, d, h)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &budgets.DescribeBudgetInput{
+		AccountId:  aws.String(accountID),
+		BudgetName: aws.String(budgetName),
+	}
+
+	op, err := svc.DescribeBudget(ctx, params)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_budgets_budget.getBudgetsBudget", "api_error", err)
+		return nil, err
+	}
+
+	return op.Budget, nil
+}
+
+// Budgets API calls take the ID of the account that owns the budgets
+func getBudgetsAccountId(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (string, error) {
+	commonData, err := getCommonColumns(ctx, d, h)
+	if err != nil {
+		return "", err
+	}
+	return commonData.(*awsCommonColumnData).AccountId, nil
+}
@@ -0,0 +1,181 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/budgets"
+	"github.com/aws/aws-sdk-go-v2/service/budgets/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsBudgetsBudgetAction(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_budgets_budget_action",
+		Description: "AWS Budgets Budget Action",
+		List: &plugin.ListConfig{
+			Hydrate: listBudgetsBudgetActions,
+			Tags:    map[string]string{"service": "budgets", "action": "DescribeBudgetActionsForAccount"},
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "budget_name", Require: plugin.Optional},
+			},
+			// An account or budget with no actions returns NotFoundException
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"NotFoundException"}),
+			},
+		},
+		Columns: awsGlobalRegionColumns([]*plugin.Column{
+			{
+				Name:        "action_id",
+				Description: "A system-generated universally unique identifier (UUID) for the action.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "budget_name",
+				Description: "The name of the budget that the action is for.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "action_type",
+				Description: "The type of action. Possible values are: APPLY_IAM_POLICY|APPLY_SCP_POLICY|RUN_SSM_DOCUMENTS.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "status",
+				Description: "The status of the action.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "approval_model",
+				Description: "Whether the action runs automatically (AUTOMATIC) or requires approval (MANUAL).",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "notification_type",
+				Description: "Whether the action is triggered by how much you have spent (ACTUAL) or for how much that you're forecasted to spend (FORECASTED).",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "action_threshold_type",
+				Description: "The type of threshold for the action. Possible values are: PERCENTAGE|ABSOLUTE_VALUE.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("ActionThreshold.ActionThresholdType"),
+			},
+			{
+				Name:        "action_threshold_value",
+				Description: "The threshold at which the action is triggered.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("ActionThreshold.ActionThresholdValue"),
+			},
+			{
+				Name:        "execution_role_arn",
+				Description: "The role passed for action execution and reversion.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "definition",
+				Description: "The IAM policy, SCP or SSM document that the action applies, and its targets.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "subscribers",
+				Description: "The subscribers that are notified when the action runs.",
+				Type:        proto.ColumnType_JSON,
+			},
+
+			// Steampipe standard columns
+			{
+				Name:        "title",
+				Description: resourceInterfaceDescription("title"),
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("ActionId"),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listBudgetsBudgetActions(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// Create session
+	svc, err := BudgetsClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_budgets_budget_action.listBudgetsBudgetActions", "client_error", err)
+		return nil, err
+	}
+
+	accountID, err := getBudgetsAccountId(ctx, d, h)
+	if err != nil {
+		return nil, err
+	}
+
+	budgetName := d.EqualsQualString("budget_name")
+
+	handlePage := func(actions []types.Action) bool {
+		for _, action := range actions {
+			d.StreamListItem(ctx, action)
+
+			// Context may get cancelled due to manual cancellation or if the limit has been reached
+			if d.RowsRemaining(ctx) == 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Limit the request to a single budget if budget_name is set
+	if budgetName != "" {
+		input := &budgets.DescribeBudgetActionsForBudgetInput{
+			AccountId:  aws.String(accountID),
+			BudgetName: aws.String(budgetName),
+			MaxResults: aws.Int32(100),
+		}
+		paginator := budgets.NewDescribeBudgetActionsForBudgetPaginator(svc, input, func(o *budgets.DescribeBudgetActionsForBudgetPaginatorOptions) {
+			o.Limit = 100
+			o.StopOnDuplicateToken = true
+		})
+		for paginator.HasMorePages() {
+			// apply rate limiting
+			d.WaitForListRateLimit(ctx)
+
+			output, err := paginator.NextPage(ctx)
+			if err != nil {
+				plugin.Logger(ctx).Error("aws_budgets_budget_action.listBudgetsBudgetActions", "api_error", err)
+				return nil, err
+			}
+			if !handlePage(output.Actions) {
+				return nil, nil
+			}
+		}
+		return nil, nil
+	}
+
+	input := &budgets.DescribeBudgetActionsForAccountInput{
+		AccountId:  aws.String(accountID),
+		MaxResults: aws.Int32(100),
+	}
+	paginator := budgets.NewDescribeBudgetActionsForAccountPaginator(svc, input, func(o *budgets.DescribeBudgetActionsForAccountPaginatorOptions) {
+		o.Limit = 100
+		o.StopOnDuplicateToken = true
+	})
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_budgets_budget_action.listBudgetsBudgetActions", "api_error", err)
+			return nil, err
+		}
+		if !handlePage(output.Actions) {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,202 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/budgets"
+	"github.com/aws/aws-sdk-go-v2/service/budgets/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsBudgetsBudgetNotification(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_budgets_budget_notification",
+		Description: "AWS Budgets Budget Notification",
+		List: &plugin.ListConfig{
+			ParentHydrate: listBudgetsBudgets,
+			Hydrate:       listBudgetsBudgetNotifications,
+			Tags:          map[string]string{"service": "budgets", "action": "ViewBudget"},
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "budget_name", Require: plugin.Optional},
+			},
+			// An account with no budgets, or a budget with no notifications,
+			// returns NotFoundException
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"NotFoundException"}),
+			},
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func: getBudgetsBudgetNotificationSubscribers,
+				Tags: map[string]string{"service": "budgets", "action": "ViewBudget"},
+				IgnoreConfig: &plugin.IgnoreConfig{
+					ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"NotFoundException"}),
+				},
+			},
+		},
+		Columns: awsGlobalRegionColumns([]*plugin.Column{
+			{
+				Name:        "budget_name",
+				Description: "The name of the budget that the notification is for.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "notification_type",
+				Description: "Specifies whether the notification is for how much you have spent (ACTUAL) or for how much that you're forecasted to spend (FORECASTED).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Notification.NotificationType"),
+			},
+			{
+				Name:        "comparison_operator",
+				Description: "The comparison that's used for this notification. Possible values are: GREATER_THAN|LESS_THAN|EQUAL_TO.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Notification.ComparisonOperator"),
+			},
+			{
+				Name:        "threshold",
+				Description: "The threshold that's associated with the notification.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Notification.Threshold"),
+			},
+			{
+				Name:        "threshold_type",
+				Description: "The type of threshold for the notification. Possible values are: PERCENTAGE|ABSOLUTE_VALUE.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Notification.ThresholdType"),
+			},
+			{
+				Name:        "notification_state",
+				Description: "Whether the budget has exceeded the threshold (ALARM) or not (OK).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Notification.NotificationState"),
+			},
+			{
+				Name:        "subscribers",
+				Description: "The subscribers that are sent the notification, with their subscription type (EMAIL or SNS) and address.",
+				Type:        proto.ColumnType_JSON,
+				Hydrate:     getBudgetsBudgetNotificationSubscribers,
+				Transform:   transform.FromValue(),
+			},
+
+			// Steampipe standard columns
+			{
+				Name:        "title",
+				Description: resourceInterfaceDescription("title"),
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("BudgetName"),
+			},
+		}),
+	}
+}
+
+type budgetsNotificationInfo struct {
+	BudgetName   *string
+	Notification types.Notification
+}
+
+//// LIST FUNCTION
+
+func listBudgetsBudgetNotifications(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	budget := h.Item.(types.Budget)
+
+	if d.EqualsQualString("budget_name") != "" && d.EqualsQualString("budget_name") != aws.ToString(budget.BudgetName) {
+		return nil, nil
+	}
+
+	// Create session
+	svc, err := BudgetsClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_budgets_budget_notification.listBudgetsBudgetNotifications", "client_error", err)
+		return nil, err
+	}
+
+	accountID, err := getBudgetsAccountId(ctx, d, h)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &budgets.DescribeNotificationsForBudgetInput{
+		AccountId:  aws.String(accountID),
+		BudgetName: budget.BudgetName,
+		MaxResults: aws.Int32(100),
+	}
+
+	paginator := budgets.NewDescribeNotificationsForBudgetPaginator(svc, input, func(o *budgets.DescribeNotificationsForBudgetPaginatorOptions) {
+		o.Limit = 100
+		o.StopOnDuplicateToken = true
+	})
+
+	// List call
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_budgets_budget_notification.listBudgetsBudgetNotifications", "api_error", err)
+			return nil, err
+		}
+
+		for _, notification := range output.Notifications {
+			d.StreamListItem(ctx, &budgetsNotificationInfo{
+				BudgetName:   budget.BudgetName,
+				Notification: notification,
+			})
+
+			// Context may get cancelled due to manual cancellation or if the limit has been reached
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getBudgetsBudgetNotificationSubscribers(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	info := h.Item.(*budgetsNotificationInfo)
+
+	// Create session
+	svc, err := BudgetsClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_budgets_budget_notification.getBudgetsBudgetNotificationSubscribers", "client_error", err)
+		return nil, err
+	}
+
+	accountID, err := getBudgetsAccountId(ctx, d, h)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &budgets.DescribeSubscribersForNotificationInput{
+		AccountId:    aws.String(accountID),
+		BudgetName:   info.BudgetName,
+		Notification: &info.Notification,
+		MaxResults:   aws.Int32(100),
+	}
+
+	paginator := budgets.NewDescribeSubscribersForNotificationPaginator(svc, input, func(o *budgets.DescribeSubscribersForNotificationPaginatorOptions) {
+		o.Limit = 100
+		o.StopOnDuplicateToken = true
+	})
+
+	var subscribers []types.Subscriber
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_budgets_budget_notification.getBudgetsBudgetNotificationSubscribers", "api_error", err)
+			return nil, err
+		}
+		subscribers = append(subscribers, output.Subscribers...)
+	}
+
+	return subscribers, nil
+}
@@ -0,0 +1,168 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/budgets"
+	"github.com/aws/aws-sdk-go-v2/service/budgets/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsBudgetsBudgetPerformanceHistory(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_budgets_budget_performance_history",
+		Description: "AWS Budgets Budget Performance History",
+		List: &plugin.ListConfig{
+			Hydrate: listBudgetsBudgetPerformanceHistory,
+			Tags:    map[string]string{"service": "budgets", "action": "ViewBudget"},
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "budget_name", Require: plugin.Required},
+			},
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"NotFoundException"}),
+			},
+		},
+		Columns: awsGlobalRegionColumns([]*plugin.Column{
+			{
+				Name:        "budget_name",
+				Description: "The name of the budget.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("History.BudgetName"),
+			},
+			{
+				Name:        "budget_type",
+				Description: "The type of the budget.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("History.BudgetType"),
+			},
+			{
+				Name:        "time_unit",
+				Description: "The time unit of the budget, such as MONTHLY or QUARTERLY.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("History.TimeUnit"),
+			},
+			{
+				Name:        "period_start",
+				Description: "The start of the period that the amounts are for.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Amounts.TimePeriod.Start"),
+			},
+			{
+				Name:        "period_end",
+				Description: "The end of the period that the amounts are for.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Amounts.TimePeriod.End"),
+			},
+			{
+				Name:        "budgeted_amount",
+				Description: "The amount of cost or usage that you created the budget for in the period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Amounts.BudgetedAmount.Amount"),
+			},
+			{
+				Name:        "budgeted_unit",
+				Description: "The unit of measurement of the budgeted amount.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Amounts.BudgetedAmount.Unit"),
+			},
+			{
+				Name:        "actual_amount",
+				Description: "Your actual budget usage amount in the period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Amounts.ActualAmount.Amount"),
+			},
+			{
+				Name:        "actual_unit",
+				Description: "The unit of measurement of the actual amount.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Amounts.ActualAmount.Unit"),
+			},
+			{
+				Name:        "cost_filters",
+				Description: "The history of the cost filters for the budget during the specified time period.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("History.CostFilters"),
+			},
+			{
+				Name:        "cost_types",
+				Description: "The history of the cost types for the budget during the specified time period.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("History.CostTypes"),
+			},
+		}),
+	}
+}
+
+type budgetsPerformanceHistoryRow struct {
+	History *types.BudgetPerformanceHistory
+	Amounts types.BudgetedAndActualAmounts
+}
+
+//// LIST FUNCTION
+
+func listBudgetsBudgetPerformanceHistory(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	budgetName := d.EqualsQualString("budget_name")
+
+	// Empty check
+	if budgetName == "" {
+		return nil, nil
+	}
+
+	// Create session
+	svc, err := BudgetsClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_budgets_budget_performance_history.listBudgetsBudgetPerformanceHistory", "client_error", err)
+		return nil, err
+	}
+
+	accountID, err := getBudgetsAccountId(ctx, d, h)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &budgets.DescribeBudgetPerformanceHistoryInput{
+		AccountId:  aws.String(accountID),
+		BudgetName: aws.String(budgetName),
+		MaxResults: aws.Int32(100),
+	}
+
+	paginator := budgets.NewDescribeBudgetPerformanceHistoryPaginator(svc, input, func(o *budgets.DescribeBudgetPerformanceHistoryPaginatorOptions) {
+		o.Limit = 100
+		o.StopOnDuplicateToken = true
+	})
+
+	// List call
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_budgets_budget_performance_history.listBudgetsBudgetPerformanceHistory", "api_error", err)
+			return nil, err
+		}
+		if output.BudgetPerformanceHistory == nil {
+			continue
+		}
+
+		for _, amounts := range output.BudgetPerformanceHistory.BudgetedAndActualAmountsList {
+			d.StreamListItem(ctx, budgetsPerformanceHistoryRow{
+				History: output.BudgetPerformanceHistory,
+				Amounts: amounts,
+			})
+
+			// Context may get cancelled due to manual cancellation or if the limit has been reached
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,104 @@
+package aws
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsCloudFrontAccessLog(_ context.Context) *plugin.Table {
+	return accessLogTable(accessLogDefinition{
+		Name:        "aws_cloudfront_access_log",
+		Description: "AWS CloudFront Standard Access Log",
+		Parse:       parseCloudFrontAccessLog,
+		FilterFields: map[string]string{
+			"edge_location":    "",
+			"client_ip":        "",
+			"status":           "",
+			"method":           "",
+			"edge_result_type": "",
+		},
+		Columns: []*plugin.Column{
+			{Name: "edge_location", Type: proto.ColumnType_STRING, Description: "The edge location that served the request, identified by a three-letter code and an assigned number, e.g. DFW3-C1."},
+			{Name: "sc_bytes", Type: proto.ColumnType_INT, Description: "The total number of bytes that the server sent to the viewer in response to the request, including headers."},
+			{Name: "client_ip", Type: proto.ColumnType_IPADDR, Description: "The IP address of the viewer that made the request."},
+			{Name: "method", Type: proto.ColumnType_STRING, Description: "The HTTP request method received from the viewer."},
+			{Name: "host", Type: proto.ColumnType_STRING, Description: "The domain name of the CloudFront distribution, e.g. d111111abcdef8.cloudfront.net."},
+			{Name: "uri_stem", Type: proto.ColumnType_STRING, Description: "The portion of the request URL that identifies the path and object."},
+			{Name: "status", Type: proto.ColumnType_INT, Description: "The HTTP status code of the server's response, or 0 if the viewer closed the connection before the server responded."},
+			{Name: "referer", Type: proto.ColumnType_STRING, Description: "The value of the Referer header in the request."},
+			{Name: "user_agent", Type: proto.ColumnType_STRING, Description: "The value of the User-Agent header in the request."},
+			{Name: "uri_query", Type: proto.ColumnType_STRING, Description: "The query string portion of the request URL, if any."},
+			{Name: "cookie", Type: proto.ColumnType_STRING, Description: "The Cookie header in the request, if cookie logging is enabled."},
+			{Name: "edge_result_type", Type: proto.ColumnType_STRING, Description: "How the server classified the response after the last byte left the server, e.g. Hit, RefreshHit, Miss or Error."},
+			{Name: "edge_request_id", Type: proto.ColumnType_STRING, Description: "An opaque string that uniquely identifies the request."},
+			{Name: "host_header", Type: proto.ColumnType_STRING, Description: "The value that the viewer included in the Host header of the request."},
+			{Name: "protocol", Type: proto.ColumnType_STRING, Description: "The protocol of the viewer request. Possible values are: http|https|ws|wss."},
+			{Name: "cs_bytes", Type: proto.ColumnType_INT, Description: "The total number of bytes of data that the viewer included in the request, including headers."},
+			{Name: "time_taken", Type: proto.ColumnType_DOUBLE, Description: "The number of seconds between the time that the server received the request and the time it wrote the last byte of the response."},
+			{Name: "forwarded_for", Type: proto.ColumnType_STRING, Description: "The value of the X-Forwarded-For header, if the viewer used an HTTP proxy or a load balancer."},
+			{Name: "ssl_protocol", Type: proto.ColumnType_STRING, Description: "The SSL/TLS protocol that the viewer and server negotiated for HTTPS requests."},
+			{Name: "ssl_cipher", Type: proto.ColumnType_STRING, Description: "The SSL/TLS cipher that the viewer and server negotiated for HTTPS requests."},
+			{Name: "edge_response_result_type", Type: proto.ColumnType_STRING, Description: "How the server classified the response just before returning it to the viewer."},
+			{Name: "protocol_version", Type: proto.ColumnType_STRING, Description: "The HTTP version that the viewer specified in the request."},
+			{Name: "fle_status", Type: proto.ColumnType_STRING, Description: "The status of field-level encryption, if it is configured for the distribution."},
+			{Name: "fle_encrypted_fields", Type: proto.ColumnType_INT, Description: "The number of field-level encryption fields that the server encrypted and forwarded to the origin."},
+			{Name: "client_port", Type: proto.ColumnType_INT, Description: "The port number of the request from the viewer."},
+			{Name: "time_to_first_byte", Type: proto.ColumnType_DOUBLE, Description: "The number of seconds between receiving the request and writing the first byte of the response, as measured on the server."},
+			{Name: "edge_detailed_result_type", Type: proto.ColumnType_STRING, Description: "The same value as edge_result_type, or a more detailed result type, e.g. OriginShieldHit, for some responses."},
+			{Name: "content_type", Type: proto.ColumnType_STRING, Description: "The value of the HTTP Content-Type header of the response."},
+			{Name: "content_length", Type: proto.ColumnType_INT, Description: "The value of the HTTP Content-Length header of the response."},
+			{Name: "range_start", Type: proto.ColumnType_INT, Description: "The start value of the range, when the response contains the HTTP Content-Range header."},
+			{Name: "range_end", Type: proto.ColumnType_INT, Description: "The end value of the range, when the response contains the HTTP Content-Range header."},
+		},
+	})
+}
+
+//// PARSE FUNCTIONS
+
+// The fields of a CloudFront standard log record, in order. The first two
+// fields are the date and the time of the record.
+var cloudFrontAccessLogFieldNames = []string{
+	"", "", "edge_location", "sc_bytes", "client_ip", "method", "host", "uri_stem",
+	"status", "referer", "user_agent", "uri_query", "cookie", "edge_result_type",
+	"edge_request_id", "host_header", "protocol", "cs_bytes", "time_taken",
+	"forwarded_for", "ssl_protocol", "ssl_cipher", "edge_response_result_type",
+	"protocol_version", "fle_status", "fle_encrypted_fields", "client_port",
+	"time_to_first_byte", "edge_detailed_result_type", "content_type",
+	"content_length", "range_start", "range_end",
+}
+
+func parseCloudFrontAccessLog(line string) (map[string]interface{}, time.Time, bool) {
+	// Log files start with #Version and #Fields headers
+	if strings.HasPrefix(line, "#") {
+		return nil, time.Time{}, false
+	}
+
+	values := strings.Split(line, "\t")
+	if len(values) < 9 {
+		return nil, time.Time{}, false
+	}
+
+	timestamp, err := time.Parse("2006-01-02 15:04:05", values[0]+" "+values[1])
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	fields := accessLogFields(cloudFrontAccessLogFieldNames, values)
+
+	// Header values are URL-encoded, e.g. spaces in the User-Agent are %20
+	for _, name := range []string{"referer", "user_agent", "cookie"} {
+		if value, ok := fields[name].(string); ok {
+			if decoded, err := url.PathUnescape(value); err == nil {
+				fields[name] = decoded
+			}
+		}
+	}
+
+	return fields, timestamp, true
+}
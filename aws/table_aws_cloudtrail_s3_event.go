@@ -0,0 +1,290 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsCloudtrailS3Event(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_cloudtrail_s3_event",
+		Description: "AWS CloudTrail S3 Event",
+		List: &plugin.ListConfig{
+			Hydrate: listCloudtrailS3Events,
+			Tags:    map[string]string{"service": "s3", "action": "GetObject"},
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "bucket_name", CacheMatch: "exact"},
+				{Name: "prefix", Require: plugin.Optional, CacheMatch: "exact"},
+				{Name: "recipient_account_id", Require: plugin.Optional},
+				{Name: "aws_region", Require: plugin.Optional},
+				{Name: "timestamp", Operators: []string{">", ">=", "=", "<", "<="}, Require: plugin.Optional},
+				{Name: "event_time", Operators: []string{">", ">=", "=", "<", "<="}, Require: plugin.Optional},
+			},
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"NoSuchBucket"}),
+			},
+		},
+		Columns: awsAccountColumns(append([]*plugin.Column{
+			// Top columns
+			{Name: "bucket_name", Type: proto.ColumnType_STRING, Transform: transform.FromQual("bucket_name"), Description: "The name of the S3 bucket that the trail delivers log files to."},
+			{Name: "prefix", Type: proto.ColumnType_STRING, Transform: transform.FromQual("prefix"), Description: "The S3 key prefix of the trail, that comes before AWSLogs/ in the keys of the log files."},
+			{Name: "source", Type: proto.ColumnType_STRING, Description: "The S3 key of the log file that the event was read from."},
+			{Name: "timestamp", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("Timestamp").Transform(transform.UnixMsToTimestamp), Description: "The time when the event occurred."},
+			{Name: "timestamp_ms", Type: proto.ColumnType_INT, Transform: transform.FromField("Timestamp"), Description: "The time when the event occurred."},
+		}, cloudtrailEventColumns()...)),
+	}
+}
+
+type cloudtrailS3Event struct {
+	Timestamp *int64
+	Message   *string
+	Source    string
+}
+
+// CloudTrail log files are named <account>_CloudTrail_<region>_<yyyymmddThhmmZ>_<id>.json.gz
+// after the time they were delivered
+var cloudtrailS3LogFileTimeRegex = regexp.MustCompile(`_CloudTrail_[a-z0-9-]+_(\d{8}T\d{4}Z)_`)
+
+//// LIST FUNCTION
+
+func listCloudtrailS3Events(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	bucketName := d.EqualsQualString("bucket_name")
+
+	// Empty check
+	if bucketName == "" {
+		return nil, nil
+	}
+
+	// Org trails often deliver to a bucket in another account, so the
+	// bucket's region is looked up rather than taken from the connection
+	region, err := getS3BucketRegion(ctx, d, bucketName)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cloudtrail_s3_event.listCloudtrailS3Events", "bucket_region_error", err)
+		return nil, err
+	}
+	svc, err := S3Client(ctx, d, region)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cloudtrail_s3_event.listCloudtrailS3Events", "client_error", err)
+		return nil, err
+	}
+
+	startTime, endTime := getCloudtrailS3EventTimeRange(d)
+
+	prefixes, err := listCloudtrailS3EventPrefixes(ctx, d, svc, bucketName, startTime, endTime)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cloudtrail_s3_event.listCloudtrailS3Events", "api_error", err)
+		return nil, err
+	}
+
+	for _, prefix := range prefixes {
+		source := newS3FileSource(ctx, d, svc, bucketName, prefix)
+		keys, err := source.list()
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_cloudtrail_s3_event.listCloudtrailS3Events", "api_error", err, "prefix", prefix)
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if !strings.HasSuffix(key, ".json.gz") && !strings.HasSuffix(key, ".json") {
+				continue
+			}
+
+			// Events are delivered after they occur, so a file delivered
+			// before the start of the time range has no events in it
+			if match := cloudtrailS3LogFileTimeRegex.FindStringSubmatch(key); match != nil && !startTime.IsZero() {
+				if delivered, err := time.Parse("20060102T1504Z", match[1]); err == nil && delivered.Add(time.Minute).Before(startTime) {
+					continue
+				}
+			}
+
+			done, err := streamCloudtrailS3LogFile(ctx, d, source, key)
+			if err != nil {
+				plugin.Logger(ctx).Error("aws_cloudtrail_s3_event.listCloudtrailS3Events", "read_error", err, "key", key)
+				return nil, err
+			}
+			if done {
+				return nil, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// getCloudtrailS3EventTimeRange returns the time range of the timestamp and
+// event_time quals. Either end is zero if it is not bounded.
+func getCloudtrailS3EventTimeRange(d *plugin.QueryData) (time.Time, time.Time) {
+	var startTime, endTime time.Time
+	for _, column := range []string{"timestamp", "event_time"} {
+		if d.Quals[column] == nil {
+			continue
+		}
+		for _, q := range d.Quals[column].Quals {
+			t := q.Value.GetTimestampValue().AsTime()
+			switch q.Operator {
+			case "=":
+				startTime, endTime = t, t
+			case ">=", ">":
+				startTime = t
+			case "<", "<=":
+				endTime = t
+			}
+		}
+	}
+	return startTime, endTime
+}
+
+// listCloudtrailS3EventPrefixes returns the key prefixes to list log files
+// under, using the key layout of CloudTrail log files:
+//
+//	<prefix>/AWSLogs/[<org id>/]<account>/CloudTrail/<region>/<yyyy>/<mm>/<dd>/
+//
+// Accounts and regions are pruned by the recipient_account_id and aws_region
+// quals, and days by the time range.
+func listCloudtrailS3EventPrefixes(ctx context.Context, d *plugin.QueryData, svc *s3.Client, bucketName string, startTime time.Time, endTime time.Time) ([]string, error) {
+	base := d.EqualsQualString("prefix")
+	if base != "" && !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	base += "AWSLogs/"
+
+	// Organization trails add a level for the organization ID
+	children, err := listS3CommonPrefixes(ctx, d, svc, bucketName, base)
+	if err != nil {
+		return nil, err
+	}
+	var accountPrefixes []string
+	for _, child := range children {
+		if !strings.HasPrefix(strings.TrimPrefix(child, base), "o-") {
+			accountPrefixes = append(accountPrefixes, child)
+			continue
+		}
+		accounts, err := listS3CommonPrefixes(ctx, d, svc, bucketName, child)
+		if err != nil {
+			return nil, err
+		}
+		accountPrefixes = append(accountPrefixes, accounts...)
+	}
+
+	account := d.EqualsQualString("recipient_account_id")
+	region := d.EqualsQualString("aws_region")
+
+	var prefixes []string
+	for _, accountPrefix := range accountPrefixes {
+		if account != "" && !strings.HasSuffix(accountPrefix, "/"+account+"/") {
+			continue
+		}
+
+		regionPrefixes, err := listS3CommonPrefixes(ctx, d, svc, bucketName, accountPrefix+"CloudTrail/")
+		if err != nil {
+			return nil, err
+		}
+		for _, regionPrefix := range regionPrefixes {
+			if region != "" && !strings.HasSuffix(regionPrefix, "/"+region+"/") {
+				continue
+			}
+			prefixes = append(prefixes, cloudtrailS3EventDayPrefixes(regionPrefix, startTime, endTime)...)
+		}
+	}
+
+	return prefixes, nil
+}
+
+// cloudtrailS3EventDayPrefixes returns the prefixes of the days that files
+// with events in the time range were delivered on, or the region prefix if
+// the range has no start.
+func cloudtrailS3EventDayPrefixes(regionPrefix string, startTime time.Time, endTime time.Time) []string {
+	if startTime.IsZero() {
+		return []string{regionPrefix}
+	}
+
+	// Events of the end of a day may be delivered on the next day
+	now := time.Now().UTC()
+	if endTime.IsZero() || endTime.After(now) {
+		endTime = now
+	} else {
+		endTime = endTime.UTC().AddDate(0, 0, 1)
+	}
+
+	var prefixes []string
+	for day := startTime.UTC().Truncate(24 * time.Hour); !day.After(endTime); day = day.AddDate(0, 0, 1) {
+		prefixes = append(prefixes, regionPrefix+day.Format("2006/01/02/"))
+	}
+	return prefixes
+}
+
+// listS3CommonPrefixes lists the "directories" directly under a prefix
+func listS3CommonPrefixes(ctx context.Context, d *plugin.QueryData, svc *s3.Client, bucketName string, prefix string) ([]string, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucketName),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	}
+
+	var prefixes []string
+	paginator := s3.NewListObjectsV2Paginator(svc, input)
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, commonPrefix := range output.CommonPrefixes {
+			prefixes = append(prefixes, aws.ToString(commonPrefix.Prefix))
+		}
+	}
+	return prefixes, nil
+}
+
+// streamCloudtrailS3LogFile streams the events of a single log file. It
+// returns true once no more rows are required.
+func streamCloudtrailS3LogFile(ctx context.Context, d *plugin.QueryData, source *fileSource, key string) (bool, error) {
+	reader, err := source.openDecompressed(key)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	var logFile struct {
+		Records []json.RawMessage `json:"Records"`
+	}
+	if err := json.NewDecoder(reader).Decode(&logFile); err != nil {
+		return false, err
+	}
+
+	for _, record := range logFile.Records {
+		var event struct {
+			EventTime time.Time `json:"eventTime"`
+		}
+		if err := json.Unmarshal(record, &event); err != nil {
+			return false, err
+		}
+
+		d.StreamListItem(ctx, cloudtrailS3Event{
+			Timestamp: aws.Int64(event.EventTime.UnixMilli()),
+			Message:   aws.String(string(record)),
+			Source:    key,
+		})
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
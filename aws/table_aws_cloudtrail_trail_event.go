@@ -33,46 +33,53 @@ func tableAwsCloudtrailTrailEvent(_ context.Context) *plugin.Table {
 			},
 		},
 		GetMatrixItemFunc: SupportedRegionMatrix(cloudwatchlogsv1.EndpointsID),
-		Columns: awsRegionalColumns([]*plugin.Column{
+		Columns: awsRegionalColumns(append([]*plugin.Column{
 			// Top columns
 			{Name: "filter", Type: proto.ColumnType_STRING, Transform: transform.FromQual("filter"), Description: "The cloudwatch filter pattern for the search."},
 			{Name: "log_group_name", Type: proto.ColumnType_STRING, Transform: transform.FromQual("log_group_name"), Description: "The name of the log group to which this event belongs."},
 			{Name: "log_stream_name", Type: proto.ColumnType_STRING, Description: "The name of the log stream to which this event belongs."},
 			{Name: "timestamp", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("Timestamp").Transform(transform.UnixMsToTimestamp), Description: "The time when the event occurred."},
 			{Name: "timestamp_ms", Type: proto.ColumnType_INT, Transform: transform.FromField("Timestamp"), Description: "The time when the event occurred."},
+		}, cloudtrailEventColumns()...)),
+	}
+}
 
-			// CloudTrail event fields
-			{Name: "access_key_id", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Transform: transform.FromField("UserIdentity.AccessKeyId"), Description: "The AWS access key ID that was used to sign the request. If the request was made with temporary security credentials, this is the access key ID of the temporary credentials."},
-			{Name: "aws_region", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The AWS region that the request was made to, such as us-east-2."},
-			{Name: "error_code", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The AWS service error if the request returns an error."},
-			{Name: "error_message", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "If the request returns an error, the description of the error."},
-			{Name: "event_category", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "Shows the event category that is used in LookupEvents calls."},
-			{Name: "event_id", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The ID of the event."},
-			{Name: "event_name", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The name of the event returned."},
-			{Name: "event_source", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The AWS service that the request was made to."},
-			{Name: "event_time", Type: proto.ColumnType_TIMESTAMP, Hydrate: getCloudtrailMessageField, Description: "The date and time the request was made, in coordinated universal time (UTC)."},
-			{Name: "event_type", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "Identifies the type of event that generated the event record."},
-			{Name: "event_version", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The version of the log event format."},
-			{Name: "read_only", Type: proto.ColumnType_BOOL, Hydrate: getCloudtrailMessageField, Description: "Information about whether the event is a write event or a read event."},
-			{Name: "recipient_account_id", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "Represents the account ID that received this event."},
-			{Name: "request_id", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The value that identifies the request."},
-			{Name: "shared_event_id", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "GUID generated by CloudTrail to uniquely identify CloudTrail events from the same AWS action that is sent to different AWS accounts."},
-			{Name: "source_ip_address", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The IP address that the request was made from."},
-			{Name: "user_agent", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The agent through which the request was made, such as the AWS Management Console, an AWS service, the AWS SDKs or the AWS CLI."},
-			{Name: "user_type", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Transform: transform.FromField("UserIdentity.Type"), Description: "The name of the event returned."},
-			{Name: "username", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Transform: transform.FromField("UserIdentity.Username"), Description: "The user name of the user that made the api request."},
-			{Name: "user_identifier", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Transform: transform.FromField("UserIdentity.Arn", "UserIdentity.SessionContext.sessionIssuer.arn", "UserIdentity.SessionContext.sessionIssuer.principalId"), Description: "The name/arn of user/role that made the api call."},
-			{Name: "vpc_endpoint_id", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "Identifies the VPC endpoint in which requests were made from a VPC to another AWS service, such as Amazon S3."},
-
-			// Json fields
-			{Name: "additional_event_data", Type: proto.ColumnType_JSON, Hydrate: getCloudtrailMessageField, Description: "Additional data about the event that was not part of the request or response."},
-			{Name: "cloudtrail_event", Type: proto.ColumnType_JSON, Transform: transform.FromField("Message").Transform(trim).Transform(transform.UnmarshalYAML), Description: "The CloudTrail event in the json format."},
-			{Name: "request_parameters", Type: proto.ColumnType_JSON, Hydrate: getCloudtrailMessageField, Description: "The parameters, if any, that were sent with the request."},
-			{Name: "response_elements", Type: proto.ColumnType_JSON, Hydrate: getCloudtrailMessageField, Description: "The response element for actions that make changes (create, update, or delete actions)."},
-			{Name: "resources", Type: proto.ColumnType_JSON, Hydrate: getCloudtrailMessageField, Description: "A list of resources referenced by the event returned."},
-			{Name: "tls_details", Type: proto.ColumnType_JSON, Hydrate: getCloudtrailMessageField, Description: "Shows information about the Transport Layer Security (TLS) version, cipher suites, and the FQDN of the client-provided host name of a service API call."},
-			{Name: "user_identity", Type: proto.ColumnType_JSON, Hydrate: getCloudtrailMessageField, Description: "Information about the user that made the request."},
-		}),
+// cloudtrailEventColumns returns the columns of the fields of a CloudTrail
+// event, which are shared by the tables that read events from CloudWatch Logs
+// and from S3.
+func cloudtrailEventColumns() []*plugin.Column {
+	return []*plugin.Column{
+		// CloudTrail event fields
+		{Name: "access_key_id", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Transform: transform.FromField("UserIdentity.AccessKeyId"), Description: "The AWS access key ID that was used to sign the request. If the request was made with temporary security credentials, this is the access key ID of the temporary credentials."},
+		{Name: "aws_region", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The AWS region that the request was made to, such as us-east-2."},
+		{Name: "error_code", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The AWS service error if the request returns an error."},
+		{Name: "error_message", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "If the request returns an error, the description of the error."},
+		{Name: "event_category", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "Shows the event category that is used in LookupEvents calls."},
+		{Name: "event_id", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The ID of the event."},
+		{Name: "event_name", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The name of the event returned."},
+		{Name: "event_source", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The AWS service that the request was made to."},
+		{Name: "event_time", Type: proto.ColumnType_TIMESTAMP, Hydrate: getCloudtrailMessageField, Description: "The date and time the request was made, in coordinated universal time (UTC)."},
+		{Name: "event_type", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "Identifies the type of event that generated the event record."},
+		{Name: "event_version", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The version of the log event format."},
+		{Name: "read_only", Type: proto.ColumnType_BOOL, Hydrate: getCloudtrailMessageField, Description: "Information about whether the event is a write event or a read event."},
+		{Name: "recipient_account_id", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "Represents the account ID that received this event."},
+		{Name: "request_id", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The value that identifies the request."},
+		{Name: "shared_event_id", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "GUID generated by CloudTrail to uniquely identify CloudTrail events from the same AWS action that is sent to different AWS accounts."},
+		{Name: "source_ip_address", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The IP address that the request was made from."},
+		{Name: "user_agent", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "The agent through which the request was made, such as the AWS Management Console, an AWS service, the AWS SDKs or the AWS CLI."},
+		{Name: "user_type", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Transform: transform.FromField("UserIdentity.Type"), Description: "The name of the event returned."},
+		{Name: "username", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Transform: transform.FromField("UserIdentity.Username"), Description: "The user name of the user that made the api request."},
+		{Name: "user_identifier", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Transform: transform.FromField("UserIdentity.Arn", "UserIdentity.SessionContext.sessionIssuer.arn", "UserIdentity.SessionContext.sessionIssuer.principalId"), Description: "The name/arn of user/role that made the api call."},
+		{Name: "vpc_endpoint_id", Type: proto.ColumnType_STRING, Hydrate: getCloudtrailMessageField, Description: "Identifies the VPC endpoint in which requests were made from a VPC to another AWS service, such as Amazon S3."},
+
+		// Json fields
+		{Name: "additional_event_data", Type: proto.ColumnType_JSON, Hydrate: getCloudtrailMessageField, Description: "Additional data about the event that was not part of the request or response."},
+		{Name: "cloudtrail_event", Type: proto.ColumnType_JSON, Transform: transform.FromField("Message").Transform(trim).Transform(transform.UnmarshalYAML), Description: "The CloudTrail event in the json format."},
+		{Name: "request_parameters", Type: proto.ColumnType_JSON, Hydrate: getCloudtrailMessageField, Description: "The parameters, if any, that were sent with the request."},
+		{Name: "response_elements", Type: proto.ColumnType_JSON, Hydrate: getCloudtrailMessageField, Description: "The response element for actions that make changes (create, update, or delete actions)."},
+		{Name: "resources", Type: proto.ColumnType_JSON, Hydrate: getCloudtrailMessageField, Description: "A list of resources referenced by the event returned."},
+		{Name: "tls_details", Type: proto.ColumnType_JSON, Hydrate: getCloudtrailMessageField, Description: "Shows information about the Transport Layer Security (TLS) version, cipher suites, and the FQDN of the client-provided host name of a service API call."},
+		{Name: "user_identity", Type: proto.ColumnType_JSON, Hydrate: getCloudtrailMessageField, Description: "Information about the user that made the request."},
 	}
 }
 
@@ -324,9 +331,15 @@ func listCloudwatchLogTrailEvents(ctx context.Context, d *plugin.QueryData, _ *p
 }
 
 func getCloudtrailMessageField(ctx context.Context, _ *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	e := h.Item.(types.FilteredLogEvent)
+	var message *string
+	switch e := h.Item.(type) {
+	case types.FilteredLogEvent:
+		message = e.Message
+	case cloudtrailS3Event:
+		message = e.Message
+	}
 	cte := cloudtrailEvent{}
-	err := json.Unmarshal([]byte(*e.Message), &cte)
+	err := json.Unmarshal([]byte(*message), &cte)
 	if err != nil {
 		return nil, err
 	}
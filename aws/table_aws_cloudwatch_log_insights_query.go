@@ -0,0 +1,236 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+
+	cloudwatchlogsv1 "github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+const (
+	// Time range queried when there are no start_time or end_time quals
+	logInsightsQueryDefaultRange = time.Hour
+
+	// How often to check if a query has completed
+	logInsightsQueryPollInterval = time.Second
+
+	// StartQuery returns at most 10,000 rows
+	logInsightsQueryMaxLimit = 10000
+)
+
+//// TABLE DEFINITION
+
+func tableAwsCloudwatchLogInsightsQuery(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_cloudwatch_log_insights_query",
+		Description: "AWS CloudWatch Logs Insights Query",
+		List: &plugin.ListConfig{
+			Hydrate: listCloudwatchLogInsightsQueryResults,
+			Tags:    map[string]string{"service": "logs", "action": "StartQuery"},
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "query_string", CacheMatch: "exact"},
+				{Name: "log_group_names", CacheMatch: "exact"},
+				{Name: "start_time", Require: plugin.Optional},
+				{Name: "end_time", Require: plugin.Optional},
+			},
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"ResourceNotFoundException"}),
+			},
+		},
+		GetMatrixItemFunc: SupportedRegionMatrix(cloudwatchlogsv1.EndpointsID),
+		Columns: awsRegionalColumns([]*plugin.Column{
+			{
+				Name:        "query_string",
+				Description: "The Logs Insights query that was run.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("query_string"),
+			},
+			{
+				Name:        "log_group_names",
+				Description: "A comma-separated list of the names of the log groups the query was run on, e.g. '/aws/lambda/a,/aws/lambda/b'.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("log_group_names"),
+			},
+			{
+				Name:        "start_time",
+				Description: "The beginning of the time range that was queried. Defaults to an hour before end_time.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "end_time",
+				Description: "The end of the time range that was queried. Defaults to the current time.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "query_id",
+				Description: "The unique ID of the query.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "status",
+				Description: "The status of the query when it finished. Possible values are: Complete|Failed|Cancelled|Timeout.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "fields",
+				Description: "The fields of the result row, such as @timestamp and @message or the fields of a stats command, keyed by field name.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "records_matched",
+				Description: "The number of log events that matched the query string.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Statistics.RecordsMatched"),
+			},
+			{
+				Name:        "records_scanned",
+				Description: "The total number of log events scanned during the query.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Statistics.RecordsScanned"),
+			},
+			{
+				Name:        "bytes_scanned",
+				Description: "The total number of bytes in the log events scanned during the query.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Statistics.BytesScanned"),
+			},
+		}),
+	}
+}
+
+type logInsightsQueryRow struct {
+	QueryId    *string
+	StartTime  time.Time
+	EndTime    time.Time
+	Status     types.QueryStatus
+	Fields     map[string]string
+	Statistics *types.QueryStatistics
+}
+
+//// LIST FUNCTION
+
+func listCloudwatchLogInsightsQueryResults(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// Get client
+	svc, err := CloudWatchLogsClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cloudwatch_log_insights_query.listCloudwatchLogInsightsQueryResults", "get_client_error", err)
+		return nil, err
+	}
+
+	// Log group names can't contain commas, so a single qual can hold several
+	var logGroupNames []string
+	for _, name := range strings.Split(d.EqualsQualString("log_group_names"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			logGroupNames = append(logGroupNames, name)
+		}
+	}
+	if len(logGroupNames) == 0 {
+		return nil, nil
+	}
+
+	endTime := time.Now()
+	if d.EqualsQuals["end_time"] != nil {
+		endTime = d.EqualsQuals["end_time"].GetTimestampValue().AsTime()
+	}
+	startTime := endTime.Add(-logInsightsQueryDefaultRange)
+	if d.EqualsQuals["start_time"] != nil {
+		startTime = d.EqualsQuals["start_time"].GetTimestampValue().AsTime()
+	}
+
+	// Limiting the results
+	maxLimit := int32(logInsightsQueryMaxLimit)
+	if d.QueryContext.Limit != nil {
+		limit := int32(*d.QueryContext.Limit)
+		if limit < maxLimit {
+			if limit < 1 {
+				maxLimit = 1
+			} else {
+				maxLimit = limit
+			}
+		}
+	}
+
+	params := &cloudwatchlogs.StartQueryInput{
+		QueryString:   aws.String(d.EqualsQualString("query_string")),
+		LogGroupNames: logGroupNames,
+		StartTime:     aws.Int64(startTime.Unix()),
+		EndTime:       aws.Int64(endTime.Unix()),
+		Limit:         aws.Int32(maxLimit),
+	}
+
+	// apply rate limiting
+	d.WaitForListRateLimit(ctx)
+
+	query, err := svc.StartQuery(ctx, params)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cloudwatch_log_insights_query.listCloudwatchLogInsightsQueryResults", "api_error", err)
+		return nil, err
+	}
+
+	output, err := waitForLogInsightsQuery(ctx, svc, query.QueryId)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cloudwatch_log_insights_query.listCloudwatchLogInsightsQueryResults", "api_error", err, "query_id", *query.QueryId)
+		return nil, err
+	}
+	if output.Status != types.QueryStatusComplete {
+		return nil, fmt.Errorf("query %s did not complete, status: %s", *query.QueryId, output.Status)
+	}
+
+	for _, result := range output.Results {
+		fields := map[string]string{}
+		for _, field := range result {
+			fields[aws.ToString(field.Field)] = aws.ToString(field.Value)
+		}
+
+		d.StreamListItem(ctx, logInsightsQueryRow{
+			QueryId:    query.QueryId,
+			StartTime:  startTime,
+			EndTime:    endTime,
+			Status:     output.Status,
+			Fields:     fields,
+			Statistics: output.Statistics,
+		})
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// waitForLogInsightsQuery polls the results of a query until it has finished,
+// stopping the query if the context is cancelled first.
+func waitForLogInsightsQuery(ctx context.Context, svc *cloudwatchlogs.Client, queryId *string) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+	for {
+		output, err := svc.GetQueryResults(ctx, &cloudwatchlogs.GetQueryResultsInput{QueryId: queryId})
+		if err != nil {
+			return nil, err
+		}
+
+		switch output.Status {
+		case types.QueryStatusScheduled, types.QueryStatusRunning:
+		default:
+			return output, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			// Use a new context, as the query's context has been cancelled
+			_, _ = svc.StopQuery(context.Background(), &cloudwatchlogs.StopQueryInput{QueryId: queryId})
+			return nil, ctx.Err()
+		case <-time.After(logInsightsQueryPollInterval):
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsCostAnomaly(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_cost_anomaly",
+		Description: "AWS Cost Explorer - Cost Anomaly",
+		List: &plugin.ListConfig{
+			Hydrate: listCostAnomalies,
+			Tags:    map[string]string{"service": "ce", "action": "GetAnomalies"},
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "monitor_arn", Require: plugin.Optional},
+				{Name: "feedback", Require: plugin.Optional},
+				{Name: "total_impact", Operators: []string{"=", ">", ">=", "<", "<="}, Require: plugin.Optional},
+				{Name: "search_start_time", Require: plugin.Optional},
+				{Name: "search_end_time", Require: plugin.Optional},
+			},
+		},
+		Columns: awsGlobalRegionColumns([]*plugin.Column{
+			{
+				Name:        "anomaly_id",
+				Description: "The unique identifier for the anomaly.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "monitor_arn",
+				Description: "The Amazon Resource Name (ARN) for the cost monitor that generated this anomaly.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "anomaly_start_date",
+				Description: "The first day the anomaly is detected.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "anomaly_end_date",
+				Description: "The last day the anomaly is detected.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "dimension_value",
+				Description: "The dimension for the anomaly (for example, an AWS service in a service monitor).",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "feedback",
+				Description: "The feedback value for the anomaly. Possible values are: YES|NO|PLANNED_ACTIVITY.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "current_score",
+				Description: "The last observed score of the anomaly.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("AnomalyScore.CurrentScore"),
+			},
+			{
+				Name:        "max_score",
+				Description: "The maximum score that's observed during the anomaly period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("AnomalyScore.MaxScore"),
+			},
+			{
+				Name:        "max_impact",
+				Description: "The maximum dollar value that's observed for an anomaly.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Impact.MaxImpact"),
+			},
+			{
+				Name:        "total_impact",
+				Description: "The cumulative dollar difference between the total actual spend and total expected spend.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Impact.TotalImpact"),
+			},
+			{
+				Name:        "total_impact_percentage",
+				Description: "The cumulative percentage difference between the total actual spend and total expected spend.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Impact.TotalImpactPercentage"),
+			},
+			{
+				Name:        "total_actual_spend",
+				Description: "The cumulative dollar amount that was actually spent during the anomaly.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Impact.TotalActualSpend"),
+			},
+			{
+				Name:        "total_expected_spend",
+				Description: "The cumulative dollar amount that was expected to be spent during the anomaly.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Impact.TotalExpectedSpend"),
+			},
+			{
+				Name:        "root_causes",
+				Description: "The list of identified root causes for the anomaly, with the service, linked account, region and usage type of each.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "search_start_time",
+				Description: "The beginning of the time period to search for anomalies. Defaults to 90 days ago.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromQual("search_start_time"),
+			},
+			{
+				Name:        "search_end_time",
+				Description: "The end of the time period to search for anomalies. Defaults to the current time.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromQual("search_end_time"),
+			},
+
+			// Steampipe standard columns
+			{
+				Name:        "title",
+				Description: resourceInterfaceDescription("title"),
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("AnomalyId"),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listCostAnomalies(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// Get client
+	svc, err := CostExplorerClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_anomaly.listCostAnomalies", "client_error", err)
+		return nil, err
+	}
+
+	// Anomalies are retained for 90 days
+	startTime, endTime := getCESearchTimeRange(d, time.Now().AddDate(0, 0, -90))
+	input := &costexplorer.GetAnomaliesInput{
+		DateInterval: &types.AnomalyDateInterval{
+			StartDate: aws.String(startTime.UTC().Format("2006-01-02")),
+			EndDate:   aws.String(endTime.UTC().Format("2006-01-02")),
+		},
+	}
+	if d.EqualsQualString("monitor_arn") != "" {
+		input.MonitorArn = aws.String(d.EqualsQualString("monitor_arn"))
+	}
+	if d.EqualsQualString("feedback") != "" {
+		input.Feedback = types.AnomalyFeedbackType(d.EqualsQualString("feedback"))
+	}
+	input.TotalImpact = buildCostAnomalyTotalImpactFilter(d.Quals["total_impact"])
+
+	// Paginator not available for API GetAnomalies
+	for {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := svc.GetAnomalies(ctx, input)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_cost_anomaly.listCostAnomalies", "api_error", err)
+			return nil, err
+		}
+
+		for _, anomaly := range output.Anomalies {
+			d.StreamListItem(ctx, anomaly)
+
+			// Context may get cancelled due to manual cancellation or if the limit has been reached
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	return nil, nil
+}
+
+// buildCostAnomalyTotalImpactFilter converts the total_impact quals into a
+// TotalImpactFilter. A lower and an upper bound are combined into BETWEEN.
+func buildCostAnomalyTotalImpactFilter(quals *plugin.KeyColumnQuals) *types.TotalImpactFilter {
+	if quals == nil {
+		return nil
+	}
+
+	var lower, upper *float64
+	for _, q := range quals.Quals {
+		value := q.Value.GetDoubleValue()
+		switch q.Operator {
+		case "=":
+			return &types.TotalImpactFilter{NumericOperator: types.NumericOperatorEqual, StartValue: value}
+		case ">", ">=":
+			lower = &value
+		case "<", "<=":
+			upper = &value
+		}
+	}
+
+	switch {
+	case lower != nil && upper != nil:
+		return &types.TotalImpactFilter{NumericOperator: types.NumericOperatorBetween, StartValue: *lower, EndValue: *upper}
+	case lower != nil:
+		return &types.TotalImpactFilter{NumericOperator: types.NumericOperatorGreaterThanOrEqual, StartValue: *lower}
+	case upper != nil:
+		return &types.TotalImpactFilter{NumericOperator: types.NumericOperatorLessThanOrEqual, StartValue: *upper}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsCostAnomalyMonitor(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_cost_anomaly_monitor",
+		Description: "AWS Cost Explorer - Cost Anomaly Monitor",
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("monitor_arn"),
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"UnknownMonitorException"}),
+			},
+			Hydrate: getCostAnomalyMonitor,
+			Tags:    map[string]string{"service": "ce", "action": "GetAnomalyMonitors"},
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listCostAnomalyMonitors,
+			Tags:    map[string]string{"service": "ce", "action": "GetAnomalyMonitors"},
+		},
+		Columns: awsGlobalRegionColumns([]*plugin.Column{
+			{
+				Name:        "monitor_name",
+				Description: "The name of the monitor.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "monitor_arn",
+				Description: "The Amazon Resource Name (ARN) of the monitor.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "monitor_type",
+				Description: "The type of the monitor. Possible values are: DIMENSIONAL|CUSTOM.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "monitor_dimension",
+				Description: "The dimension that a DIMENSIONAL monitor evaluates.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "creation_date",
+				Description: "The date when the monitor was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "last_evaluated_date",
+				Description: "The date when the monitor last evaluated for anomalies.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "last_updated_date",
+				Description: "The date when the monitor was last updated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "dimensional_value_count",
+				Description: "The number of values that the monitor evaluates for its dimension.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "monitor_specification",
+				Description: "The filter expression that a CUSTOM monitor evaluates.",
+				Type:        proto.ColumnType_JSON,
+			},
+
+			// Steampipe standard columns
+			{
+				Name:        "title",
+				Description: resourceInterfaceDescription("title"),
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("MonitorName"),
+			},
+			{
+				Name:        "akas",
+				Description: resourceInterfaceDescription("akas"),
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("MonitorArn").Transform(transform.EnsureStringArray),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listCostAnomalyMonitors(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// Get client
+	svc, err := CostExplorerClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_anomaly_monitor.listCostAnomalyMonitors", "client_error", err)
+		return nil, err
+	}
+
+	// Paginator not available for API GetAnomalyMonitors
+	input := &costexplorer.GetAnomalyMonitorsInput{}
+	for {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := svc.GetAnomalyMonitors(ctx, input)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_cost_anomaly_monitor.listCostAnomalyMonitors", "api_error", err)
+			return nil, err
+		}
+
+		for _, monitor := range output.AnomalyMonitors {
+			d.StreamListItem(ctx, monitor)
+
+			// Context may get cancelled due to manual cancellation or if the limit has been reached
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getCostAnomalyMonitor(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	arn := d.EqualsQualString("monitor_arn")
+	if arn == "" {
+		return nil, nil
+	}
+
+	// Get client
+	svc, err := CostExplorerClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_anomaly_monitor.getCostAnomalyMonitor", "client_error", err)
+		return nil, err
+	}
+
+	output, err := svc.GetAnomalyMonitors(ctx, &costexplorer.GetAnomalyMonitorsInput{
+		MonitorArnList: []string{arn},
+		MaxResults:     aws.Int32(1),
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_anomaly_monitor.getCostAnomalyMonitor", "api_error", err)
+		return nil, err
+	}
+
+	if len(output.AnomalyMonitors) > 0 {
+		return output.AnomalyMonitors[0], nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,152 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsCostAnomalySubscription(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_cost_anomaly_subscription",
+		Description: "AWS Cost Explorer - Cost Anomaly Subscription",
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("subscription_arn"),
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"UnknownSubscriptionException"}),
+			},
+			Hydrate: getCostAnomalySubscription,
+			Tags:    map[string]string{"service": "ce", "action": "GetAnomalySubscriptions"},
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listCostAnomalySubscriptions,
+			Tags:    map[string]string{"service": "ce", "action": "GetAnomalySubscriptions"},
+		},
+		Columns: awsGlobalRegionColumns([]*plugin.Column{
+			{
+				Name:        "subscription_name",
+				Description: "The name of the subscription.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "subscription_arn",
+				Description: "The Amazon Resource Name (ARN) of the subscription.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "frequency",
+				Description: "The frequency that anomaly reports are sent. Possible values are: DAILY|IMMEDIATE|WEEKLY.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "threshold",
+				Description: "The dollar value that triggers a notification if the threshold is exceeded. Deprecated in favor of threshold_expression.",
+				Type:        proto.ColumnType_DOUBLE,
+			},
+			{
+				Name:        "monitor_arn_list",
+				Description: "A list of cost anomaly monitors covered by the subscription.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "subscribers",
+				Description: "A list of subscribers to notify.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "threshold_expression",
+				Description: "The filter expression on anomaly impact that triggers a notification.",
+				Type:        proto.ColumnType_JSON,
+			},
+
+			// Steampipe standard columns
+			{
+				Name:        "title",
+				Description: resourceInterfaceDescription("title"),
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("SubscriptionName"),
+			},
+			{
+				Name:        "akas",
+				Description: resourceInterfaceDescription("akas"),
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("SubscriptionArn").Transform(transform.EnsureStringArray),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listCostAnomalySubscriptions(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// Get client
+	svc, err := CostExplorerClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_anomaly_subscription.listCostAnomalySubscriptions", "client_error", err)
+		return nil, err
+	}
+
+	// Paginator not available for API GetAnomalySubscriptions
+	input := &costexplorer.GetAnomalySubscriptionsInput{}
+	for {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := svc.GetAnomalySubscriptions(ctx, input)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_cost_anomaly_subscription.listCostAnomalySubscriptions", "api_error", err)
+			return nil, err
+		}
+
+		for _, subscription := range output.AnomalySubscriptions {
+			d.StreamListItem(ctx, subscription)
+
+			// Context may get cancelled due to manual cancellation or if the limit has been reached
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getCostAnomalySubscription(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	arn := d.EqualsQualString("subscription_arn")
+	if arn == "" {
+		return nil, nil
+	}
+
+	// Get client
+	svc, err := CostExplorerClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_anomaly_subscription.getCostAnomalySubscription", "client_error", err)
+		return nil, err
+	}
+
+	output, err := svc.GetAnomalySubscriptions(ctx, &costexplorer.GetAnomalySubscriptionsInput{
+		SubscriptionArnList: []string{arn},
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_anomaly_subscription.getCostAnomalySubscription", "api_error", err)
+		return nil, err
+	}
+
+	if len(output.AnomalySubscriptions) > 0 {
+		return output.AnomalySubscriptions[0], nil
+	}
+	return nil, nil
+}
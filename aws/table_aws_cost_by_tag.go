@@ -2,6 +2,7 @@ package aws
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
@@ -22,8 +23,14 @@ func tableAwsCostByTag(_ context.Context) *plugin.Table {
 		List: &plugin.ListConfig{
 			KeyColumns: []*plugin.KeyColumn{
 				{Name: "granularity", Require: plugin.Required},
-				{Name: "tag_key_1", Require: plugin.Required},
+				{Name: "tag_key_1", Require: plugin.AnyOf},
+				{Name: "cost_category_key", Require: plugin.AnyOf},
 				{Name: "tag_key_2", Operators: []string{"=", "<>"}, Require: plugin.Optional, CacheMatch: "exact"},
+				{Name: "tag_value_1", Operators: []string{"=", "<>"}, Require: plugin.Optional},
+				{Name: "tag_value_2", Operators: []string{"=", "<>"}, Require: plugin.Optional},
+				{Name: "cost_category_value", Operators: []string{"=", "<>"}, Require: plugin.Optional},
+				{Name: "linked_account_id", Operators: []string{"=", "<>"}, Require: plugin.Optional},
+				{Name: "service", Operators: []string{"=", "<>"}, Require: plugin.Optional},
 			},
 			Hydrate: listCostAndUsageByTags,
 			Tags:    map[string]string{"service": "ce", "action": "GetCostAndUsage"},
@@ -48,7 +55,7 @@ func tableAwsCostByTag(_ context.Context) *plugin.Table {
 					Name:        "tag_value_1",
 					Description: "The primary tag value grouped by.",
 					Type:        proto.ColumnType_STRING,
-					Transform:   transform.FromField("Dimension1").Transform(splitCETagValue),
+					Transform:   transform.FromP(costByTagGroupValue, "tag_key_1"),
 				},
 				{
 					Name:        "tag_key_2",
@@ -60,7 +67,35 @@ func tableAwsCostByTag(_ context.Context) *plugin.Table {
 					Name:        "tag_value_2",
 					Description: "A secondary tag value grouped by.",
 					Type:        proto.ColumnType_STRING,
-					Transform:   transform.FromField("Dimension2").Transform(splitCETagValue),
+					Transform:   transform.FromP(costByTagGroupValue, "tag_key_2"),
+				},
+				{
+					Name:        "is_untagged",
+					Description: "True if the cost has no value for the primary tag key.",
+					Type:        proto.ColumnType_BOOL,
+					Transform:   transform.FromP(costByTagIsUntagged, "tag_key_1"),
+				},
+				{
+					Name:        "cost_category_key",
+					Description: "The cost category name to group by.",
+					Type:        proto.ColumnType_STRING,
+					Transform:   transform.FromQual("cost_category_key"),
+				},
+				{
+					Name:        "cost_category_value",
+					Description: "The cost category value grouped by.",
+					Type:        proto.ColumnType_STRING,
+					Transform:   transform.FromP(costByTagGroupValue, "cost_category_key"),
+				},
+				{
+					Name:        "linked_account_id",
+					Description: "The linked account of the cost.",
+					Type:        proto.ColumnType_STRING,
+				},
+				{
+					Name:        "service",
+					Description: "The service of the cost, e.g. Amazon Elastic Compute Cloud - Compute.",
+					Type:        proto.ColumnType_STRING,
 				},
 			}),
 		),
@@ -70,11 +105,81 @@ func tableAwsCostByTag(_ context.Context) *plugin.Table {
 //// LIST FUNCTION
 
 func listCostAndUsageByTags(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
-	params := buildInputFromTagKeyAndTagValueQuals(ctx, d)
-	return streamCostAndUsage(ctx, d, params)
+	// Create session
+	svc, err := CostExplorerClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_by_tag.listCostAndUsageByTags", "client_error", err)
+		return nil, err
+	}
+
+	params, dimensions, err := buildInputFromTagKeyAndTagValueQuals(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_by_tag.listCostAndUsageByTags", "build_input_error", err)
+		return nil, err
+	}
+
+	for {
+		output, err := getCostAndUsage(ctx, d, svc, params)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_cost_by_tag.listCostAndUsageByTags", "api_error", err)
+			return nil, err
+		}
+
+		for _, row := range buildCEMetricRows(ctx, output, d.EqualsQuals) {
+			d.StreamListItem(ctx, costByTagRow{
+				CEMetricRow:     row,
+				LinkedAccountId: costByTagDimensionValue(row, dimensions["linked_account_id"]),
+				Service:         costByTagDimensionValue(row, dimensions["service"]),
+			})
+
+			// Context can be cancelled due to manual cancellation or the limit has been hit
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+
+		if output.NextPageToken == nil {
+			break
+		}
+		params.NextPageToken = output.NextPageToken
+	}
+
+	return nil, nil
+}
+
+// costByTagRow is a cost row with the linked account and service it belongs
+// to, either from the quals or from the groups of the result
+type costByTagRow struct {
+	CEMetricRow
+	LinkedAccountId *string
+	Service         *string
+}
+
+// costByTagDimensionValue returns the value of a dimension for a row: the
+// value that was filtered on, or the group key that holds it
+func costByTagDimensionValue(row CEMetricRow, dimension costByTagDimension) *string {
+	switch {
+	case dimension.Value != "":
+		return aws.String(dimension.Value)
+	case dimension.GroupIndex == 0:
+		return row.Dimension1
+	case dimension.GroupIndex == 1:
+		return row.Dimension2
+	}
+	return nil
+}
+
+// costByTagDimension is how the value of a dimension column is known for the
+// rows of a request
+type costByTagDimension struct {
+	Value      string
+	GroupIndex int
 }
 
-func buildInputFromTagKeyAndTagValueQuals(ctx context.Context, d *plugin.QueryData) *costexplorer.GetCostAndUsageInput {
+// buildInputFromTagKeyAndTagValueQuals builds a single request for the costs
+// matching the quals, and returns how the linked account and service of each
+// row is known
+func buildInputFromTagKeyAndTagValueQuals(ctx context.Context, d *plugin.QueryData) (*costexplorer.GetCostAndUsageInput, map[string]costByTagDimension, error) {
 	granularity := strings.ToUpper(d.EqualsQualString("granularity"))
 	timeFormat := "2006-01-02"
 	if granularity == "HOURLY" {
@@ -91,27 +196,178 @@ func buildInputFromTagKeyAndTagValueQuals(ctx context.Context, d *plugin.QueryDa
 		Granularity: types.Granularity(granularity),
 		Metrics:     AllCostMetrics(),
 	}
-	tagKey1 := d.EqualsQualString("tag_key_1")
-	tagKey2 := d.EqualsQualString("tag_key_2")
+
+	groupKeys := costByTagGroupKeys(d.EqualsQualString)
+	if len(groupKeys) > 2 {
+		return nil, nil, fmt.Errorf("at most two of tag_key_1, tag_key_2 and cost_category_key can be used to group by")
+	}
 
 	var groupsBy []types.GroupDefinition
+	for _, groupKey := range groupKeys {
+		groupType := types.GroupDefinitionTypeTag
+		if groupKey == "cost_category_key" {
+			groupType = types.GroupDefinitionTypeCostCategory
+		}
+		groupsBy = append(groupsBy, types.GroupDefinition{
+			Type: groupType,
+			Key:  aws.String(d.EqualsQualString(groupKey)),
+		})
+	}
+	var filters []types.Expression
 
-	groupBy := types.GroupDefinition{
-		Type: "TAG",
-		Key:  aws.String(tagKey1),
+	// Filter on the grouped tag and cost category values
+	valueQuals := map[string]string{
+		"tag_value_1":         "tag_key_1",
+		"tag_value_2":         "tag_key_2",
+		"cost_category_value": "cost_category_key",
 	}
-	groupsBy = append(groupsBy, groupBy)
+	for valueQual, keyQual := range valueQuals {
+		key := d.EqualsQualString(keyQual)
+		if d.Quals[valueQual] == nil || key == "" {
+			continue
+		}
+		for _, qual := range d.Quals[valueQual].Quals {
+			filter := buildCostByTagValueFilter(keyQual, key, ceQualStringValues(qual.Value))
+			if qual.Operator == "<>" {
+				filter = types.Expression{Not: &filter}
+			}
+			filters = append(filters, filter)
+		}
+	}
+
+	// Filter on the linked account and service dimensions. Rows only know the
+	// value of a dimension that is filtered to a single value, so a dimension
+	// filtered to several values, or only excluded with <>, is grouped by.
+	dimensions := map[string]costByTagDimension{}
+	for _, dimension := range []struct {
+		qualName string
+		key      types.Dimension
+	}{
+		{"linked_account_id", types.DimensionLinkedAccount},
+		{"service", types.DimensionService},
+	} {
+		dimensions[dimension.qualName] = costByTagDimension{GroupIndex: -1}
+		if d.Quals[dimension.qualName] == nil {
+			continue
+		}
+
+		var included []string
+		filtered := false
+		for _, qual := range d.Quals[dimension.qualName].Quals {
+			values := ceQualStringValues(qual.Value)
+			expression := types.Expression{
+				Dimensions: &types.DimensionValues{
+					Key:    dimension.key,
+					Values: values,
+				},
+			}
+			if qual.Operator == "<>" {
+				expression = types.Expression{Not: &expression}
+			} else {
+				included = values
+			}
+			filters = append(filters, expression)
+			filtered = true
+		}
+		if !filtered {
+			continue
+		}
 
-	if tagKey2 != "" {
+		if len(included) == 1 {
+			dimensions[dimension.qualName] = costByTagDimension{Value: included[0], GroupIndex: -1}
+			continue
+		}
+		if len(groupsBy) == 2 {
+			return nil, nil, fmt.Errorf("%s can only be filtered with in or <> when at most one of tag_key_1, tag_key_2 and cost_category_key is used", dimension.qualName)
+		}
+		dimensions[dimension.qualName] = costByTagDimension{GroupIndex: len(groupsBy)}
 		groupsBy = append(groupsBy, types.GroupDefinition{
-			Type: "TAG",
-			Key:  aws.String(tagKey2),
+			Type: types.GroupDefinitionTypeDimension,
+			Key:  aws.String(string(dimension.key)),
 		})
 	}
-
 	params.GroupBy = groupsBy
 
-	return params
+	if len(filters) > 1 {
+		params.Filter = &types.Expression{
+			And: filters,
+		}
+	} else if len(filters) == 1 {
+		params.Filter = &(filters[0])
+	}
+
+	return params, dimensions, nil
+}
+
+// costByTagGroupKeys returns the grouping quals that are set, in the order
+// their values appear in the result groups.
+func costByTagGroupKeys(qualValue func(string) string) []string {
+	var groupKeys []string
+	for _, groupKey := range []string{"tag_key_1", "tag_key_2", "cost_category_key"} {
+		if qualValue(groupKey) != "" {
+			groupKeys = append(groupKeys, groupKey)
+		}
+	}
+	return groupKeys
+}
+
+// buildCostByTagValueFilter matches the given tag or cost category values. An
+// empty value matches costs that have no value for the key.
+func buildCostByTagValueFilter(keyQual string, key string, values []string) types.Expression {
+	var present []string
+	absent := false
+	for _, value := range values {
+		if value == "" {
+			absent = true
+			continue
+		}
+		present = append(present, value)
+	}
+
+	var expressions []types.Expression
+	if len(present) > 0 {
+		expressions = append(expressions, costByTagValueExpression(keyQual, key, present, types.MatchOptionEquals))
+	}
+	if absent {
+		expressions = append(expressions, costByTagValueExpression(keyQual, key, nil, types.MatchOptionAbsent))
+	}
+
+	if len(expressions) == 1 {
+		return expressions[0]
+	}
+	return types.Expression{Or: expressions}
+}
+
+func costByTagValueExpression(keyQual string, key string, values []string, matchOption types.MatchOption) types.Expression {
+	if keyQual == "cost_category_key" {
+		return types.Expression{
+			CostCategories: &types.CostCategoryValues{
+				Key:          aws.String(key),
+				Values:       values,
+				MatchOptions: []types.MatchOption{matchOption},
+			},
+		}
+	}
+	return types.Expression{
+		Tags: &types.TagValues{
+			Key:          aws.String(key),
+			Values:       values,
+			MatchOptions: []types.MatchOption{matchOption},
+		},
+	}
+}
+
+// ceQualStringValues returns the values of an = or <> qual, which is a list
+// for in and not in.
+func ceQualStringValues(value *proto.QualValue) []string {
+	if value.GetListValue() != nil {
+		var values []string
+		for _, v := range value.GetListValue().Values {
+			values = append(values, v.GetStringValue())
+		}
+		return values
+	}
+	return []string{value.GetStringValue()}
 }
 
 //// TRANSFORM FUNCTIONS
@@ -129,3 +385,51 @@ func splitCETagValue(_ context.Context, d *transform.TransformData) (interface{}
 
 	return strings.Join(tag[1:], "$"), nil
 }
+
+func costByTagGroupValue(ctx context.Context, d *transform.TransformData) (interface{}, error) {
+	row := d.HydrateItem.(costByTagRow)
+	dimension := costByTagGroupDimension(d, row.CEMetricRow)
+	if dimension == nil {
+		return nil, nil
+	}
+	return splitCETagValue(ctx, &transform.TransformData{Value: dimension})
+}
+
+func costByTagIsUntagged(ctx context.Context, d *transform.TransformData) (interface{}, error) {
+	row := d.HydrateItem.(costByTagRow)
+	dimension := costByTagGroupDimension(d, row.CEMetricRow)
+	if dimension == nil {
+		return nil, nil
+	}
+
+	// Costs without the tag are returned with an empty value, e.g. "Team$"
+	value, err := splitCETagValue(ctx, &transform.TransformData{Value: dimension})
+	if err != nil {
+		return nil, err
+	}
+	return value == "", nil
+}
+
+// costByTagGroupDimension returns the group value of the grouping qual named
+// in the transform param.
+func costByTagGroupDimension(d *transform.TransformData, row CEMetricRow) *string {
+	qualValue := func(name string) string {
+		for _, q := range d.KeyColumnQuals[name] {
+			if q.Operator == "=" {
+				return q.Value.GetStringValue()
+			}
+		}
+		return ""
+	}
+
+	for i, groupKey := range costByTagGroupKeys(qualValue) {
+		if groupKey != d.Param.(string) {
+			continue
+		}
+		if i == 0 {
+			return row.Dimension1
+		}
+		return row.Dimension2
+	}
+	return nil
+}
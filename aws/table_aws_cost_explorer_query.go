@@ -0,0 +1,220 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/turbot/go-kit/helpers"
+	goKitTypes "github.com/turbot/go-kit/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableAwsCostExplorerQuery(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_cost_explorer_query",
+		Description: "AWS Cost Explorer - Cost and Usage Query",
+		List: &plugin.ListConfig{
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "granularity", Require: plugin.Required},
+				{Name: "search_start_time", Require: plugin.Optional},
+				{Name: "search_end_time", Require: plugin.Optional},
+				{Name: "metrics", Require: plugin.Optional, CacheMatch: "exact"},
+				{Name: "group_by", Require: plugin.Optional, CacheMatch: "exact"},
+				{Name: "filter", Require: plugin.Optional, CacheMatch: "exact"},
+			},
+			Hydrate: listCostExplorerQuery,
+			Tags:    map[string]string{"service": "ce", "action": "GetCostAndUsage"},
+		},
+		Columns: awsGlobalRegionColumns(
+			costExplorerColumns([]*plugin.Column{
+				{
+					Name:        "group_key_1",
+					Description: "The raw key returned by Cost Explorer for the first group. For tag and cost category groupings this is in the form 'key$value'.",
+					Type:        proto.ColumnType_STRING,
+					Transform:   transform.FromField("Dimension1"),
+				},
+				{
+					Name:        "group_value_1",
+					Description: "The value of the first group. For tag and cost category groupings the key prefix is removed.",
+					Type:        proto.ColumnType_STRING,
+					Transform:   transform.FromField("Dimension1").TransformP(ceQueryGroupValue, 0),
+				},
+				{
+					Name:        "group_key_2",
+					Description: "The raw key returned by Cost Explorer for the second group. For tag and cost category groupings this is in the form 'key$value'.",
+					Type:        proto.ColumnType_STRING,
+					Transform:   transform.FromField("Dimension2"),
+				},
+				{
+					Name:        "group_value_2",
+					Description: "The value of the second group. For tag and cost category groupings the key prefix is removed.",
+					Type:        proto.ColumnType_STRING,
+					Transform:   transform.FromField("Dimension2").TransformP(ceQueryGroupValue, 1),
+				},
+
+				// Quals columns - to filter the lookups
+				{
+					Name:        "granularity",
+					Description: "The granularity for cost and usage metric data. Possible values are: DAILY|MONTHLY|HOURLY.",
+					Type:        proto.ColumnType_STRING,
+					Hydrate:     hydrateCostAndUsageQuals,
+				},
+				{
+					Name:        "search_start_time",
+					Description: "The beginning of the time period. Defaults to the lookback period for the granularity.",
+					Type:        proto.ColumnType_TIMESTAMP,
+					Transform:   transform.FromQual("search_start_time"),
+				},
+				{
+					Name:        "search_end_time",
+					Description: "The end of the time period. Defaults to the current time.",
+					Type:        proto.ColumnType_TIMESTAMP,
+					Transform:   transform.FromQual("search_end_time"),
+				},
+				{
+					Name:        "metrics",
+					Description: "The list of metrics to return, e.g. [\"UnblendedCost\", \"UsageQuantity\"]. Defaults to all cost metrics.",
+					Type:        proto.ColumnType_JSON,
+					Transform:   transform.FromQual("metrics"),
+				},
+				{
+					Name:        "group_by",
+					Description: "Up to two groupings, e.g. [{\"Type\": \"DIMENSION\", \"Key\": \"LINKED_ACCOUNT\"}, {\"Type\": \"COST_CATEGORY\", \"Key\": \"Team\"}]. Valid types are DIMENSION, TAG and COST_CATEGORY.",
+					Type:        proto.ColumnType_JSON,
+					Transform:   transform.FromQual("group_by"),
+				},
+				{
+					Name:        "filter",
+					Description: "A Cost Explorer filter expression, e.g. {\"Dimensions\": {\"Key\": \"REGION\", \"Values\": [\"us-east-1\"]}}.",
+					Type:        proto.ColumnType_JSON,
+					Transform:   transform.FromQual("filter"),
+				},
+			}),
+		),
+	}
+}
+
+//// LIST FUNCTION
+
+func listCostExplorerQuery(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	params, err := buildCostExplorerQueryInput(d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_explorer_query.listCostExplorerQuery", "input_error", err)
+		return nil, err
+	}
+	return streamCostAndUsage(ctx, d, params)
+}
+
+func buildCostExplorerQueryInput(d *plugin.QueryData) (*costexplorer.GetCostAndUsageInput, error) {
+	granularity := strings.ToUpper(d.EqualsQualString("granularity"))
+
+	params := &costexplorer.GetCostAndUsageInput{
+		TimePeriod:  getCEDateInterval(d, granularity),
+		Granularity: types.Granularity(granularity),
+		Metrics:     AllCostMetrics(),
+	}
+
+	if d.EqualsQuals["metrics"] != nil {
+		var metrics []string
+		if err := json.Unmarshal([]byte(d.EqualsQuals["metrics"].GetJsonbValue()), &metrics); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal metrics %v: %v", d.EqualsQuals["metrics"].GetJsonbValue(), err)
+		}
+		for _, metric := range metrics {
+			if !helpers.StringSliceContains(AllCostMetrics(), metric) {
+				return nil, fmt.Errorf("invalid metric %q, valid metrics are %s", metric, strings.Join(AllCostMetrics(), ", "))
+			}
+		}
+		if len(metrics) > 0 {
+			params.Metrics = metrics
+		}
+	}
+
+	groupBy, err := getCEQueryGroupDefinitions(d.EqualsQuals["group_by"])
+	if err != nil {
+		return nil, err
+	}
+	params.GroupBy = groupBy
+
+	if d.EqualsQuals["filter"] != nil {
+		var filter types.Expression
+		if err := json.Unmarshal([]byte(d.EqualsQuals["filter"].GetJsonbValue()), &filter); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal filter %v: %v", d.EqualsQuals["filter"].GetJsonbValue(), err)
+		}
+		params.Filter = &filter
+	}
+
+	return params, nil
+}
+
+// getCEQueryGroupDefinitions parses the group_by qual. Cost Explorer accepts
+// at most two groupings per request.
+func getCEQueryGroupDefinitions(qual *proto.QualValue) ([]types.GroupDefinition, error) {
+	if qual == nil {
+		return nil, nil
+	}
+
+	var groupBy []types.GroupDefinition
+	if err := json.Unmarshal([]byte(qual.GetJsonbValue()), &groupBy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal group_by %v: %v", qual.GetJsonbValue(), err)
+	}
+	if len(groupBy) > 2 {
+		return nil, fmt.Errorf("group_by supports at most 2 groupings, got %d", len(groupBy))
+	}
+	for i, group := range groupBy {
+		groupType := types.GroupDefinitionType(strings.ToUpper(string(group.Type)))
+		switch groupType {
+		case types.GroupDefinitionTypeDimension, types.GroupDefinitionTypeTag, types.GroupDefinitionTypeCostCategory:
+		default:
+			return nil, fmt.Errorf("invalid group_by type %q, valid types are DIMENSION, TAG and COST_CATEGORY", group.Type)
+		}
+		if group.Key == nil || *group.Key == "" {
+			return nil, fmt.Errorf("group_by entry %d is missing a key", i+1)
+		}
+		groupBy[i].Type = groupType
+		if groupType == types.GroupDefinitionTypeDimension {
+			groupBy[i].Key = aws.String(strings.ToUpper(*group.Key))
+		}
+	}
+
+	return groupBy, nil
+}
+
+//// TRANSFORM FUNCTIONS
+
+// ceQueryGroupValue strips the 'key$' prefix that Cost Explorer adds to
+// tag and cost category group keys. The param is the index of the grouping
+// in the group_by qual.
+func ceQueryGroupValue(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	value := goKitTypes.SafeString(d.Value)
+	if value == "" {
+		return nil, nil
+	}
+
+	qualSlice := d.KeyColumnQuals["group_by"]
+	if len(qualSlice) == 0 {
+		return value, nil
+	}
+	groupBy, err := getCEQueryGroupDefinitions(qualSlice[0].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	index := d.Param.(int)
+	if index >= len(groupBy) || groupBy[index].Type == types.GroupDefinitionTypeDimension {
+		return value, nil
+	}
+
+	parts := strings.Split(value, "$")
+	if len(parts) == 1 {
+		return value, nil
+	}
+	return strings.Join(parts[1:], "$"), nil
+}
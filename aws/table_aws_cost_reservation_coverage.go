@@ -0,0 +1,209 @@
+package aws
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsCostReservationCoverage(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_cost_reservation_coverage",
+		Description: "AWS Cost Explorer - Reservation Coverage",
+		List: &plugin.ListConfig{
+			Hydrate: listCostReservationCoverage,
+			Tags:    map[string]string{"service": "ce", "action": "GetReservationCoverage"},
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "granularity", Require: plugin.Required},
+				{Name: "dimension_type", Require: plugin.Optional},
+				{Name: "search_start_time", Require: plugin.Optional},
+				{Name: "search_end_time", Require: plugin.Optional},
+			},
+		},
+		Columns: awsGlobalRegionColumns([]*plugin.Column{
+			{
+				Name:        "period_start",
+				Description: "Start timestamp for this coverage data.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "period_end",
+				Description: "End timestamp for this coverage data.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "coverage_hours_percentage",
+				Description: "The percentage of instance hours that a reservation covered.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Coverage.CoverageHours.CoverageHoursPercentage"),
+			},
+			{
+				Name:        "on_demand_hours",
+				Description: "The number of instance running hours that On-Demand Instances covered.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Coverage.CoverageHours.OnDemandHours"),
+			},
+			{
+				Name:        "reserved_hours",
+				Description: "The number of instance running hours that reservations covered.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Coverage.CoverageHours.ReservedHours"),
+			},
+			{
+				Name:        "total_running_hours",
+				Description: "The total instance usage, in hours.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Coverage.CoverageHours.TotalRunningHours"),
+			},
+			{
+				Name:        "coverage_normalized_units_percentage",
+				Description: "The percentage of your used instance normalized units that a reservation covers.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Coverage.CoverageNormalizedUnits.CoverageNormalizedUnitsPercentage"),
+			},
+			{
+				Name:        "on_demand_normalized_units",
+				Description: "The number of normalized units that are covered by On-Demand Instances instead of a reservation.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Coverage.CoverageNormalizedUnits.OnDemandNormalizedUnits"),
+			},
+			{
+				Name:        "reserved_normalized_units",
+				Description: "The number of normalized units that a reservation covers.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Coverage.CoverageNormalizedUnits.ReservedNormalizedUnits"),
+			},
+			{
+				Name:        "total_running_normalized_units",
+				Description: "The total number of normalized units that you used.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Coverage.CoverageNormalizedUnits.TotalRunningNormalizedUnits"),
+			},
+			{
+				Name:        "on_demand_cost",
+				Description: "How much an On-Demand Instance costs.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Coverage.CoverageCost.OnDemandCost"),
+			},
+			{
+				Name:        "attributes",
+				Description: "The attributes of the group, when grouped by dimension_type.",
+				Type:        proto.ColumnType_JSON,
+			},
+
+			// Quals columns - to filter the lookups
+			{
+				Name:        "granularity",
+				Description: "The granularity of the coverage data. Possible values are: DAILY|MONTHLY.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("granularity"),
+			},
+			{
+				Name:        "dimension_type",
+				Description: "The dimension to group the coverage by, e.g. INSTANCE_TYPE, LINKED_ACCOUNT, REGION or PLATFORM.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("dimension_type"),
+			},
+			{
+				Name:        "search_start_time",
+				Description: "The beginning of the time period. Defaults to the lookback period for the granularity.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromQual("search_start_time"),
+			},
+			{
+				Name:        "search_end_time",
+				Description: "The end of the time period. Defaults to the current time.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromQual("search_end_time"),
+			},
+		}),
+	}
+}
+
+type ceReservationCoverageRow struct {
+	PeriodStart *string
+	PeriodEnd   *string
+	Attributes  map[string]string
+	Coverage    *types.Coverage
+}
+
+//// LIST FUNCTION
+
+func listCostReservationCoverage(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// Get client
+	svc, err := CostExplorerClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_reservation_coverage.listCostReservationCoverage", "client_error", err)
+		return nil, err
+	}
+
+	granularity := strings.ToUpper(d.EqualsQualString("granularity"))
+	input := &costexplorer.GetReservationCoverageInput{
+		TimePeriod:  getCEDateInterval(d, granularity),
+		Granularity: types.Granularity(granularity),
+	}
+	if dimension := d.EqualsQualString("dimension_type"); dimension != "" {
+		input.GroupBy = []types.GroupDefinition{
+			{
+				Type: types.GroupDefinitionTypeDimension,
+				Key:  aws.String(strings.ToUpper(dimension)),
+			},
+		}
+	}
+
+	// Paginator not available for API GetReservationCoverage
+	for {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := svc.GetReservationCoverage(ctx, input)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_cost_reservation_coverage.listCostReservationCoverage", "api_error", err)
+			return nil, err
+		}
+
+		for _, result := range output.CoveragesByTime {
+			rows := []ceReservationCoverageRow{}
+			if len(result.Groups) == 0 {
+				rows = append(rows, ceReservationCoverageRow{
+					Coverage: result.Total,
+				})
+			}
+			for _, group := range result.Groups {
+				rows = append(rows, ceReservationCoverageRow{
+					Attributes: group.Attributes,
+					Coverage:   group.Coverage,
+				})
+			}
+
+			for _, row := range rows {
+				if result.TimePeriod != nil {
+					row.PeriodStart = result.TimePeriod.Start
+					row.PeriodEnd = result.TimePeriod.End
+				}
+				d.StreamListItem(ctx, row)
+
+				// Context may get cancelled due to manual cancellation or if the limit has been reached
+				if d.RowsRemaining(ctx) == 0 {
+					return nil, nil
+				}
+			}
+		}
+
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,246 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsCostReservationPurchaseRecommendation(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_cost_reservation_purchase_recommendation",
+		Description: "AWS Cost Explorer - Reservation Purchase Recommendation",
+		List: &plugin.ListConfig{
+			Hydrate: listCostReservationPurchaseRecommendations,
+			Tags:    map[string]string{"service": "ce", "action": "GetReservationPurchaseRecommendation"},
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "service", Require: plugin.Required},
+				{Name: "account_scope", Require: plugin.Optional},
+				{Name: "lookback_period_in_days", Require: plugin.Optional},
+				{Name: "payment_option", Require: plugin.Optional},
+				{Name: "term_in_years", Require: plugin.Optional},
+			},
+		},
+		Columns: awsGlobalRegionColumns([]*plugin.Column{
+			{
+				Name:        "service",
+				Description: "The service for which the recommendation is made, e.g. Amazon Elastic Compute Cloud - Compute, Amazon Relational Database Service, Amazon ElastiCache, Amazon Redshift or Amazon OpenSearch Service.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("service"),
+			},
+			{
+				Name:        "recommendation_id",
+				Description: "The ID for the recommendation.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Metadata.RecommendationId"),
+			},
+			{
+				Name:        "generation_timestamp",
+				Description: "The timestamp for when Amazon Web Services made the recommendation.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Metadata.GenerationTimestamp"),
+			},
+			{
+				Name:        "account_scope",
+				Description: "The account scope for the recommendation. Possible values are: PAYER|LINKED.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Recommendation.AccountScope"),
+			},
+			{
+				Name:        "lookback_period_in_days",
+				Description: "How many days of previous usage the recommendation considers. Possible values are: SEVEN_DAYS|THIRTY_DAYS|SIXTY_DAYS.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Recommendation.LookbackPeriodInDays"),
+			},
+			{
+				Name:        "payment_option",
+				Description: "The payment option for the reservation. Possible values are: NO_UPFRONT|PARTIAL_UPFRONT|ALL_UPFRONT.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Recommendation.PaymentOption"),
+			},
+			{
+				Name:        "term_in_years",
+				Description: "The term of the reservation. Possible values are: ONE_YEAR|THREE_YEARS.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Recommendation.TermInYears"),
+			},
+			{
+				Name:        "linked_account_id",
+				Description: "The account that the recommendation is for.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Detail.AccountId"),
+			},
+			{
+				Name:        "currency_code",
+				Description: "The currency code of the recommendation.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Detail.CurrencyCode"),
+			},
+			{
+				Name:        "recommended_number_of_instances_to_purchase",
+				Description: "The number of instances that Amazon Web Services recommends that you purchase.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.RecommendedNumberOfInstancesToPurchase"),
+			},
+			{
+				Name:        "recommended_normalized_units_to_purchase",
+				Description: "The number of normalized units that Amazon Web Services recommends that you purchase.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.RecommendedNormalizedUnitsToPurchase"),
+			},
+			{
+				Name:        "average_utilization",
+				Description: "The average utilization of your instances.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.AverageUtilization"),
+			},
+			{
+				Name:        "estimated_break_even_in_months",
+				Description: "How long Amazon Web Services estimates that it takes for this instance to start saving you money, in months.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.EstimatedBreakEvenInMonths"),
+			},
+			{
+				Name:        "estimated_monthly_on_demand_cost",
+				Description: "How much Amazon Web Services estimates that you spend on On-Demand Instances in a month.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.EstimatedMonthlyOnDemandCost"),
+			},
+			{
+				Name:        "estimated_monthly_savings_amount",
+				Description: "How much Amazon Web Services estimates that this specific recommendation might save you in a month.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.EstimatedMonthlySavingsAmount"),
+			},
+			{
+				Name:        "estimated_monthly_savings_percentage",
+				Description: "How much Amazon Web Services estimates that this specific recommendation might save you in a month, as a percentage of your overall costs.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.EstimatedMonthlySavingsPercentage"),
+			},
+			{
+				Name:        "estimated_reservation_cost_for_lookback_period",
+				Description: "How much Amazon Web Services estimates that you might spend for all usage during the specified historical period if you had a reservation.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.EstimatedReservationCostForLookbackPeriod"),
+			},
+			{
+				Name:        "upfront_cost",
+				Description: "How much purchasing this instance costs you upfront.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.UpfrontCost"),
+			},
+			{
+				Name:        "recurring_standard_monthly_cost",
+				Description: "How much purchasing this instance costs you on a monthly basis.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.RecurringStandardMonthlyCost"),
+			},
+			{
+				Name:        "average_number_of_instances_used_per_hour",
+				Description: "The average number of instances that you used in an hour during the historical period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.AverageNumberOfInstancesUsedPerHour"),
+			},
+			{
+				Name:        "maximum_number_of_instances_used_per_hour",
+				Description: "The maximum number of instances that you used in an hour during the historical period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.MaximumNumberOfInstancesUsedPerHour"),
+			},
+			{
+				Name:        "minimum_number_of_instances_used_per_hour",
+				Description: "The minimum number of instances that you used in an hour during the historical period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.MinimumNumberOfInstancesUsedPerHour"),
+			},
+			{
+				Name:        "instance_details",
+				Description: "Details about the instances that Amazon Web Services recommends that you purchase.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Detail.InstanceDetails"),
+			},
+			{
+				Name:        "recommendation_summary",
+				Description: "A summary of the total estimated monthly savings for the recommendation.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Recommendation.RecommendationSummary"),
+			},
+		}),
+	}
+}
+
+type ceReservationPurchaseRecommendationRow struct {
+	Metadata       *types.ReservationPurchaseRecommendationMetadata
+	Recommendation types.ReservationPurchaseRecommendation
+	Detail         types.ReservationPurchaseRecommendationDetail
+}
+
+//// LIST FUNCTION
+
+func listCostReservationPurchaseRecommendations(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// Get client
+	svc, err := CostExplorerClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_reservation_purchase_recommendation.listCostReservationPurchaseRecommendations", "client_error", err)
+		return nil, err
+	}
+
+	input := &costexplorer.GetReservationPurchaseRecommendationInput{
+		Service: aws.String(d.EqualsQualString("service")),
+	}
+	if d.EqualsQualString("account_scope") != "" {
+		input.AccountScope = types.AccountScope(d.EqualsQualString("account_scope"))
+	}
+	if d.EqualsQualString("lookback_period_in_days") != "" {
+		input.LookbackPeriodInDays = types.LookbackPeriodInDays(d.EqualsQualString("lookback_period_in_days"))
+	}
+	if d.EqualsQualString("payment_option") != "" {
+		input.PaymentOption = types.PaymentOption(d.EqualsQualString("payment_option"))
+	}
+	if d.EqualsQualString("term_in_years") != "" {
+		input.TermInYears = types.TermInYears(d.EqualsQualString("term_in_years"))
+	}
+
+	// Paginator not available for API GetReservationPurchaseRecommendation
+	for {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := svc.GetReservationPurchaseRecommendation(ctx, input)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_cost_reservation_purchase_recommendation.listCostReservationPurchaseRecommendations", "api_error", err)
+			return nil, err
+		}
+
+		for _, recommendation := range output.Recommendations {
+			for _, detail := range recommendation.RecommendationDetails {
+				d.StreamListItem(ctx, ceReservationPurchaseRecommendationRow{
+					Metadata:       output.Metadata,
+					Recommendation: recommendation,
+					Detail:         detail,
+				})
+
+				// Context may get cancelled due to manual cancellation or if the limit has been reached
+				if d.RowsRemaining(ctx) == 0 {
+					return nil, nil
+				}
+			}
+		}
+
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,265 @@
+package aws
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsCostReservationUtilization(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_cost_reservation_utilization",
+		Description: "AWS Cost Explorer - Reservation Utilization",
+		List: &plugin.ListConfig{
+			Hydrate: listCostReservationUtilization,
+			Tags:    map[string]string{"service": "ce", "action": "GetReservationUtilization"},
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "granularity", Require: plugin.Required},
+				{Name: "dimension_type", Require: plugin.Optional},
+				{Name: "search_start_time", Require: plugin.Optional},
+				{Name: "search_end_time", Require: plugin.Optional},
+			},
+		},
+		Columns: awsGlobalRegionColumns([]*plugin.Column{
+			{
+				Name:        "period_start",
+				Description: "Start timestamp for this utilization data.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "period_end",
+				Description: "End timestamp for this utilization data.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "subscription_id",
+				Description: "The ID of the reservation, when grouped by SUBSCRIPTION_ID.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("GroupValue"),
+			},
+			{
+				Name:        "utilization_percentage",
+				Description: "The percentage of reservation time that you used.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.UtilizationPercentage"),
+			},
+			{
+				Name:        "utilization_percentage_in_units",
+				Description: "The percentage of Amazon EC2 reservation time that you used, converted to normalized units.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.UtilizationPercentageInUnits"),
+			},
+			{
+				Name:        "purchased_hours",
+				Description: "How many reservation hours that you purchased.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.PurchasedHours"),
+			},
+			{
+				Name:        "purchased_units",
+				Description: "The number of Amazon EC2 reservation hours that you purchased, converted to normalized units.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.PurchasedUnits"),
+			},
+			{
+				Name:        "total_actual_hours",
+				Description: "The total number of reservation hours that you used.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.TotalActualHours"),
+			},
+			{
+				Name:        "total_actual_units",
+				Description: "The total number of Amazon EC2 reservation hours that you used, converted to normalized units.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.TotalActualUnits"),
+			},
+			{
+				Name:        "unused_hours",
+				Description: "The number of reservation hours that you didn't use.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.UnusedHours"),
+			},
+			{
+				Name:        "unused_units",
+				Description: "The number of Amazon EC2 reservation hours that you didn't use, converted to normalized units.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.UnusedUnits"),
+			},
+			{
+				Name:        "amortized_recurring_fee",
+				Description: "The monthly cost of your reservation, amortized over the reservation period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.AmortizedRecurringFee"),
+			},
+			{
+				Name:        "amortized_upfront_fee",
+				Description: "The upfront cost of your reservation, amortized over the reservation period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.AmortizedUpfrontFee"),
+			},
+			{
+				Name:        "total_amortized_fee",
+				Description: "The total cost of your reservation, amortized over the reservation period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.TotalAmortizedFee"),
+			},
+			{
+				Name:        "on_demand_cost_of_ri_hours_used",
+				Description: "How much your reservation costs if charged On-Demand rates.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.OnDemandCostOfRIHoursUsed"),
+			},
+			{
+				Name:        "net_ri_savings",
+				Description: "How much you saved due to purchasing and utilizing reservation.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.NetRISavings"),
+			},
+			{
+				Name:        "total_potential_ri_savings",
+				Description: "How much you might save if you use your entire reservation.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.TotalPotentialRISavings"),
+			},
+			{
+				Name:        "realized_savings",
+				Description: "The realized savings because of purchasing and using a reservation.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.RealizedSavings"),
+			},
+			{
+				Name:        "unrealized_savings",
+				Description: "The unrealized savings because of purchasing and using a reservation.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.UnrealizedSavings"),
+			},
+			{
+				Name:        "ri_cost_for_unused_hours",
+				Description: "The cost of unused hours for your reservation.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Utilization.RICostForUnusedHours"),
+			},
+			{
+				Name:        "attributes",
+				Description: "The attributes of the reservation, when grouped by SUBSCRIPTION_ID.",
+				Type:        proto.ColumnType_JSON,
+			},
+
+			// Quals columns - to filter the lookups
+			{
+				Name:        "granularity",
+				Description: "The granularity of the utilization data. Possible values are: DAILY|MONTHLY.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("granularity"),
+			},
+			{
+				Name:        "dimension_type",
+				Description: "The dimension to group the utilization by. The only supported value is SUBSCRIPTION_ID.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("dimension_type"),
+			},
+			{
+				Name:        "search_start_time",
+				Description: "The beginning of the time period. Defaults to the lookback period for the granularity.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromQual("search_start_time"),
+			},
+			{
+				Name:        "search_end_time",
+				Description: "The end of the time period. Defaults to the current time.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromQual("search_end_time"),
+			},
+		}),
+	}
+}
+
+type ceReservationUtilizationRow struct {
+	PeriodStart *string
+	PeriodEnd   *string
+	GroupValue  *string
+	Attributes  map[string]string
+	Utilization *types.ReservationAggregates
+}
+
+//// LIST FUNCTION
+
+func listCostReservationUtilization(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// Get client
+	svc, err := CostExplorerClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_reservation_utilization.listCostReservationUtilization", "client_error", err)
+		return nil, err
+	}
+
+	granularity := strings.ToUpper(d.EqualsQualString("granularity"))
+	input := &costexplorer.GetReservationUtilizationInput{
+		TimePeriod:  getCEDateInterval(d, granularity),
+		Granularity: types.Granularity(granularity),
+	}
+	if dimension := d.EqualsQualString("dimension_type"); dimension != "" {
+		input.GroupBy = []types.GroupDefinition{
+			{
+				Type: types.GroupDefinitionTypeDimension,
+				Key:  aws.String(strings.ToUpper(dimension)),
+			},
+		}
+	}
+
+	// Paginator not available for API GetReservationUtilization
+	for {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := svc.GetReservationUtilization(ctx, input)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_cost_reservation_utilization.listCostReservationUtilization", "api_error", err)
+			return nil, err
+		}
+
+		for _, result := range output.UtilizationsByTime {
+			rows := []ceReservationUtilizationRow{}
+			if len(result.Groups) == 0 {
+				rows = append(rows, ceReservationUtilizationRow{
+					Utilization: result.Total,
+				})
+			}
+			for _, group := range result.Groups {
+				rows = append(rows, ceReservationUtilizationRow{
+					GroupValue:  group.Value,
+					Attributes:  group.Attributes,
+					Utilization: group.Utilization,
+				})
+			}
+
+			for _, row := range rows {
+				if result.TimePeriod != nil {
+					row.PeriodStart = result.TimePeriod.Start
+					row.PeriodEnd = result.TimePeriod.End
+				}
+				d.StreamListItem(ctx, row)
+
+				// Context may get cancelled due to manual cancellation or if the limit has been reached
+				if d.RowsRemaining(ctx) == 0 {
+					return nil, nil
+				}
+			}
+		}
+
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	return nil, nil
+}
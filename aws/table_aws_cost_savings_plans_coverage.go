@@ -0,0 +1,153 @@
+package aws
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsCostSavingsPlansCoverage(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_cost_savings_plans_coverage",
+		Description: "AWS Cost Explorer - Savings Plans Coverage",
+		List: &plugin.ListConfig{
+			Hydrate: listCostSavingsPlansCoverage,
+			Tags:    map[string]string{"service": "ce", "action": "GetSavingsPlansCoverage"},
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "granularity", Require: plugin.Required},
+				{Name: "dimension_type", Require: plugin.Optional},
+				{Name: "search_start_time", Require: plugin.Optional},
+				{Name: "search_end_time", Require: plugin.Optional},
+			},
+		},
+		Columns: awsGlobalRegionColumns([]*plugin.Column{
+			{
+				Name:        "period_start",
+				Description: "Start timestamp for this coverage data.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("TimePeriod.Start"),
+			},
+			{
+				Name:        "period_end",
+				Description: "End timestamp for this coverage data.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("TimePeriod.End"),
+			},
+			{
+				Name:        "coverage_percentage",
+				Description: "The percentage of your existing Savings Plans covered usage, divided by all of your eligible Savings Plans usage in an account (or set of accounts).",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Coverage.CoveragePercentage"),
+			},
+			{
+				Name:        "on_demand_cost",
+				Description: "The cost of your Amazon Web Services usage at the public On-Demand rate.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Coverage.OnDemandCost"),
+			},
+			{
+				Name:        "spend_covered_by_savings_plans",
+				Description: "The amount of your Amazon Web Services usage that's covered by a Savings Plans.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Coverage.SpendCoveredBySavingsPlans"),
+			},
+			{
+				Name:        "total_cost",
+				Description: "The total cost of your Amazon Web Services usage, regardless of your purchase option.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Coverage.TotalCost"),
+			},
+			{
+				Name:        "attributes",
+				Description: "The attributes of the group, when grouped by dimension_type.",
+				Type:        proto.ColumnType_JSON,
+			},
+
+			// Quals columns - to filter the lookups
+			{
+				Name:        "granularity",
+				Description: "The granularity of the coverage data. Possible values are: DAILY|MONTHLY.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("granularity"),
+			},
+			{
+				Name:        "dimension_type",
+				Description: "The dimension to group the coverage by. Possible values are: INSTANCE_FAMILY|REGION|SERVICE.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("dimension_type"),
+			},
+			{
+				Name:        "search_start_time",
+				Description: "The beginning of the time period. Defaults to the lookback period for the granularity.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromQual("search_start_time"),
+			},
+			{
+				Name:        "search_end_time",
+				Description: "The end of the time period. Defaults to the current time.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromQual("search_end_time"),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listCostSavingsPlansCoverage(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// Get client
+	svc, err := CostExplorerClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_savings_plans_coverage.listCostSavingsPlansCoverage", "client_error", err)
+		return nil, err
+	}
+
+	granularity := strings.ToUpper(d.EqualsQualString("granularity"))
+	input := &costexplorer.GetSavingsPlansCoverageInput{
+		TimePeriod:  getCEDateInterval(d, granularity),
+		Granularity: types.Granularity(granularity),
+	}
+	if dimension := d.EqualsQualString("dimension_type"); dimension != "" {
+		input.GroupBy = []types.GroupDefinition{
+			{
+				Type: types.GroupDefinitionTypeDimension,
+				Key:  aws.String(strings.ToUpper(dimension)),
+			},
+		}
+	}
+
+	paginator := costexplorer.NewGetSavingsPlansCoveragePaginator(svc, input, func(o *costexplorer.GetSavingsPlansCoveragePaginatorOptions) {
+		o.StopOnDuplicateToken = true
+	})
+
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_cost_savings_plans_coverage.listCostSavingsPlansCoverage", "api_error", err)
+			return nil, err
+		}
+
+		for _, coverage := range output.SavingsPlansCoverages {
+			d.StreamListItem(ctx, coverage)
+
+			// Context may get cancelled due to manual cancellation or if the limit has been reached
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
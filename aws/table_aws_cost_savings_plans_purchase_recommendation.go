@@ -0,0 +1,250 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsCostSavingsPlansPurchaseRecommendation(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_cost_savings_plans_purchase_recommendation",
+		Description: "AWS Cost Explorer - Savings Plans Purchase Recommendation",
+		List: &plugin.ListConfig{
+			Hydrate: listCostSavingsPlansPurchaseRecommendations,
+			Tags:    map[string]string{"service": "ce", "action": "GetSavingsPlansPurchaseRecommendation"},
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "savings_plans_type", Require: plugin.Required},
+				{Name: "account_scope", Require: plugin.Optional},
+				{Name: "lookback_period_in_days", Require: plugin.Optional},
+				{Name: "payment_option", Require: plugin.Optional},
+				{Name: "term_in_years", Require: plugin.Optional},
+			},
+		},
+		Columns: awsGlobalRegionColumns([]*plugin.Column{
+			{
+				Name:        "savings_plans_type",
+				Description: "The type of Savings Plans. Possible values are: COMPUTE_SP|EC2_INSTANCE_SP|SAGEMAKER_SP.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Recommendation.SavingsPlansType"),
+			},
+			{
+				Name:        "recommendation_id",
+				Description: "The unique identifier for the recommendation set.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Metadata.RecommendationId"),
+			},
+			{
+				Name:        "generation_timestamp",
+				Description: "The timestamp that shows when the recommendations were generated.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Metadata.GenerationTimestamp"),
+			},
+			{
+				Name:        "account_scope",
+				Description: "The account scope for the recommendation. Possible values are: PAYER|LINKED.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Recommendation.AccountScope"),
+			},
+			{
+				Name:        "lookback_period_in_days",
+				Description: "How many days of previous usage the recommendation considers. Possible values are: SEVEN_DAYS|THIRTY_DAYS|SIXTY_DAYS.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Recommendation.LookbackPeriodInDays"),
+			},
+			{
+				Name:        "payment_option",
+				Description: "The payment option for the Savings Plans. Possible values are: NO_UPFRONT|PARTIAL_UPFRONT|ALL_UPFRONT.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Recommendation.PaymentOption"),
+			},
+			{
+				Name:        "term_in_years",
+				Description: "The Savings Plans recommendation term in years. Possible values are: ONE_YEAR|THREE_YEARS.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Recommendation.TermInYears"),
+			},
+			{
+				Name:        "linked_account_id",
+				Description: "The account that the recommendation is for.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Detail.AccountId"),
+			},
+			{
+				Name:        "currency_code",
+				Description: "The currency code that Amazon Web Services used to generate the recommendation.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Detail.CurrencyCode"),
+			},
+			{
+				Name:        "hourly_commitment_to_purchase",
+				Description: "The recommended hourly commitment level for the Savings Plans type and the configuration that's based on the usage during the lookback period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.HourlyCommitmentToPurchase"),
+			},
+			{
+				Name:        "estimated_average_utilization",
+				Description: "The estimated utilization of the recommended Savings Plans.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.EstimatedAverageUtilization"),
+			},
+			{
+				Name:        "estimated_monthly_savings_amount",
+				Description: "The estimated monthly savings amount based on the recommended Savings Plans.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.EstimatedMonthlySavingsAmount"),
+			},
+			{
+				Name:        "estimated_on_demand_cost",
+				Description: "The remaining On-Demand cost estimated to not be covered by the recommended Savings Plans, over the length of the lookback period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.EstimatedOnDemandCost"),
+			},
+			{
+				Name:        "estimated_on_demand_cost_with_current_commitment",
+				Description: "The estimated On-Demand costs you expect with no additional commitment, based on your usage of the selected time period and the Savings Plans you own.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.EstimatedOnDemandCostWithCurrentCommitment"),
+			},
+			{
+				Name:        "estimated_roi",
+				Description: "The estimated return on investment that's based on the recommended Savings Plans that you purchased.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.EstimatedROI"),
+			},
+			{
+				Name:        "estimated_sp_cost",
+				Description: "The cost of the recommended Savings Plans over the length of the lookback period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.EstimatedSPCost"),
+			},
+			{
+				Name:        "estimated_savings_amount",
+				Description: "The estimated savings amount that's based on the recommended Savings Plans over the length of the lookback period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.EstimatedSavingsAmount"),
+			},
+			{
+				Name:        "estimated_savings_percentage",
+				Description: "The estimated savings percentage relative to the total cost of applicable On-Demand usage over the lookback period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.EstimatedSavingsPercentage"),
+			},
+			{
+				Name:        "current_average_hourly_on_demand_spend",
+				Description: "The average value of hourly On-Demand spend over the lookback period of the applicable usage type.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.CurrentAverageHourlyOnDemandSpend"),
+			},
+			{
+				Name:        "current_maximum_hourly_on_demand_spend",
+				Description: "The highest value of hourly On-Demand spend over the lookback period of the applicable usage type.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.CurrentMaximumHourlyOnDemandSpend"),
+			},
+			{
+				Name:        "current_minimum_hourly_on_demand_spend",
+				Description: "The lowest value of hourly On-Demand spend over the lookback period of the applicable usage type.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.CurrentMinimumHourlyOnDemandSpend"),
+			},
+			{
+				Name:        "upfront_cost",
+				Description: "The upfront cost of the recommended Savings Plans, based on the selected payment option.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Detail.UpfrontCost"),
+			},
+			{
+				Name:        "savings_plans_details",
+				Description: "Details for the Savings Plans that Amazon Web Services recommends that you purchase, including the instance family, offering ID and region.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Detail.SavingsPlansDetails"),
+			},
+			{
+				Name:        "recommendation_summary",
+				Description: "A summary of the total estimated savings for the recommendation.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Recommendation.SavingsPlansPurchaseRecommendationSummary"),
+			},
+		}),
+	}
+}
+
+type ceSavingsPlansPurchaseRecommendationRow struct {
+	Metadata       *types.SavingsPlansPurchaseRecommendationMetadata
+	Recommendation *types.SavingsPlansPurchaseRecommendation
+	Detail         types.SavingsPlansPurchaseRecommendationDetail
+}
+
+//// LIST FUNCTION
+
+func listCostSavingsPlansPurchaseRecommendations(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// Get client
+	svc, err := CostExplorerClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_savings_plans_purchase_recommendation.listCostSavingsPlansPurchaseRecommendations", "client_error", err)
+		return nil, err
+	}
+
+	// The API requires the lookback period, payment option and term, so use
+	// the same defaults as the console when they are not set
+	input := &costexplorer.GetSavingsPlansPurchaseRecommendationInput{
+		SavingsPlansType:     types.SupportedSavingsPlansType(d.EqualsQualString("savings_plans_type")),
+		LookbackPeriodInDays: types.LookbackPeriodInDaysThirtyDays,
+		PaymentOption:        types.PaymentOptionNoUpfront,
+		TermInYears:          types.TermInYearsOneYear,
+	}
+	if d.EqualsQualString("account_scope") != "" {
+		input.AccountScope = types.AccountScope(d.EqualsQualString("account_scope"))
+	}
+	if d.EqualsQualString("lookback_period_in_days") != "" {
+		input.LookbackPeriodInDays = types.LookbackPeriodInDays(d.EqualsQualString("lookback_period_in_days"))
+	}
+	if d.EqualsQualString("payment_option") != "" {
+		input.PaymentOption = types.PaymentOption(d.EqualsQualString("payment_option"))
+	}
+	if d.EqualsQualString("term_in_years") != "" {
+		input.TermInYears = types.TermInYears(d.EqualsQualString("term_in_years"))
+	}
+
+	// Paginator not available for API GetSavingsPlansPurchaseRecommendation
+	for {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := svc.GetSavingsPlansPurchaseRecommendation(ctx, input)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_cost_savings_plans_purchase_recommendation.listCostSavingsPlansPurchaseRecommendations", "api_error", err)
+			return nil, err
+		}
+
+		if output.SavingsPlansPurchaseRecommendation != nil {
+			for _, detail := range output.SavingsPlansPurchaseRecommendation.SavingsPlansPurchaseRecommendationDetails {
+				d.StreamListItem(ctx, ceSavingsPlansPurchaseRecommendationRow{
+					Metadata:       output.Metadata,
+					Recommendation: output.SavingsPlansPurchaseRecommendation,
+					Detail:         detail,
+				})
+
+				// Context may get cancelled due to manual cancellation or if the limit has been reached
+				if d.RowsRemaining(ctx) == 0 {
+					return nil, nil
+				}
+			}
+		}
+
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,706 @@
+package aws
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsCostUsageReport(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_cost_usage_report",
+		Description: "AWS Cost and Usage Report line items, read from report files in S3 or on the local file system.",
+		List: &plugin.ListConfig{
+			Hydrate: listCostUsageReportLineItems,
+			Tags:    map[string]string{"service": "s3", "action": "GetObject"},
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "bucket_name", Require: plugin.AnyOf, CacheMatch: "exact"},
+				{Name: "path", Require: plugin.AnyOf, CacheMatch: "exact"},
+				{Name: "prefix", Require: plugin.Optional, CacheMatch: "exact"},
+				{Name: "bill_billing_period_start_date", Require: plugin.Optional, Operators: []string{"=", ">", ">=", "<", "<="}},
+				{Name: "bill_payer_account_id", Require: plugin.Optional},
+				{Name: "line_item_usage_account_id", Require: plugin.Optional},
+			},
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func: getBucketLocationForObjects,
+				Tags: map[string]string{"service": "s3", "action": "GetBucketLocation"},
+			},
+		},
+		Columns: awsAccountColumns([]*plugin.Column{
+			{
+				Name:        "identity_line_item_id",
+				Description: "The ID of the line item. Line item IDs are not stable across report versions.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.identity_line_item_id"),
+			},
+			{
+				Name:        "identity_time_interval",
+				Description: "The time interval that the line item applies to, in the format start/end.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.identity_time_interval"),
+			},
+			{
+				Name:        "bill_invoice_id",
+				Description: "The ID of the invoice that the line item belongs to. Empty until the bill is finalized.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.bill_invoice_id"),
+			},
+			{
+				Name:        "bill_billing_entity",
+				Description: "Whether the line item is billed by AWS or by AWS Marketplace.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.bill_billing_entity"),
+			},
+			{
+				Name:        "bill_bill_type",
+				Description: "The type of bill, e.g. Anniversary, Purchase or Refund.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.bill_bill_type"),
+			},
+			{
+				Name:        "bill_payer_account_id",
+				Description: "The account ID of the paying account.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.bill_payer_account_id"),
+			},
+			{
+				Name:        "bill_billing_period_start_date",
+				Description: "The start date of the billing period covered by the report.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Fields.bill_billing_period_start_date"),
+			},
+			{
+				Name:        "bill_billing_period_end_date",
+				Description: "The end date of the billing period covered by the report.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Fields.bill_billing_period_end_date"),
+			},
+			{
+				Name:        "line_item_usage_account_id",
+				Description: "The account ID of the account that used the line item.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.line_item_usage_account_id"),
+			},
+			{
+				Name:        "line_item_line_item_type",
+				Description: "The type of charge, e.g. Usage, Tax, Credit, DiscountedUsage or SavingsPlanCoveredUsage.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.line_item_line_item_type"),
+			},
+			{
+				Name:        "line_item_usage_start_date",
+				Description: "The start date and time for the line item.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Fields.line_item_usage_start_date"),
+			},
+			{
+				Name:        "line_item_usage_end_date",
+				Description: "The end date and time for the line item.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Fields.line_item_usage_end_date"),
+			},
+			{
+				Name:        "line_item_product_code",
+				Description: "The code of the product measured, e.g. AmazonEC2.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.line_item_product_code"),
+			},
+			{
+				Name:        "line_item_usage_type",
+				Description: "The usage details of the line item, e.g. USW2-BoxUsage:m2.2xlarge.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.line_item_usage_type"),
+			},
+			{
+				Name:        "line_item_operation",
+				Description: "The specific AWS operation covered by the line item, e.g. RunInstances.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.line_item_operation"),
+			},
+			{
+				Name:        "line_item_availability_zone",
+				Description: "The Availability Zone that hosts the line item.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.line_item_availability_zone"),
+			},
+			{
+				Name:        "line_item_resource_id",
+				Description: "The resource ID of the line item, if the report includes resource IDs.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.line_item_resource_id"),
+			},
+			{
+				Name:        "line_item_usage_amount",
+				Description: "The amount of usage incurred during the specified time period.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Fields.line_item_usage_amount"),
+			},
+			{
+				Name:        "line_item_normalization_factor",
+				Description: "The normalization factor of the instance size, for size-flexible reservations.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Fields.line_item_normalization_factor"),
+			},
+			{
+				Name:        "line_item_normalized_usage_amount",
+				Description: "The amount of usage incurred, in normalized units.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Fields.line_item_normalized_usage_amount"),
+			},
+			{
+				Name:        "line_item_currency_code",
+				Description: "The currency that the line item is shown in.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.line_item_currency_code"),
+			},
+			{
+				Name:        "line_item_unblended_rate",
+				Description: "The rate that applies to this usage, without blending.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Fields.line_item_unblended_rate"),
+			},
+			{
+				Name:        "line_item_unblended_cost",
+				Description: "The unblended rate multiplied by the usage amount.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Fields.line_item_unblended_cost"),
+			},
+			{
+				Name:        "line_item_blended_rate",
+				Description: "The average cost incurred for each SKU across the organization.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Fields.line_item_blended_rate"),
+			},
+			{
+				Name:        "line_item_blended_cost",
+				Description: "The blended rate multiplied by the usage amount.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Fields.line_item_blended_cost"),
+			},
+			{
+				Name:        "line_item_line_item_description",
+				Description: "The description of the line item type.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.line_item_line_item_description"),
+			},
+			{
+				Name:        "line_item_tax_type",
+				Description: "The type of tax that AWS applied to the line item.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.line_item_tax_type"),
+			},
+			{
+				Name:        "line_item_legal_entity",
+				Description: "The seller of record of the product or service.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.line_item_legal_entity"),
+			},
+			{
+				Name:        "product_product_name",
+				Description: "The full name of the AWS service.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.product_product_name"),
+			},
+			{
+				Name:        "product_region",
+				Description: "The region code of the product, e.g. us-east-1.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.product_region"),
+			},
+			{
+				Name:        "product_instance_type",
+				Description: "The instance type of the product, for instance-based usage.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.product_instance_type"),
+			},
+			{
+				Name:        "pricing_term",
+				Description: "Whether the usage is Reserved or On-Demand.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.pricing_term"),
+			},
+			{
+				Name:        "pricing_unit",
+				Description: "The pricing unit that AWS used to calculate the usage cost.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.pricing_unit"),
+			},
+			{
+				Name:        "pricing_public_on_demand_rate",
+				Description: "The public On-Demand rate for the usage.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Fields.pricing_public_on_demand_rate"),
+			},
+			{
+				Name:        "pricing_public_on_demand_cost",
+				Description: "The total cost of the line item based on public On-Demand rates.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Fields.pricing_public_on_demand_cost"),
+			},
+			{
+				Name:        "reservation_reservation_arn",
+				Description: "The ARN of the Reserved Instance that the line item benefited from.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.reservation_reservation_arn"),
+			},
+			{
+				Name:        "reservation_effective_cost",
+				Description: "The sum of the upfront and hourly rate of the Reserved Instance, averaged into an effective hourly rate.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Fields.reservation_effective_cost"),
+			},
+			{
+				Name:        "savings_plan_savings_plan_arn",
+				Description: "The ARN of the Savings Plan that the line item benefited from.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.savings_plan_savings_plan_arn"),
+			},
+			{
+				Name:        "savings_plan_savings_plan_effective_cost",
+				Description: "The proportion of the Savings Plan monthly commitment amount allocated to each usage line.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.FromField("Fields.savings_plan_savings_plan_effective_cost"),
+			},
+			{
+				Name:        "resource_tags",
+				Description: "The user-defined and AWS-generated cost allocation tags of the line item.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "cost_category",
+				Description: "The cost category values of the line item.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "line_item",
+				Description: "Every column of the line item, keyed by its snake case name.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Fields"),
+			},
+			{
+				Name:        "source",
+				Description: "The S3 key or local file the line item was read from.",
+				Type:        proto.ColumnType_STRING,
+			},
+
+			// Quals columns - to locate the report files
+			{
+				Name:        "bucket_name",
+				Description: "The name of the S3 bucket the report is delivered to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("bucket_name"),
+			},
+			{
+				Name:        "prefix",
+				Description: "The key prefix of the report in the S3 bucket, e.g. cur/my-report.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("prefix"),
+			},
+			{
+				Name:        "path",
+				Description: "A local report file, or a directory that contains report files. It must be within one of the local_file_paths of the connection config.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("path"),
+			},
+		}),
+	}
+}
+
+type costUsageReportRow struct {
+	Fields       map[string]string
+	ResourceTags map[string]string
+	CostCategory map[string]string
+	Source       string
+}
+
+var (
+	// Legacy reports are written to <prefix>/<report>/<yyyymmdd>-<yyyymmdd>/...
+	curLegacyPeriodRegex = regexp.MustCompile(`(?:^|/)(\d{8})-\d{8}(?:/|$)`)
+	// CUR 2.0 exports are written to <prefix>/<export>/data/BILLING_PERIOD=<yyyy-mm>/...
+	curExportPeriodRegex = regexp.MustCompile(`BILLING_PERIOD=(\d{4}-\d{2})`)
+)
+
+//// LIST FUNCTION
+
+func listCostUsageReportLineItems(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	var source *fileSource
+	if d.EqualsQualString("path") != "" {
+		var err error
+		source, err = newAllowedLocalFileSource(d, d.EqualsQualString("path"))
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_cost_usage_report.listCostUsageReportLineItems", "path_error", err)
+			return nil, err
+		}
+	} else {
+		// Bucket location will be nil if getBucketLocationForObjects returned an error but
+		// was ignored through ignore_error_codes config arg
+		location, err := getBucketLocationForObjects(ctx, d, h)
+		if err != nil {
+			return nil, err
+		} else if location == "" {
+			return nil, nil
+		}
+
+		svc, err := S3Client(ctx, d, fmt.Sprint(location))
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_cost_usage_report.listCostUsageReportLineItems", "client_error", err)
+			return nil, err
+		}
+		source = newS3FileSource(ctx, d, svc, d.EqualsQualString("bucket_name"), d.EqualsQualString("prefix"))
+	}
+
+	names, err := source.list()
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_usage_report.listCostUsageReportLineItems", "list_error", err)
+		return nil, err
+	}
+
+	files, err := selectCostUsageReportFiles(names, source)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_cost_usage_report.listCostUsageReportLineItems", "manifest_error", err)
+		return nil, err
+	}
+
+	for _, name := range files {
+		if !costUsageReportPeriodMatches(d, name) {
+			continue
+		}
+		done, err := streamCostUsageReportFile(ctx, d, source, name)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_cost_usage_report.listCostUsageReportLineItems", "read_error", err, "source", name)
+			return nil, err
+		}
+		if done {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// selectCostUsageReportFiles returns the data files to read. Legacy reports
+// keep every assembly of a billing period, so when a period has a manifest
+// only the report keys listed in it are read to avoid double counting.
+func selectCostUsageReportFiles(names []string, source *fileSource) ([]string, error) {
+	manifests := map[string]string{}
+	for _, name := range names {
+		if strings.HasSuffix(name, "-Manifest.json") && path.Dir(name) == costUsageReportPeriodDir(name) {
+			manifests[path.Dir(name)] = name
+		}
+	}
+
+	// Report keys in the manifest are relative to the bucket, so match them
+	// from the billing period onwards to also support local copies
+	current := map[string]bool{}
+	for _, manifest := range manifests {
+		keys, err := readCostUsageReportManifest(source, manifest)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			current[costUsageReportPeriodKey(key)] = true
+		}
+	}
+
+	var files []string
+	for _, name := range names {
+		if !isCostUsageReportDataFile(name) {
+			continue
+		}
+		if _, ok := manifests[costUsageReportPeriodDir(name)]; ok && !current[costUsageReportPeriodKey(name)] {
+			continue
+		}
+		files = append(files, name)
+	}
+
+	return files, nil
+}
+
+func readCostUsageReportManifest(source *fileSource, name string) ([]string, error) {
+	body, err := source.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var manifest struct {
+		ReportKeys []string `json:"reportKeys"`
+	}
+	if err := json.NewDecoder(body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %v", name, err)
+	}
+	return manifest.ReportKeys, nil
+}
+
+func isCostUsageReportDataFile(name string) bool {
+	for _, suffix := range []string{".csv", ".csv.gz", ".csv.zip", ".parquet"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// costUsageReportPeriodDir returns the key of the <yyyymmdd>-<yyyymmdd>
+// directory of a legacy report file.
+func costUsageReportPeriodDir(name string) string {
+	loc := curLegacyPeriodRegex.FindStringSubmatchIndex(name)
+	if loc == nil {
+		return ""
+	}
+	return strings.TrimSuffix(name[:loc[1]], "/")
+}
+
+// costUsageReportPeriodKey returns the part of a legacy report key that
+// starts at the billing period directory, e.g.
+// 20240101-20240201/<assembly id>/report-00001.csv.gz.
+func costUsageReportPeriodKey(name string) string {
+	loc := curLegacyPeriodRegex.FindStringSubmatchIndex(name)
+	if loc == nil {
+		return name
+	}
+	return name[loc[2]:]
+}
+
+// costUsageReportPeriodStart returns the billing period start encoded in a
+// report key, if any.
+func costUsageReportPeriodStart(name string) (time.Time, bool) {
+	if match := curLegacyPeriodRegex.FindStringSubmatch(name); match != nil {
+		t, err := time.Parse("20060102", match[1])
+		return t, err == nil
+	}
+	if match := curExportPeriodRegex.FindStringSubmatch(name); match != nil {
+		t, err := time.Parse("2006-01", match[1])
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+// costUsageReportPeriodMatches prunes files whose billing period, taken from
+// the key layout, cannot satisfy the bill_billing_period_start_date quals.
+func costUsageReportPeriodMatches(d *plugin.QueryData, name string) bool {
+	periodStart, ok := costUsageReportPeriodStart(name)
+	if !ok || d.Quals["bill_billing_period_start_date"] == nil {
+		return true
+	}
+
+	for _, q := range d.Quals["bill_billing_period_start_date"].Quals {
+		value := q.Value.GetTimestampValue().AsTime().UTC()
+		switch q.Operator {
+		case "=":
+			if !periodStart.Equal(value) {
+				return false
+			}
+		case ">":
+			if !periodStart.After(value) {
+				return false
+			}
+		case ">=":
+			if periodStart.Before(value) {
+				return false
+			}
+		case "<":
+			if !periodStart.Before(value) {
+				return false
+			}
+		case "<=":
+			if periodStart.After(value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// streamCostUsageReportFile streams the line items of a single report file,
+// which is CSV, optionally GZIP or ZIP compressed, or Parquet. It returns
+// true once no more rows are required.
+func streamCostUsageReportFile(ctx context.Context, d *plugin.QueryData, source *fileSource, name string) (bool, error) {
+	payerAccount := d.EqualsQualString("bill_payer_account_id")
+	usageAccount := d.EqualsQualString("line_item_usage_account_id")
+
+	done := false
+	stream := func(row costUsageReportRow) bool {
+		if payerAccount != "" && row.Fields["bill_payer_account_id"] != payerAccount {
+			return true
+		}
+		if usageAccount != "" && row.Fields["line_item_usage_account_id"] != usageAccount {
+			return true
+		}
+
+		d.StreamListItem(ctx, row)
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		done = d.RowsRemaining(ctx) == 0
+		return !done
+	}
+
+	switch {
+	case strings.HasSuffix(name, ".parquet"):
+		err := readParquetFile(source, name, func(record map[string]interface{}) bool {
+			return stream(costUsageReportParquetRow(record, name))
+		})
+		return done, err
+
+	case strings.HasSuffix(name, ".zip"):
+		body, size, err := source.openAt(name)
+		if err != nil {
+			return false, err
+		}
+		defer body.Close()
+
+		archive, err := zip.NewReader(body, size)
+		if err != nil {
+			return false, err
+		}
+		for _, file := range archive.File {
+			if !strings.HasSuffix(file.Name, ".csv") {
+				continue
+			}
+			reader, err := file.Open()
+			if err != nil {
+				return false, err
+			}
+			err = readCostUsageReportCSV(reader, name, stream)
+			reader.Close()
+			if err != nil || done {
+				return done, err
+			}
+		}
+		return done, nil
+	}
+
+	reader, err := source.openDecompressed(name)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	err = readCostUsageReportCSV(reader, name, stream)
+	return done, err
+}
+
+// readCostUsageReportCSV reads the line items of a CSV report until stream
+// returns false
+func readCostUsageReportCSV(reader io.Reader, name string, stream func(row costUsageReportRow) bool) error {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	csvReader.ReuseRecord = true
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns := make([]string, len(header))
+	for i, h := range header {
+		columns[i] = snakeCase(h)
+	}
+
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		row := costUsageReportRow{
+			Fields:       map[string]string{},
+			ResourceTags: map[string]string{},
+			CostCategory: map[string]string{},
+			Source:       name,
+		}
+		for i, value := range record {
+			if i >= len(columns) || value == "" {
+				continue
+			}
+			setCostUsageReportField(&row, header[i], columns[i], value)
+		}
+
+		if !stream(row) {
+			return nil
+		}
+	}
+}
+
+// costUsageReportParquetRow converts a record of a Parquet report. Legacy
+// reports have one column per tag or cost category, e.g.
+// resource_tags_user_name, while CUR 2.0 exports a single map column.
+func costUsageReportParquetRow(record map[string]interface{}, name string) costUsageReportRow {
+	row := costUsageReportRow{
+		Fields:       map[string]string{},
+		ResourceTags: map[string]string{},
+		CostCategory: map[string]string{},
+		Source:       name,
+	}
+	for column, value := range record {
+		if value == nil {
+			continue
+		}
+		switch {
+		case column == "resource_tags" || column == "cost_category":
+			values, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			target := row.ResourceTags
+			if column == "cost_category" {
+				target = row.CostCategory
+			}
+			for key, value := range values {
+				target[key] = parquetString(value)
+			}
+		default:
+			text := parquetString(value)
+			if text == "" {
+				continue
+			}
+			switch {
+			case strings.HasPrefix(column, "resource_tags_"):
+				row.ResourceTags[strings.TrimPrefix(column, "resource_tags_")] = text
+			case strings.HasPrefix(column, "cost_category_"):
+				row.CostCategory[strings.TrimPrefix(column, "cost_category_")] = text
+			default:
+				row.Fields[column] = text
+			}
+		}
+	}
+	return row
+}
+
+func setCostUsageReportField(row *costUsageReportRow, header string, column string, value string) {
+	// Legacy reports have one column per tag or cost category, e.g.
+	// resourceTags/user:Name, while CUR 2.0 exports a single map column.
+	switch {
+	case strings.HasPrefix(header, "resourceTags/"):
+		row.ResourceTags[strings.TrimPrefix(header, "resourceTags/")] = value
+	case strings.HasPrefix(header, "costCategory/"):
+		row.CostCategory[strings.TrimPrefix(header, "costCategory/")] = value
+	case column == "resource_tags":
+		_ = json.Unmarshal([]byte(value), &row.ResourceTags)
+	case column == "cost_category":
+		_ = json.Unmarshal([]byte(value), &row.CostCategory)
+	default:
+		row.Fields[column] = value
+	}
+}
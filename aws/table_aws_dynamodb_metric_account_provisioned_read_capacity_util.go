@@ -9,20 +9,12 @@ import (
 //// TABLE DEFINITION
 
 func tableAwsDynamoDBMetricAccountProvisionedReadCapacityUtilization(_ context.Context) *plugin.Table {
-	return &plugin.Table{
+	return cwMetricTable(cwMetricTableDefinition{
 		Name:        "aws_dynamodb_metric_account_provisioned_read_capacity_util",
 		Description: "AWS DynamoDB Metric Account Provisioned Read Capacity Utilization",
-		List: &plugin.ListConfig{
-			Hydrate: listDynamoDBMetricAccountProvisionedReadCapacityUtilization,
-			Tags:    map[string]string{"service": "cloudwatch", "action": "GetMetricStatistics"},
-		},
-		GetMatrixItemFunc: CloudWatchRegionsMatrix,
-		Columns:           awsRegionalColumns(cwMetricColumns([]*plugin.Column{})),
-	}
-}
-
-//// LIST FUNCTION
-
-func listDynamoDBMetricAccountProvisionedReadCapacityUtilization(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	return listCWMetricStatistics(ctx, d, "5_MIN", "AWS/DynamoDB", "AccountProvisionedReadCapacityUtilization", "", "")
+		Namespace:   "AWS/DynamoDB",
+		MetricName:  "AccountProvisionedReadCapacityUtilization",
+		Unit:        "Percent",
+		Granularity: "5_MIN",
+	})
 }
@@ -4,36 +4,29 @@ import (
 	"context"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
-	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
-	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
 //// TABLE DEFINITION
 
 func tableAwsEbsVolumeMetricWriteOpsDaily(_ context.Context) *plugin.Table {
-	return &plugin.Table{
-		Name:        "aws_ebs_volume_metric_write_ops_daily",
-		Description: "AWS EBS Volume Cloudwatch Metrics - Write Ops (Daily)",
-		List: &plugin.ListConfig{
-			ParentHydrate: listEBSVolume,
-			Hydrate:       listEbsVolumeMetricWriteOpsDaily,
-			Tags:          map[string]string{"service": "cloudwatch", "action": "GetMetricStatistics"},
+	return cwMetricTable(cwMetricTableDefinition{
+		Name:          "aws_ebs_volume_metric_write_ops_daily",
+		Description:   "AWS EBS Volume Cloudwatch Metrics - Write Ops (Daily)",
+		Namespace:     "AWS/EBS",
+		MetricName:    "VolumeWriteOps",
+		Unit:          "Count",
+		Granularity:   "DAILY",
+		DimensionName: "VolumeId",
+		DimensionColumn: &plugin.Column{
+			Name:        "volume_id",
+			Description: "The EBS Volume ID.",
 		},
-		GetMatrixItemFunc: CloudWatchRegionsMatrix,
-		Columns: awsRegionalColumns(cwMetricColumns(
-			[]*plugin.Column{
-				{
-					Name:        "volume_id",
-					Description: "The EBS Volume ID.",
-					Type:        proto.ColumnType_STRING,
-					Transform:   transform.FromField("DimensionValue"),
-				},
-			})),
-	}
-}
-
-func listEbsVolumeMetricWriteOpsDaily(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	volume := h.Item.(types.Volume)
-	return listCWMetricStatistics(ctx, d, "DAILY", "AWS/EBS", "VolumeWriteOps", "VolumeId", *volume.VolumeId)
+		ParentHydrate: listEBSVolume,
+		DimensionValue: func(item interface{}) string {
+			volume := item.(types.Volume)
+			return *volume.VolumeId
+		},
+	})
 }
@@ -0,0 +1,92 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsEc2ApplicationLoadBalancerAccessLog(_ context.Context) *plugin.Table {
+	return accessLogTable(accessLogDefinition{
+		Name:        "aws_ec2_application_load_balancer_access_log",
+		Description: "AWS EC2 Application Load Balancer Access Log",
+		Parse:       parseApplicationLoadBalancerAccessLog,
+		FilterFields: map[string]string{
+			"type":            "",
+			"elb":             "",
+			"elb_status_code": "",
+		},
+		Columns: []*plugin.Column{
+			{Name: "type", Type: proto.ColumnType_STRING, Description: "The type of request or connection. Possible values are: http|https|h2|grpcs|ws|wss."},
+			{Name: "elb", Type: proto.ColumnType_STRING, Description: "The resource ID of the load balancer, e.g. app/my-loadbalancer/50dc6c495c0c9188."},
+			{Name: "client_ip", Type: proto.ColumnType_IPADDR, Description: "The IP address of the requesting client."},
+			{Name: "client_port", Type: proto.ColumnType_INT, Description: "The port of the requesting client."},
+			{Name: "target_ip", Type: proto.ColumnType_IPADDR, Description: "The IP address of the target that processed the request."},
+			{Name: "target_port", Type: proto.ColumnType_INT, Description: "The port of the target that processed the request."},
+			{Name: "request_processing_time", Type: proto.ColumnType_DOUBLE, Description: "The total time elapsed, in seconds, from the time the load balancer received the request until the time it sent the request to a target. The value is -1 if the load balancer can't dispatch the request to a target."},
+			{Name: "target_processing_time", Type: proto.ColumnType_DOUBLE, Description: "The total time elapsed, in seconds, from the time the load balancer sent the request to a target until the target started to send the response headers. The value is -1 if the target closed the connection or did not respond."},
+			{Name: "response_processing_time", Type: proto.ColumnType_DOUBLE, Description: "The total time elapsed, in seconds, from the time the load balancer received the response header from the target until it started to send the response to the client. The value is -1 if the load balancer did not receive a response from a target."},
+			{Name: "elb_status_code", Type: proto.ColumnType_INT, Description: "The status code of the response from the load balancer."},
+			{Name: "target_status_code", Type: proto.ColumnType_INT, Description: "The status code of the response from the target."},
+			{Name: "received_bytes", Type: proto.ColumnType_INT, Description: "The size of the request, in bytes, received from the client."},
+			{Name: "sent_bytes", Type: proto.ColumnType_INT, Description: "The size of the response, in bytes, sent to the client."},
+			{Name: "request", Type: proto.ColumnType_STRING, Description: "The request line from the client, in the format HTTP method + protocol://host:port/uri + HTTP version."},
+			{Name: "request_method", Type: proto.ColumnType_STRING, Description: "The HTTP method of the request."},
+			{Name: "request_url", Type: proto.ColumnType_STRING, Description: "The URL of the request."},
+			{Name: "request_http_version", Type: proto.ColumnType_STRING, Description: "The HTTP version of the request."},
+			{Name: "user_agent", Type: proto.ColumnType_STRING, Description: "The User-Agent string that identifies the client that originated the request."},
+			{Name: "ssl_cipher", Type: proto.ColumnType_STRING, Description: "The SSL cipher of HTTPS listeners."},
+			{Name: "ssl_protocol", Type: proto.ColumnType_STRING, Description: "The SSL protocol of HTTPS listeners."},
+			{Name: "target_group_arn", Type: proto.ColumnType_STRING, Description: "The Amazon Resource Name (ARN) of the target group."},
+			{Name: "trace_id", Type: proto.ColumnType_STRING, Description: "The contents of the X-Amzn-Trace-Id header."},
+			{Name: "domain_name", Type: proto.ColumnType_STRING, Description: "The SNI domain provided by the client during the TLS handshake."},
+			{Name: "chosen_cert_arn", Type: proto.ColumnType_STRING, Description: "The ARN of the certificate presented to the client."},
+			{Name: "matched_rule_priority", Type: proto.ColumnType_INT, Description: "The priority value of the rule that matched the request. The value is 0 for the default rule."},
+			{Name: "request_creation_time", Type: proto.ColumnType_TIMESTAMP, Description: "The time when the load balancer received the request from the client."},
+			{Name: "actions_executed", Type: proto.ColumnType_STRING, Description: "The comma-separated actions taken when processing the request, e.g. waf,forward."},
+			{Name: "redirect_url", Type: proto.ColumnType_STRING, Description: "The URL of the redirect target for the location header of the HTTP response."},
+			{Name: "error_reason", Type: proto.ColumnType_STRING, Description: "The error reason code, if the request failed."},
+			{Name: "target_port_list", Type: proto.ColumnType_STRING, Description: "The space-delimited list of IP addresses and ports for the targets that processed the request."},
+			{Name: "target_status_code_list", Type: proto.ColumnType_STRING, Description: "The space-delimited list of status codes from the responses of the targets."},
+			{Name: "classification", Type: proto.ColumnType_STRING, Description: "The classification for desync mitigation. Possible values are: Acceptable|Ambiguous|Severe."},
+			{Name: "classification_reason", Type: proto.ColumnType_STRING, Description: "The classification reason code, if the request is not compliant with RFC 7230."},
+		},
+	})
+}
+
+//// PARSE FUNCTIONS
+
+// The fields of an ALB access log record, in order
+var applicationLoadBalancerAccessLogFieldNames = []string{
+	"type", "time", "elb", "client", "target",
+	"request_processing_time", "target_processing_time", "response_processing_time",
+	"elb_status_code", "target_status_code", "received_bytes", "sent_bytes",
+	"request", "user_agent", "ssl_cipher", "ssl_protocol", "target_group_arn",
+	"trace_id", "domain_name", "chosen_cert_arn", "matched_rule_priority",
+	"request_creation_time", "actions_executed", "redirect_url", "error_reason",
+	"target_port_list", "target_status_code_list", "classification", "classification_reason",
+}
+
+func parseApplicationLoadBalancerAccessLog(line string) (map[string]interface{}, time.Time, bool) {
+	values := splitAccessLogLine(line)
+	if len(values) < 13 {
+		return nil, time.Time{}, false
+	}
+
+	fields := accessLogFields(applicationLoadBalancerAccessLogFieldNames, values)
+	timestamp, err := time.Parse(time.RFC3339Nano, values[1])
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	delete(fields, "time")
+
+	splitAccessLogAddress(fields, "client", "client_ip", "client_port")
+	splitAccessLogAddress(fields, "target", "target_ip", "target_port")
+	splitAccessLogRequest(fields, "request", "request_")
+
+	return fields, timestamp, true
+}
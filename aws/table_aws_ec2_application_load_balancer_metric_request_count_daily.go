@@ -6,37 +6,29 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
 
-	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
-	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
 //// TABLE DEFINITION
 
 func tableAwsEc2ApplicationLoadBalancerMetricRequestCountDaily(_ context.Context) *plugin.Table {
-	return &plugin.Table{
-		Name:        "aws_ec2_application_load_balancer_metric_request_count_daily",
-		Description: "AWS EC2 Application Load Balancer Metrics - Request Count (Daily)",
-		List: &plugin.ListConfig{
-			ParentHydrate: listEc2ApplicationLoadBalancers,
-			Hydrate:       listEc2ApplicationLoadBalancerMetricRequestCountDaily,
-			Tags:          map[string]string{"service": "cloudwatch", "action": "GetMetricStatistics"},
+	return cwMetricTable(cwMetricTableDefinition{
+		Name:          "aws_ec2_application_load_balancer_metric_request_count_daily",
+		Description:   "AWS EC2 Application Load Balancer Metrics - Request Count (Daily)",
+		Namespace:     "AWS/ApplicationELB",
+		MetricName:    "RequestCount",
+		Unit:          "Count",
+		Granularity:   "DAILY",
+		DimensionName: "LoadBalancer",
+		DimensionColumn: &plugin.Column{
+			Name:        "name",
+			Description: "The friendly name of the Load Balancer that was provided during resource creation.",
 		},
-		GetMatrixItemFunc: CloudWatchRegionsMatrix,
-		Columns: awsRegionalColumns(cwMetricColumns(
-			[]*plugin.Column{
-				{
-					Name:        "name",
-					Description: "The friendly name of the Load Balancer that was provided during resource creation.",
-					Type:        proto.ColumnType_STRING,
-					Transform:   transform.FromField("DimensionValue"),
-				},
-			})),
-	}
-}
-
-func listEc2ApplicationLoadBalancerMetricRequestCountDaily(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	loadBalancer := h.Item.(types.LoadBalancer)
-	arn := strings.SplitN(*loadBalancer.LoadBalancerArn, "/", 2)[1]
-	return listCWMetricStatistics(ctx, d, "DAILY", "AWS/ApplicationELB", "RequestCount", "LoadBalancer", arn)
+		ParentHydrate: listEc2ApplicationLoadBalancers,
+		DimensionValue: func(item interface{}) string {
+			loadBalancer := item.(types.LoadBalancer)
+			arn := strings.SplitN(*loadBalancer.LoadBalancerArn, "/", 2)[1]
+			return arn
+		},
+	})
 }
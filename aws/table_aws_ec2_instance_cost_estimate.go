@@ -0,0 +1,357 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	pricingTypes "github.com/aws/aws-sdk-go-v2/service/pricing/types"
+
+	ec2v1 "github.com/aws/aws-sdk-go/service/ec2"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// Hours used for the estimate when no hours qual is given, the average
+// number of hours in a month.
+const ec2InstanceCostEstimateDefaultHours = 730
+
+//// TABLE DEFINITION
+
+func tableAwsEc2InstanceCostEstimate(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_ec2_instance_cost_estimate",
+		Description: "AWS EC2 Instance Cost Estimate",
+		List: &plugin.ListConfig{
+			Hydrate: listEc2InstanceCostEstimates,
+			Tags:    map[string]string{"service": "ec2", "action": "DescribeInstances"},
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "instance_id", Require: plugin.Optional},
+				{Name: "instance_type", Require: plugin.Optional},
+				{Name: "hours", Require: plugin.Optional, CacheMatch: "exact"},
+			},
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func: getEc2InstanceOnDemandPrice,
+				Tags: map[string]string{"service": "pricing", "action": "GetProducts"},
+			},
+		},
+		GetMatrixItemFunc: SupportedRegionMatrix(ec2v1.EndpointsID),
+		Columns: awsRegionalColumns([]*plugin.Column{
+			{
+				Name:        "instance_id",
+				Description: "The ID of the instance.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "instance_type",
+				Description: "The instance type.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "instance_lifecycle",
+				Description: "Indicates whether this is a Spot Instance or a Scheduled Instance. The estimate uses On-Demand prices, so it overstates the cost of Spot Instances.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "tenancy",
+				Description: "The tenancy of the instance (if the instance is running in a VPC). An instance with a tenancy of dedicated runs on single-tenant hardware.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Placement.Tenancy"),
+			},
+			{
+				Name:        "platform_details",
+				Description: "The platform details value for the instance, e.g. Linux/UNIX or Windows with SQL Server Standard.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "usage_operation",
+				Description: "The usage operation value for the instance, which identifies the operating system and license of the instance in the price list.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "operating_system",
+				Description: "The operating system of the matched price list product.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getEc2InstanceOnDemandPrice,
+			},
+			{
+				Name:        "license_model",
+				Description: "The license model of the matched price list product.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getEc2InstanceOnDemandPrice,
+			},
+			{
+				Name:        "pre_installed_sw",
+				Description: "The pre-installed software of the matched price list product, e.g. SQL Std.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getEc2InstanceOnDemandPrice,
+			},
+			{
+				Name:        "hourly_price",
+				Description: "The On-Demand price per hour of the instance.",
+				Type:        proto.ColumnType_DOUBLE,
+				Hydrate:     getEc2InstanceOnDemandPrice,
+			},
+			{
+				Name:        "currency",
+				Description: "The currency of the price.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getEc2InstanceOnDemandPrice,
+			},
+			{
+				Name:        "hours",
+				Description: "The number of hours the estimate is for. Defaults to 730, the average number of hours in a month.",
+				Type:        proto.ColumnType_DOUBLE,
+				Transform:   transform.From(ec2InstanceCostEstimateHours),
+			},
+			{
+				Name:        "estimated_cost",
+				Description: "The On-Demand price per hour multiplied by hours.",
+				Type:        proto.ColumnType_DOUBLE,
+				Hydrate:     getEc2InstanceOnDemandPrice,
+				Transform:   transform.FromValue().Transform(ec2InstanceEstimatedCost),
+			},
+			{
+				Name:        "launch_time",
+				Description: "The time the instance was launched.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "tags_src",
+				Description: "A list of tags assigned to the instance.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Tags"),
+			},
+
+			// Steampipe standard columns
+			{
+				Name:        "title",
+				Description: resourceInterfaceDescription("title"),
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("InstanceId"),
+			},
+			{
+				Name:        "tags",
+				Description: resourceInterfaceDescription("tags"),
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.From(getEc2InstanceTurbotTags),
+			},
+		}),
+	}
+}
+
+type ec2InstanceOnDemandPrice struct {
+	OperatingSystem *string
+	LicenseModel    *string
+	PreInstalledSw  *string
+	HourlyPrice     *float64
+	Currency        *string
+}
+
+//// LIST FUNCTION
+
+func listEc2InstanceCostEstimates(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// Create Session
+	svc, err := EC2Client(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_ec2_instance_cost_estimate.listEc2InstanceCostEstimates", "connection_error", err)
+		return nil, err
+	}
+
+	// Only running instances are charged for compute
+	input := &ec2.DescribeInstancesInput{
+		MaxResults: aws.Int32(1000),
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: []string{"running"},
+			},
+		},
+	}
+	if d.EqualsQualString("instance_id") != "" {
+		input.Filters = append(input.Filters, types.Filter{
+			Name:   aws.String("instance-id"),
+			Values: []string{d.EqualsQualString("instance_id")},
+		})
+	}
+	if d.EqualsQualString("instance_type") != "" {
+		input.Filters = append(input.Filters, types.Filter{
+			Name:   aws.String("instance-type"),
+			Values: []string{d.EqualsQualString("instance_type")},
+		})
+	}
+
+	paginator := ec2.NewDescribeInstancesPaginator(svc, input, func(o *ec2.DescribeInstancesPaginatorOptions) {
+		o.Limit = 1000
+		o.StopOnDuplicateToken = true
+	})
+
+	// List call
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_ec2_instance_cost_estimate.listEc2InstanceCostEstimates", "api_error", err)
+			return nil, err
+		}
+
+		for _, items := range output.Reservations {
+			for _, instance := range items.Instances {
+				d.StreamListItem(ctx, instance)
+
+				// Context can be cancelled due to manual cancellation or the limit has been hit
+				if d.RowsRemaining(ctx) == 0 {
+					return nil, nil
+				}
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getEc2InstanceOnDemandPrice(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	instance := h.Item.(types.Instance)
+	region := d.EqualsQualString(matrixKeyRegion)
+
+	tenancy := "Shared"
+	if instance.Placement != nil {
+		switch instance.Placement.Tenancy {
+		case types.TenancyDedicated:
+			tenancy = "Dedicated"
+		case types.TenancyHost:
+			tenancy = "Host"
+		}
+	}
+
+	// Prices rarely change, so they are cached for the connection rather than
+	// looked up again for every instance and query
+	cacheKey := "getEc2InstanceOnDemandPrice" + region + string(instance.InstanceType) + tenancy + aws.ToString(instance.UsageOperation)
+	if cachedData, ok := d.ConnectionManager.Cache.Get(cacheKey); ok {
+		return cachedData.(*ec2InstanceOnDemandPrice), nil
+	}
+
+	svc, err := PricingClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_ec2_instance_cost_estimate.getEc2InstanceOnDemandPrice", "connection_error", err)
+		return nil, err
+	}
+	if svc == nil {
+		// Unsupported region, return no data
+		return nil, nil
+	}
+
+	// The usage operation, e.g. RunInstances:0002 for Windows, identifies the
+	// operating system, license model and pre-installed software together
+	input := &pricing.GetProductsInput{
+		ServiceCode:   aws.String("AmazonEC2"),
+		FormatVersion: aws.String("aws_v1"),
+		Filters: []pricingTypes.Filter{
+			{Field: aws.String("instanceType"), Type: pricingTypes.FilterTypeTermMatch, Value: aws.String(string(instance.InstanceType))},
+			{Field: aws.String("regionCode"), Type: pricingTypes.FilterTypeTermMatch, Value: aws.String(region)},
+			{Field: aws.String("tenancy"), Type: pricingTypes.FilterTypeTermMatch, Value: aws.String(tenancy)},
+			{Field: aws.String("operation"), Type: pricingTypes.FilterTypeTermMatch, Value: instance.UsageOperation},
+			{Field: aws.String("capacitystatus"), Type: pricingTypes.FilterTypeTermMatch, Value: aws.String("Used")},
+		},
+	}
+
+	paginator := pricing.NewGetProductsPaginator(svc, input, func(o *pricing.GetProductsPaginatorOptions) {
+		o.StopOnDuplicateToken = true
+	})
+
+	price := &ec2InstanceOnDemandPrice{}
+	for paginator.HasMorePages() && price.HourlyPrice == nil {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_ec2_instance_cost_estimate.getEc2InstanceOnDemandPrice", "api_error", err)
+			return nil, err
+		}
+
+		for _, item := range output.PriceList {
+			var priceList PriceList
+			if err := json.Unmarshal([]byte(item), &priceList); err != nil {
+				plugin.Logger(ctx).Error("aws_ec2_instance_cost_estimate.getEc2InstanceOnDemandPrice", "unmarshal_err", err)
+				return nil, err
+			}
+			if setEc2InstanceOnDemandPrice(price, priceList) {
+				break
+			}
+		}
+	}
+
+	// Only a found price is cached, so that an instance type missing from the
+	// price list is looked up again by the next query
+	if price.HourlyPrice != nil {
+		d.ConnectionManager.Cache.SetWithTTL(cacheKey, price, 24*time.Hour)
+	}
+
+	return price, nil
+}
+
+// setEc2InstanceOnDemandPrice sets the hourly On-Demand price from a price
+// list product. It returns false if the product has no hourly On-Demand price.
+func setEc2InstanceOnDemandPrice(price *ec2InstanceOnDemandPrice, priceList PriceList) bool {
+	if priceList.Terms["OnDemand"] == nil {
+		return false
+	}
+
+	for _, offer := range *priceList.Terms["OnDemand"] {
+		for _, priceDimension := range offer.PriceDimensions {
+			if aws.ToString(priceDimension.Unit) != "Hrs" {
+				continue
+			}
+			for currency, value := range priceDimension.PricePerUnit {
+				hourlyPrice, err := strconv.ParseFloat(aws.ToString(value), 64)
+				if err != nil {
+					continue
+				}
+				price.HourlyPrice = aws.Float64(hourlyPrice)
+				price.Currency = aws.String(currency)
+				if priceList.Product != nil {
+					price.OperatingSystem = priceList.Product.Attributes["operatingSystem"]
+					price.LicenseModel = priceList.Product.Attributes["licenseModel"]
+					price.PreInstalledSw = priceList.Product.Attributes["preInstalledSw"]
+				}
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//// TRANSFORM FUNCTIONS
+
+func ec2InstanceCostEstimateHours(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	for _, q := range d.KeyColumnQuals["hours"] {
+		if q.Operator == "=" {
+			return q.Value.GetDoubleValue(), nil
+		}
+	}
+	return float64(ec2InstanceCostEstimateDefaultHours), nil
+}
+
+func ec2InstanceEstimatedCost(ctx context.Context, d *transform.TransformData) (interface{}, error) {
+	price, ok := d.Value.(*ec2InstanceOnDemandPrice)
+	if !ok || price == nil || price.HourlyPrice == nil {
+		return nil, nil
+	}
+
+	hours, err := ec2InstanceCostEstimateHours(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	return *price.HourlyPrice * hours.(float64), nil
+}
@@ -0,0 +1,88 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsEc2NetworkLoadBalancerAccessLog(_ context.Context) *plugin.Table {
+	return accessLogTable(accessLogDefinition{
+		Name:        "aws_ec2_network_load_balancer_access_log",
+		Description: "AWS EC2 Network Load Balancer Access Log",
+		Parse:       parseNetworkLoadBalancerAccessLog,
+		FilterFields: map[string]string{
+			"elb":                  "",
+			"listener":             "",
+			"tls_protocol_version": "",
+		},
+		Columns: []*plugin.Column{
+			{Name: "type", Type: proto.ColumnType_STRING, Description: "The type of listener. The value is always tls."},
+			{Name: "version", Type: proto.ColumnType_STRING, Description: "The version of the log entry."},
+			{Name: "elb", Type: proto.ColumnType_STRING, Description: "The resource ID of the load balancer, e.g. net/my-loadbalancer/1234567890abcdef."},
+			{Name: "listener", Type: proto.ColumnType_STRING, Description: "The resource ID of the TLS listener for the connection."},
+			{Name: "client_ip", Type: proto.ColumnType_IPADDR, Description: "The IP address of the client."},
+			{Name: "client_port", Type: proto.ColumnType_INT, Description: "The port of the client."},
+			{Name: "destination_ip", Type: proto.ColumnType_IPADDR, Description: "The IP address of the destination. If the client connects directly to the load balancer, the destination is the listener."},
+			{Name: "destination_port", Type: proto.ColumnType_INT, Description: "The port of the destination."},
+			{Name: "connection_time", Type: proto.ColumnType_INT, Description: "The total time for the connection to complete, from start to closure, in milliseconds."},
+			{Name: "tls_handshake_time", Type: proto.ColumnType_INT, Description: "The total time for the TLS handshake to complete after the TCP connection is established, in milliseconds."},
+			{Name: "received_bytes", Type: proto.ColumnType_INT, Description: "The count of bytes received by the load balancer from the client, after decryption."},
+			{Name: "sent_bytes", Type: proto.ColumnType_INT, Description: "The count of bytes sent by the load balancer to the client, before encryption."},
+			{Name: "incoming_tls_alert", Type: proto.ColumnType_STRING, Description: "The integer value of TLS alerts received by the load balancer from the client, if present."},
+			{Name: "chosen_cert_arn", Type: proto.ColumnType_STRING, Description: "The ARN of the certificate served to the client."},
+			{Name: "chosen_cert_serial", Type: proto.ColumnType_STRING, Description: "Reserved for future use. The value is always -."},
+			{Name: "tls_cipher", Type: proto.ColumnType_STRING, Description: "The cipher suite negotiated with the client, in OpenSSL format."},
+			{Name: "tls_protocol_version", Type: proto.ColumnType_STRING, Description: "The TLS protocol negotiated with the client, e.g. tlsv12."},
+			{Name: "tls_named_group", Type: proto.ColumnType_STRING, Description: "Reserved for future use. The value is always -."},
+			{Name: "domain_name", Type: proto.ColumnType_STRING, Description: "The value of the server_name extension in the client hello message."},
+			{Name: "alpn_fe_protocol", Type: proto.ColumnType_STRING, Description: "The application protocol negotiated with the client."},
+			{Name: "alpn_be_protocol", Type: proto.ColumnType_STRING, Description: "The application protocol that is negotiated with the target."},
+			{Name: "alpn_client_preference_list", Type: proto.ColumnType_STRING, Description: "The value of the application_layer_protocol_negotiation extension in the client hello message."},
+			{Name: "tls_connection_creation_time", Type: proto.ColumnType_TIMESTAMP, Description: "The time recorded at the beginning of the TLS connection."},
+		},
+	})
+}
+
+//// PARSE FUNCTIONS
+
+// The fields of an NLB access log record, in order
+var networkLoadBalancerAccessLogFieldNames = []string{
+	"type", "version", "time", "elb", "listener", "client", "destination",
+	"connection_time", "tls_handshake_time", "received_bytes", "sent_bytes",
+	"incoming_tls_alert", "chosen_cert_arn", "chosen_cert_serial", "tls_cipher",
+	"tls_protocol_version", "tls_named_group", "domain_name", "alpn_fe_protocol",
+	"alpn_be_protocol", "alpn_client_preference_list", "tls_connection_creation_time",
+}
+
+// NLB access logs record times in UTC without a time zone
+const networkLoadBalancerAccessLogTimeLayout = "2006-01-02T15:04:05"
+
+func parseNetworkLoadBalancerAccessLog(line string) (map[string]interface{}, time.Time, bool) {
+	values := splitAccessLogLine(line)
+	if len(values) < 11 {
+		return nil, time.Time{}, false
+	}
+
+	fields := accessLogFields(networkLoadBalancerAccessLogFieldNames, values)
+	timestamp, err := time.Parse(networkLoadBalancerAccessLogTimeLayout, values[2])
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	delete(fields, "time")
+
+	if created, ok := fields["tls_connection_creation_time"].(string); ok {
+		if t, err := time.Parse(networkLoadBalancerAccessLogTimeLayout, created); err == nil {
+			fields["tls_connection_creation_time"] = t
+		}
+	}
+
+	splitAccessLogAddress(fields, "client", "client_ip", "client_port")
+	splitAccessLogAddress(fields, "destination", "destination_ip", "destination_port")
+
+	return fields, timestamp, true
+}
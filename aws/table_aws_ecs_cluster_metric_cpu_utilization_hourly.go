@@ -5,37 +5,30 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
-	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
-	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
 //// TABLE DEFINITION
 
 func tableAwsEcsClusterMetricCpuUtilizationHourly(_ context.Context) *plugin.Table {
-	return &plugin.Table{
-		Name:        "aws_ecs_cluster_metric_cpu_utilization_hourly",
-		Description: "AWS ECS Cluster Cloudwatch Metrics - CPU Utilization (Hourly)",
-		List: &plugin.ListConfig{
-			ParentHydrate: listEcsClusters,
-			Hydrate:       listEcsClusterMetricCpuUtilizationHourly,
-			Tags:          map[string]string{"service": "cloudwatch", "action": "GetMetricStatistics"},
+	return cwMetricTable(cwMetricTableDefinition{
+		Name:          "aws_ecs_cluster_metric_cpu_utilization_hourly",
+		Description:   "AWS ECS Cluster Cloudwatch Metrics - CPU Utilization (Hourly)",
+		Namespace:     "AWS/ECS",
+		MetricName:    "CPUUtilization",
+		Unit:          "Percent",
+		Granularity:   "HOURLY",
+		DimensionName: "ClusterName",
+		DimensionColumn: &plugin.Column{
+			Name:        "cluster_name",
+			Description: "A user-generated string that you use to identify your cluster.",
 		},
-		GetMatrixItemFunc: CloudWatchRegionsMatrix,
-		Columns: awsRegionalColumns(cwMetricColumns(
-			[]*plugin.Column{
-				{
-					Name:        "cluster_name",
-					Description: "A user-generated string that you use to identify your cluster.",
-					Type:        proto.ColumnType_STRING,
-					Transform:   transform.FromField("DimensionValue"),
-				},
-			})),
-	}
-}
-
-func listEcsClusterMetricCpuUtilizationHourly(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	data := h.Item.(types.Cluster)
-	clusterName := strings.Split(*data.ClusterArn, "/")[1]
-	return listCWMetricStatistics(ctx, d, "HOURLY", "AWS/ECS", "CPUUtilization", "ClusterName", clusterName)
+		ParentHydrate: listEcsClusters,
+		DimensionValue: func(item interface{}) string {
+			data := item.(types.Cluster)
+			clusterName := strings.Split(*data.ClusterArn, "/")[1]
+			return clusterName
+		},
+	})
 }
@@ -4,36 +4,29 @@ import (
 	"context"
 
 	"github.com/aws/aws-sdk-go-v2/service/elasticache/types"
-	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
-	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
 //// TABLE DEFINITION
 
 func tableAwsElasticacheRedisEngineCPUUtilizationHourly(_ context.Context) *plugin.Table {
-	return &plugin.Table{
-		Name:        "aws_elasticache_redis_metric_engine_cpu_utilization_hourly",
-		Description: "AWS Elasticache Redis EngineCPUUtilization metric (Hourly)",
-		List: &plugin.ListConfig{
-			ParentHydrate: listElastiCacheClusters,
-			Hydrate:       listElastiCacheMetricEngineCPUUtilizationHourly,
-			Tags:          map[string]string{"service": "cloudwatch", "action": "GetMetricStatistics"},
+	return cwMetricTable(cwMetricTableDefinition{
+		Name:          "aws_elasticache_redis_metric_engine_cpu_utilization_hourly",
+		Description:   "AWS Elasticache Redis EngineCPUUtilization metric (Hourly)",
+		Namespace:     "AWS/ElastiCache",
+		MetricName:    "EngineCPUUtilization",
+		Unit:          "Percent",
+		Granularity:   "HOURLY",
+		DimensionName: "CacheClusterId",
+		DimensionColumn: &plugin.Column{
+			Name:        "cache_cluster_id",
+			Description: "The cache cluster id.",
 		},
-		GetMatrixItemFunc: CloudWatchRegionsMatrix,
-		Columns: awsRegionalColumns(cwMetricColumns(
-			[]*plugin.Column{
-				{
-					Name:        "cache_cluster_id",
-					Description: "The cache cluster id.",
-					Type:        proto.ColumnType_STRING,
-					Transform:   transform.FromField("DimensionValue"),
-				},
-			})),
-	}
-}
-
-func listElastiCacheMetricEngineCPUUtilizationHourly(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	cacheClusterConfiguration := h.Item.(types.CacheCluster)
-	return listCWMetricStatistics(ctx, d, "Hourly", "AWS/ElastiCache", "EngineCPUUtilization", "CacheClusterId", *cacheClusterConfiguration.CacheClusterId)
+		ParentHydrate: listElastiCacheClusters,
+		DimensionValue: func(item interface{}) string {
+			cacheClusterConfiguration := item.(types.CacheCluster)
+			return *cacheClusterConfiguration.CacheClusterId
+		},
+	})
 }
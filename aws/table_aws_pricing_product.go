@@ -39,7 +39,7 @@ func tableAwsPricingProduct(_ context.Context) *plugin.Table {
 			{Name: "begin_range", Description: "Start of billing range, by unit", Type: proto.ColumnType_STRING, Transform: transform.FromField("Offer.PriceDimension.BeginRange")},
 			{Name: "end_range", Description: "Enf of billing range, by unit", Type: proto.ColumnType_STRING, Transform: transform.FromField("Offer.PriceDimension.EndRange")},
 			{Name: "unit", Description: "The pricing unit that AWS used for calculating your usage cost (ex: hours)", Type: proto.ColumnType_STRING, Transform: transform.FromField("Offer.PriceDimension.Unit")},
-			{Name: "price_per_unit", Description: "Price by unit", Type: proto.ColumnType_STRING, Transform: transform.FromField("Offer.PriceDimension.PricePerUnit").Transform(extractPricePerUnit)},
+			{Name: "price_per_unit", Description: "Price by unit", Type: proto.ColumnType_DOUBLE, Transform: transform.FromField("Offer.PriceDimension.PricePerUnit").Transform(extractPricePerUnit)},
 			{Name: "currency", Description: "Currency used for the price", Type: proto.ColumnType_STRING, Transform: transform.FromField("Offer.PriceDimension.PricePerUnit").Transform(extractCurrency)},
 			{Name: "publication_date", Description: "The publication date of the offer.", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("PublicationDate")},
 			{Name: "effective_date", Description: "The effective date of the pricing details.", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("Offer.EffectiveDate")},
@@ -86,6 +86,24 @@ type Offer struct {
 	TermAttributes  *TermAttributes
 }
 
+type OfferOutput struct {
+	_              struct{} `type:"structure"`
+	PriceDimension *PriceDimension
+	Term           string
+	EffectiveDate  *time.Time
+	OfferTermCode  *string
+	TermAttributes *TermAttributes
+}
+
+type PriceOutput struct {
+	_               struct{} `type:"structure"`
+	Product         *Product
+	ServiceCode     *string
+	Offer           *OfferOutput
+	Version         *string
+	PublicationDate *time.Time
+}
+
 type PriceList struct {
 	_               struct{} `type:"structure"`
 	Product         *Product
@@ -96,6 +114,17 @@ type PriceList struct {
 }
 
 func listPricingProduct(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// Read bulk price list offer files instead of calling the Pricing API, if configured
+	awsSpcConfig := GetConfig(d.Connection)
+	if awsSpcConfig.PricingOfferPath != nil && *awsSpcConfig.PricingOfferPath != "" {
+		filters, err := buildPricingFilter(d.Quals["filters"])
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_pricing_product.listPricingProduct", "filters_building_error", err)
+			return nil, err
+		}
+		return listPricingProductFromOfferFiles(ctx, d, *awsSpcConfig.PricingOfferPath, filters)
+	}
+
 	// Create Session
 	svc, err := PricingClient(ctx, d)
 	if err != nil {
@@ -127,24 +156,6 @@ func listPricingProduct(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydr
 		MaxResults:    aws.Int32(maxItems),
 	}
 
-	type OfferOutput struct {
-		_              struct{} `type:"structure"`
-		PriceDimension *PriceDimension
-		Term           string
-		EffectiveDate  *time.Time
-		OfferTermCode  *string
-		TermAttributes *TermAttributes
-	}
-
-	type PriceOutput struct {
-		_               struct{} `type:"structure"`
-		Product         *Product
-		ServiceCode     *string
-		Offer           *OfferOutput
-		Version         *string
-		PublicationDate *time.Time
-	}
-
 	filters, err := buildPricingFilter(d.Quals["filters"])
 	if err != nil {
 		plugin.Logger(ctx).Error("aws_pricing_product.listPricingProduct", "filters_building_error", err)
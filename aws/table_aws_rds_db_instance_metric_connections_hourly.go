@@ -4,36 +4,29 @@ import (
 	"context"
 
 	"github.com/aws/aws-sdk-go-v2/service/rds/types"
-	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
-	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
 //// TABLE DEFINITION
 
 func tableAwsRdsInstanceMetricConnectionsHourly(_ context.Context) *plugin.Table {
-	return &plugin.Table{
-		Name:        "aws_rds_db_instance_metric_connections_hourly",
-		Description: "AWS RDS DB Instance Cloudwatch Metrics - DB Connections (Hourly)",
-		List: &plugin.ListConfig{
-			ParentHydrate: listRDSDBInstances,
-			Hydrate:       listRdsInstanceMetricConnectionsHourly,
-			Tags:          map[string]string{"service": "cloudwatch", "action": "GetMetricStatistics"},
+	return cwMetricTable(cwMetricTableDefinition{
+		Name:          "aws_rds_db_instance_metric_connections_hourly",
+		Description:   "AWS RDS DB Instance Cloudwatch Metrics - DB Connections (Hourly)",
+		Namespace:     "AWS/RDS",
+		MetricName:    "DatabaseConnections",
+		Unit:          "Count",
+		Granularity:   "HOURLY",
+		DimensionName: "DBInstanceIdentifier",
+		DimensionColumn: &plugin.Column{
+			Name:        "db_instance_identifier",
+			Description: "The friendly name to identify the DB Instance.",
 		},
-		GetMatrixItemFunc: CloudWatchRegionsMatrix,
-		Columns: awsRegionalColumns(cwMetricColumns(
-			[]*plugin.Column{
-				{
-					Name:        "db_instance_identifier",
-					Description: "The friendly name to identify the DB Instance.",
-					Type:        proto.ColumnType_STRING,
-					Transform:   transform.FromField("DimensionValue"),
-				},
-			})),
-	}
-}
-
-func listRdsInstanceMetricConnectionsHourly(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	instance := h.Item.(types.DBInstance)
-	return listCWMetricStatistics(ctx, d, "HOURLY", "AWS/RDS", "DatabaseConnections", "DBInstanceIdentifier", *instance.DBInstanceIdentifier)
+		ParentHydrate: listRDSDBInstances,
+		DimensionValue: func(item interface{}) string {
+			instance := item.(types.DBInstance)
+			return *instance.DBInstanceIdentifier
+		},
+	})
 }
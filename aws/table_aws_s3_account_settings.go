@@ -135,6 +135,19 @@ func getAccountBucketPublicAccessBlock(ctx context.Context, d *plugin.QueryData,
 	return accessBlock.PublicAccessBlockConfiguration, nil
 }
 
+// getS3AccountPublicAccessBlock returns the public access block of the
+// connection's account, for resources other than the account that it applies to.
+var getS3AccountPublicAccessBlock = plugin.HydrateFunc(getS3AccountPublicAccessBlockUncached).Memoize()
+
+func getS3AccountPublicAccessBlockUncached(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	commonData, err := getCommonColumns(ctx, d, h)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_account_settings.getS3AccountPublicAccessBlockUncached", "common_data_error", err)
+		return nil, err
+	}
+	return getAccountBucketPublicAccessBlock(ctx, d, &plugin.HydrateData{Item: commonData})
+}
+
 //// Transform Functions
 
 func s3AccountDataToAkas(ctx context.Context, d *transform.TransformData) (interface{}, error) {
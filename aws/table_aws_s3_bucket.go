@@ -6,6 +6,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	s3controlTypes "github.com/aws/aws-sdk-go-v2/service/s3control/types"
 	"github.com/aws/smithy-go"
 
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
@@ -99,6 +100,11 @@ func tableAwsS3Bucket(_ context.Context) *plugin.Table {
 				Depends: []plugin.HydrateFunc{getBucketLocation},
 				Tags:    map[string]string{"service": "s3", "action": "GetBucketWebsite"},
 			},
+			{
+				Func:    getBucketEffectivePublicAccess,
+				Depends: []plugin.HydrateFunc{getBucketACL, getBucketPolicy, getBucketPublicAccessBlock, getS3BucketObjectOwnershipControl},
+				Tags:    map[string]string{"service": "s3control", "action": "GetPublicAccessBlock"},
+			},
 		},
 		Columns: awsAccountColumns([]*plugin.Column{
 			{
@@ -168,6 +174,48 @@ func tableAwsS3Bucket(_ context.Context) *plugin.Table {
 				Hydrate:     getBucketPublicAccessBlock,
 				Transform:   transform.FromField("RestrictPublicBuckets"),
 			},
+			{
+				Name:        "public_read",
+				Description: "True if anyone can read objects in the bucket, through a public bucket policy that is not restricted by the bucket or account public access block. Object ACLs are not evaluated.",
+				Type:        proto.ColumnType_BOOL,
+				Hydrate:     getBucketEffectivePublicAccess,
+				Transform:   transform.FromField("PublicRead"),
+			},
+			{
+				Name:        "public_read_reasons",
+				Description: "The ACL grants and policy statements that make objects in the bucket publicly readable, or the settings that block them.",
+				Type:        proto.ColumnType_JSON,
+				Hydrate:     getBucketEffectivePublicAccess,
+				Transform:   transform.FromField("PublicReadReasons"),
+			},
+			{
+				Name:        "public_write",
+				Description: "True if anyone can write or delete objects in the bucket, through the bucket ACL or a public bucket policy, that is not blocked by the bucket or account public access block.",
+				Type:        proto.ColumnType_BOOL,
+				Hydrate:     getBucketEffectivePublicAccess,
+				Transform:   transform.FromField("PublicWrite"),
+			},
+			{
+				Name:        "public_write_reasons",
+				Description: "The ACL grants and policy statements that make the bucket publicly writable, or the settings that block them.",
+				Type:        proto.ColumnType_JSON,
+				Hydrate:     getBucketEffectivePublicAccess,
+				Transform:   transform.FromField("PublicWriteReasons"),
+			},
+			{
+				Name:        "public_list",
+				Description: "True if anyone can list the objects in the bucket, through the bucket ACL or a public bucket policy, that is not blocked by the bucket or account public access block.",
+				Type:        proto.ColumnType_BOOL,
+				Hydrate:     getBucketEffectivePublicAccess,
+				Transform:   transform.FromField("PublicList"),
+			},
+			{
+				Name:        "public_list_reasons",
+				Description: "The ACL grants and policy statements that make the bucket publicly listable, or the settings that block them.",
+				Type:        proto.ColumnType_JSON,
+				Hydrate:     getBucketEffectivePublicAccess,
+				Transform:   transform.FromField("PublicListReasons"),
+			},
 			{
 				Name:        "event_notification_configuration",
 				Description: "A container for specifying the notification configuration of the bucket. If this element is empty, notifications are turned off for the bucket.",
@@ -845,6 +893,63 @@ func getObjectLockConfiguration(ctx context.Context, d *plugin.QueryData, h *plu
 
 //// TRANSFORM FUNCTIONS
 
+// getBucketEffectivePublicAccess evaluates the public read, write and list
+// access to the bucket from its ACL, its policy, its object ownership and the
+// bucket and account public access blocks.
+func getBucketEffectivePublicAccess(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// Bucket location will be nil if getBucketLocation returned an error but
+	// was ignored through ignore_error_codes config arg
+	if h.HydrateResults["getBucketLocation"] == nil {
+		return nil, nil
+	}
+
+	bucket := h.Item.(types.Bucket)
+	settings := s3PublicAccessSettings{
+		BucketName: *bucket.Name,
+	}
+
+	if acl, ok := h.HydrateResults["getBucketACL"].(*map[string]any); ok && acl != nil {
+		if grants, ok := (*acl)["Grants"].([]types.Grant); ok {
+			settings.Grants = grants
+		}
+	}
+	if policy, ok := h.HydrateResults["getBucketPolicy"].(*s3.GetBucketPolicyOutput); ok && policy.Policy != nil {
+		settings.Policy = *policy.Policy
+	}
+	if ownership, ok := h.HydrateResults["getS3BucketObjectOwnershipControl"].(*types.OwnershipControls); ok && ownership != nil {
+		for _, rule := range ownership.Rules {
+			if rule.ObjectOwnership == types.ObjectOwnershipBucketOwnerEnforced {
+				settings.AclsDisabled = true
+			}
+		}
+	}
+	if accessBlock, ok := h.HydrateResults["getBucketPublicAccessBlock"].(*types.PublicAccessBlockConfiguration); ok && accessBlock != nil {
+		settings.BucketIgnorePublicAcls = accessBlock.IgnorePublicAcls
+		settings.BucketRestrictPublicBuckets = accessBlock.RestrictPublicBuckets
+	}
+
+	accountAccessBlock, err := getS3AccountPublicAccessBlock(ctx, d, h)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_bucket.getBucketEffectivePublicAccess", "account_public_access_block_error", err)
+		return nil, err
+	}
+	switch accessBlock := accountAccessBlock.(type) {
+	case *s3controlTypes.PublicAccessBlockConfiguration:
+		settings.AccountIgnorePublicAcls = accessBlock.IgnorePublicAcls
+		settings.AccountRestrictPublicBuckets = accessBlock.RestrictPublicBuckets
+	case *types.PublicAccessBlockConfiguration:
+		settings.AccountIgnorePublicAcls = accessBlock.IgnorePublicAcls
+		settings.AccountRestrictPublicBuckets = accessBlock.RestrictPublicBuckets
+	}
+
+	access, err := evaluateS3PublicAccess(settings)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_bucket.getBucketEffectivePublicAccess", "policy_error", err)
+		return nil, err
+	}
+	return access, nil
+}
+
 func handleS3TagsToTurbotTags(_ context.Context, d *transform.TransformData) (interface{}, error) {
 	tags := d.Value.([]types.Tag)
 
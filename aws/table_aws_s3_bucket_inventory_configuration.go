@@ -0,0 +1,260 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsS3BucketInventoryConfiguration(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_s3_bucket_inventory_configuration",
+		Description: "AWS S3 Bucket Inventory Configuration",
+		Get: &plugin.GetConfig{
+			Hydrate:    getBucketInventoryConfiguration,
+			Tags:       map[string]string{"service": "s3", "action": "GetInventoryConfiguration"},
+			KeyColumns: plugin.AllColumns([]string{"bucket_name", "id"}),
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"NoSuchConfiguration"}),
+			},
+		},
+		List: &plugin.ListConfig{
+			ParentHydrate: listS3Buckets,
+			Hydrate:       listBucketInventoryConfigurations,
+			Tags:          map[string]string{"service": "s3", "action": "ListBucketInventoryConfigurations"},
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "bucket_name", Require: plugin.Optional, CacheMatch: "exact"},
+			},
+		},
+		Columns: awsRegionalColumns([]*plugin.Column{
+			{
+				Name:        "bucket_name",
+				Description: "The name of the bucket that the inventory is of.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "id",
+				Description: "The ID used to identify the inventory configuration.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "is_enabled",
+				Description: "Specifies whether the inventory is enabled or disabled.",
+				Type:        proto.ColumnType_BOOL,
+			},
+			{
+				Name:        "included_object_versions",
+				Description: "Object versions to include in the inventory list. Possible values are: All|Current.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "schedule_frequency",
+				Description: "How frequently inventory results are produced. Possible values are: Daily|Weekly.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Schedule.Frequency"),
+			},
+			{
+				Name:        "destination_bucket_arn",
+				Description: "The ARN of the bucket where inventory results are published.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Destination.S3BucketDestination.Bucket"),
+			},
+			{
+				Name:        "destination_bucket_name",
+				Description: "The name of the bucket where inventory results are published.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Destination.S3BucketDestination.Bucket").Transform(s3BucketArnToName),
+			},
+			{
+				Name:        "destination_account_id",
+				Description: "The account ID that owns the destination bucket.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Destination.S3BucketDestination.AccountId"),
+			},
+			{
+				Name:        "destination_format",
+				Description: "The format of the inventory results. Possible values are: CSV|ORC|Parquet.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Destination.S3BucketDestination.Format"),
+			},
+			{
+				Name:        "destination_prefix",
+				Description: "The prefix that is prepended to all inventory results.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Destination.S3BucketDestination.Prefix"),
+			},
+			{
+				Name:        "destination_encryption",
+				Description: "The type of server-side encryption used to encrypt the inventory results.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Destination.S3BucketDestination.Encryption"),
+			},
+			{
+				Name:        "filter_prefix",
+				Description: "The prefix that an object must have to be included in the inventory results.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Filter.Prefix"),
+			},
+			{
+				Name:        "optional_fields",
+				Description: "The optional fields that are included in the inventory results.",
+				Type:        proto.ColumnType_JSON,
+			},
+
+			// Steampipe standard columns
+			{
+				Name:        "title",
+				Description: resourceInterfaceDescription("title"),
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Id"),
+			},
+		}),
+	}
+}
+
+type InventoryConfigurationInfo struct {
+	BucketName *string
+	types.InventoryConfiguration
+}
+
+//// LIST FUNCTION
+
+func listBucketInventoryConfigurations(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+
+	// Bucket location will be nil if getBucketLocation returned an error but
+	// was ignored through ignore_error_codes config arg
+	bucket := h.Item.(types.Bucket)
+	location, err := getIntelligentTieringBucketLocation(ctx, d, h)
+	if err != nil {
+		return nil, nil
+	} else if location == nil {
+		return nil, nil
+	}
+
+	if d.EqualsQualString("bucket_name") != "" && d.EqualsQualString("bucket_name") != *bucket.Name {
+		return nil, nil
+	}
+
+	// Create client
+	svc, err := S3Client(ctx, d, fmt.Sprint(location))
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_bucket_inventory_configuration.listBucketInventoryConfigurations", "client_error", err)
+		return nil, err
+	}
+
+	configurations, err := listS3InventoryConfigurations(ctx, d, svc, *bucket.Name)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_bucket_inventory_configuration.listBucketInventoryConfigurations", "api_error", err)
+		return nil, err
+	}
+
+	for _, configuration := range configurations {
+		d.StreamListItem(ctx, &InventoryConfigurationInfo{bucket.Name, configuration})
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// listS3InventoryConfigurations returns all the inventory configurations of a bucket
+func listS3InventoryConfigurations(ctx context.Context, d *plugin.QueryData, svc *s3.Client, bucketName string) ([]types.InventoryConfiguration, error) {
+	params := &s3.ListBucketInventoryConfigurationsInput{
+		Bucket: &bucketName,
+	}
+
+	var configurations []types.InventoryConfiguration
+	for {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		op, err := svc.ListBucketInventoryConfigurations(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		configurations = append(configurations, op.InventoryConfigurationList...)
+
+		if op.NextContinuationToken != nil {
+			params.ContinuationToken = op.NextContinuationToken
+		} else {
+			break
+		}
+	}
+
+	return configurations, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getBucketInventoryConfiguration(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	bucketName := d.EqualsQualString("bucket_name")
+	id := d.EqualsQualString("id")
+
+	if bucketName == "" || id == "" {
+		return nil, nil
+	}
+
+	// Bucket location will be nil if getBucketLocation returned an error but
+	// was ignored through ignore_error_codes config arg
+	location, err := getIntelligentTieringBucketLocation(ctx, d, h)
+	if err != nil {
+		return nil, nil
+	} else if location == nil {
+		return nil, nil
+	}
+
+	// Create client
+	svc, err := S3Client(ctx, d, fmt.Sprint(location))
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_bucket_inventory_configuration.getBucketInventoryConfiguration", "client_error", err)
+		return nil, err
+	}
+
+	params := &s3.GetBucketInventoryConfigurationInput{
+		Bucket: &bucketName,
+		Id:     &id,
+	}
+
+	op, err := svc.GetBucketInventoryConfiguration(ctx, params)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_bucket_inventory_configuration.getBucketInventoryConfiguration", "api_error", err)
+		return nil, err
+	}
+
+	if op != nil && op.InventoryConfiguration != nil {
+		return &InventoryConfigurationInfo{&bucketName, *op.InventoryConfiguration}, nil
+	}
+
+	return nil, nil
+}
+
+//// TRANSFORM FUNCTIONS
+
+// s3BucketArnToName returns the name of a bucket from its ARN, e.g.
+// arn:aws:s3:::my-bucket
+func s3BucketArnToName(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	arn, ok := d.Value.(*string)
+	if !ok || arn == nil {
+		return nil, nil
+	}
+	return s3BucketNameFromArn(*arn), nil
+}
+
+func s3BucketNameFromArn(arn string) string {
+	if i := strings.LastIndex(arn, ":::"); i >= 0 {
+		return arn[i+3:]
+	}
+	return arn
+}
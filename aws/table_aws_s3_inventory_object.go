@@ -0,0 +1,470 @@
+package aws
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsS3InventoryObject(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_s3_inventory_object",
+		Description: "AWS S3 Inventory Object, read from CSV and Parquet inventory reports. ORC reports are not supported.",
+		List: &plugin.ListConfig{
+			Hydrate: listS3InventoryObjects,
+			Tags:    map[string]string{"service": "s3", "action": "GetObject"},
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "bucket_name", CacheMatch: "exact"},
+				{Name: "inventory_id", Require: plugin.Optional, CacheMatch: "exact"},
+			},
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"NoSuchBucket", "NoSuchConfiguration"}),
+			},
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func: getBucketLocationForObjects,
+				Tags: map[string]string{"service": "s3", "action": "GetBucketLocation"},
+			},
+		},
+		Columns: awsAccountColumns([]*plugin.Column{
+			{
+				Name:        "bucket_name",
+				Description: "The name of the bucket that the inventory is of.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("bucket_name"),
+			},
+			{
+				Name:        "inventory_id",
+				Description: "The ID of the inventory configuration that produced the report. Defaults to the first enabled configuration of the bucket.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "inventory_time",
+				Description: "The time when the inventory report was started.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "manifest_key",
+				Description: "The key of the manifest of the inventory report, in the destination bucket.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "source",
+				Description: "The key of the inventory data file that the object was read from, in the destination bucket.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "key",
+				Description: "The key of the object.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.key"),
+			},
+			{
+				Name:        "version_id",
+				Description: "The version ID of the object, if the inventory includes all versions.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.version_id"),
+			},
+			{
+				Name:        "is_latest",
+				Description: "True if the object is the current version of the object, if the inventory includes all versions.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Fields.is_latest"),
+			},
+			{
+				Name:        "is_delete_marker",
+				Description: "True if the object is a delete marker, if the inventory includes all versions.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Fields.is_delete_marker"),
+			},
+			{
+				Name:        "size",
+				Description: "The size of the object in bytes.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("Fields.size"),
+			},
+			{
+				Name:        "last_modified_date",
+				Description: "The time when the object was created or last modified.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Fields.last_modified_date"),
+			},
+			{
+				Name:        "e_tag",
+				Description: "The entity tag of the object.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.e_tag"),
+			},
+			{
+				Name:        "storage_class",
+				Description: "The storage class of the object.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.storage_class"),
+			},
+			{
+				Name:        "is_multipart_uploaded",
+				Description: "True if the object was uploaded as a multipart upload.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Fields.is_multipart_uploaded"),
+			},
+			{
+				Name:        "replication_status",
+				Description: "The replication status of the object. Possible values are: PENDING|COMPLETED|FAILED|REPLICA.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.replication_status"),
+			},
+			{
+				Name:        "encryption_status",
+				Description: "The server-side encryption of the object. Possible values are: NOT-SSE|SSE-S3|SSE-C|SSE-KMS|DSSE-KMS.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.encryption_status"),
+			},
+			{
+				Name:        "bucket_key_status",
+				Description: "Whether an S3 Bucket Key is used for the encryption of the object. Possible values are: ENABLED|DISABLED.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.bucket_key_status"),
+			},
+			{
+				Name:        "object_lock_retain_until_date",
+				Description: "The date until which the locked object cannot be deleted.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("Fields.object_lock_retain_until_date"),
+			},
+			{
+				Name:        "object_lock_mode",
+				Description: "The Object Lock mode of the object. Possible values are: GOVERNANCE|COMPLIANCE.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.object_lock_mode"),
+			},
+			{
+				Name:        "object_lock_legal_hold_status",
+				Description: "The Object Lock legal hold status of the object. Possible values are: ON|OFF.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.object_lock_legal_hold_status"),
+			},
+			{
+				Name:        "intelligent_tiering_access_tier",
+				Description: "The access tier of the object, if it is in the S3 Intelligent-Tiering storage class.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.intelligent_tiering_access_tier"),
+			},
+			{
+				Name:        "checksum_algorithm",
+				Description: "The algorithm used to create a checksum of the object.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.checksum_algorithm"),
+			},
+			{
+				Name:        "object_owner",
+				Description: "The canonical user ID of the owner of the object.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Fields.object_owner"),
+			},
+			{
+				Name:        "fields",
+				Description: "All the fields of the inventory record, including fields without a column of their own.",
+				Type:        proto.ColumnType_JSON,
+			},
+		}),
+	}
+}
+
+type s3InventoryObject struct {
+	InventoryId   string
+	InventoryTime *time.Time
+	ManifestKey   string
+	Source        string
+	Fields        map[string]interface{}
+}
+
+// The manifest.json of an inventory report
+type s3InventoryManifest struct {
+	CreationTimestamp string `json:"creationTimestamp"`
+	FileFormat        string `json:"fileFormat"`
+	FileSchema        string `json:"fileSchema"`
+	Files             []struct {
+		Key string `json:"key"`
+	} `json:"files"`
+}
+
+// Inventory reports are delivered under folders named after the time the
+// report was started, e.g. 2023-01-02T01-00Z/
+var s3InventoryReportFolderRegex = regexp.MustCompile(`/\d{4}-\d{2}-\d{2}T\d{2}-\d{2}Z/$`)
+
+//// LIST FUNCTION
+
+func listS3InventoryObjects(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	bucketName := d.EqualsQualString("bucket_name")
+
+	// Bucket location will be nil if getBucketLocationForObjects returned an error but
+	// was ignored through ignore_error_codes config arg
+	location, err := getBucketLocationForObjects(ctx, d, h)
+	if err != nil {
+		return nil, err
+	} else if location == "" {
+		return nil, nil
+	}
+
+	svc, err := S3Client(ctx, d, fmt.Sprint(location))
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_inventory_object.listS3InventoryObjects", "get_client_error", err)
+		return nil, err
+	}
+
+	configuration, err := getS3InventoryObjectConfiguration(ctx, d, svc, bucketName)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_inventory_object.listS3InventoryObjects", "api_error", err)
+		return nil, err
+	}
+	if configuration == nil || configuration.Destination == nil || configuration.Destination.S3BucketDestination == nil {
+		return nil, nil
+	}
+	destination := configuration.Destination.S3BucketDestination
+
+	// Inventory reports are often delivered to a bucket in another region or
+	// account, so the destination bucket's region is looked up
+	destinationBucket := s3BucketNameFromArn(aws.ToString(destination.Bucket))
+	destinationRegion, err := getS3BucketRegion(ctx, d, destinationBucket)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_inventory_object.listS3InventoryObjects", "bucket_region_error", err)
+		return nil, err
+	}
+	destinationSvc, err := S3Client(ctx, d, destinationRegion)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_inventory_object.listS3InventoryObjects", "get_client_error", err)
+		return nil, err
+	}
+
+	// Reports are delivered to <prefix>/<source bucket>/<configuration id>/<time>/
+	base := aws.ToString(destination.Prefix)
+	if base != "" && !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	base += bucketName + "/" + aws.ToString(configuration.Id) + "/"
+
+	folders, err := listS3CommonPrefixes(ctx, d, destinationSvc, destinationBucket, base)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_inventory_object.listS3InventoryObjects", "api_error", err, "prefix", base)
+		return nil, err
+	}
+	// A folder is created when a report is started, but the manifest is only
+	// written once all of its data files have been delivered, so the newest
+	// folder with a manifest is read
+	sort.Sort(sort.Reverse(sort.StringSlice(folders)))
+	var source *fileSource
+	var manifestKey string
+	for _, folder := range folders {
+		if !s3InventoryReportFolderRegex.MatchString(folder) {
+			continue
+		}
+		folderSource := newS3FileSource(ctx, d, destinationSvc, destinationBucket, folder)
+		complete, err := s3InventoryReportComplete(folderSource, folder)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_s3_inventory_object.listS3InventoryObjects", "api_error", err, "prefix", folder)
+			return nil, err
+		}
+		if complete {
+			source = folderSource
+			manifestKey = folder + "manifest.json"
+			break
+		}
+	}
+	if source == nil {
+		return nil, nil
+	}
+
+	manifest, err := readS3InventoryManifest(source, manifestKey)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_inventory_object.listS3InventoryObjects", "manifest_error", err, "key", manifestKey)
+		return nil, err
+	}
+
+	// ORC data files can't be read by the plugin, so ORC reports are declined
+	// up front rather than after rows of other reports have been returned
+	format := strings.ToUpper(manifest.FileFormat)
+	if format != "CSV" && format != "PARQUET" {
+		return nil, fmt.Errorf("aws_s3_inventory_object: inventory %s of bucket %s publishes %s reports, which are not supported; only CSV and Parquet inventory reports can be read", aws.ToString(configuration.Id), bucketName, manifest.FileFormat)
+	}
+
+	item := s3InventoryObject{
+		InventoryId: aws.ToString(configuration.Id),
+		ManifestKey: manifestKey,
+	}
+	if ms, err := strconv.ParseInt(manifest.CreationTimestamp, 10, 64); err == nil {
+		item.InventoryTime = aws.Time(time.UnixMilli(ms))
+	}
+
+	for _, file := range manifest.Files {
+		item.Source = file.Key
+
+		var done bool
+		if format == "CSV" {
+			done, err = streamS3InventoryCSVFile(ctx, d, source, manifest.FileSchema, item)
+		} else {
+			done, err = streamS3InventoryParquetFile(ctx, d, source, item)
+		}
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_s3_inventory_object.listS3InventoryObjects", "read_error", err, "key", file.Key)
+			return nil, err
+		}
+		if done {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getS3InventoryObjectConfiguration returns the inventory configuration of the
+// inventory_id qual, or the first enabled configuration of the bucket.
+func getS3InventoryObjectConfiguration(ctx context.Context, d *plugin.QueryData, svc *s3.Client, bucketName string) (*types.InventoryConfiguration, error) {
+	if id := d.EqualsQualString("inventory_id"); id != "" {
+		op, err := svc.GetBucketInventoryConfiguration(ctx, &s3.GetBucketInventoryConfigurationInput{
+			Bucket: aws.String(bucketName),
+			Id:     aws.String(id),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return op.InventoryConfiguration, nil
+	}
+
+	configurations, err := listS3InventoryConfigurations(ctx, d, svc, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(configurations, func(i, j int) bool {
+		return aws.ToString(configurations[i].Id) < aws.ToString(configurations[j].Id)
+	})
+	for _, configuration := range configurations {
+		if configuration.IsEnabled {
+			return &configuration, nil
+		}
+	}
+	return nil, nil
+}
+
+// s3InventoryReportComplete returns true if the report folder has its
+// manifest.json
+func s3InventoryReportComplete(source *fileSource, folder string) (bool, error) {
+	keys, err := source.list()
+	if err != nil {
+		return false, err
+	}
+	for _, key := range keys {
+		if key == folder+"manifest.json" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func readS3InventoryManifest(source *fileSource, key string) (*s3InventoryManifest, error) {
+	body, err := source.open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var manifest s3InventoryManifest
+	if err := json.NewDecoder(body).Decode(&manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// streamS3InventoryCSVFile streams the objects of a gzipped CSV data file,
+// whose columns are named by the fileSchema of the manifest, e.g.
+// "Bucket, Key, Size, LastModifiedDate". It returns true once no more rows are
+// required.
+func streamS3InventoryCSVFile(ctx context.Context, d *plugin.QueryData, source *fileSource, fileSchema string, item s3InventoryObject) (bool, error) {
+	var names []string
+	for _, field := range strings.Split(fileSchema, ",") {
+		names = append(names, snakeCase(strings.TrimSpace(field)))
+	}
+
+	reader, err := source.openDecompressed(item.Source)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	records := csv.NewReader(reader)
+	records.FieldsPerRecord = -1
+	for {
+		record, err := records.Read()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		fields := map[string]interface{}{}
+		for i, value := range record {
+			// Empty values are left out so they are null, rather than
+			// failing to convert to the column's type
+			if i >= len(names) || value == "" {
+				continue
+			}
+			fields[names[i]] = value
+		}
+
+		// Keys are URL-encoded in CSV reports
+		if key, ok := fields["key"].(string); ok {
+			if decoded, err := url.QueryUnescape(key); err == nil {
+				fields["key"] = decoded
+			}
+		}
+
+		item.Fields = fields
+		d.StreamListItem(ctx, item)
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return true, nil
+		}
+	}
+}
+
+// streamS3InventoryParquetFile streams the objects of a Parquet data file. It
+// returns true once no more rows are required.
+func streamS3InventoryParquetFile(ctx context.Context, d *plugin.QueryData, source *fileSource, item s3InventoryObject) (bool, error) {
+	done := false
+	err := readParquetFile(source, item.Source, func(fields map[string]interface{}) bool {
+		// Empty values are left out so they are null, as in CSV reports
+		for name, value := range fields {
+			if value == nil || value == "" {
+				delete(fields, name)
+			}
+		}
+
+		item.Fields = fields
+		d.StreamListItem(ctx, item)
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		done = d.RowsRemaining(ctx) == 0
+		return !done
+	})
+	return done, err
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"golang.org/x/sync/errgroup"
 )
 
 func tableAwsS3Object(_ context.Context) *plugin.Table {
@@ -26,6 +27,7 @@ func tableAwsS3Object(_ context.Context) *plugin.Table {
 			KeyColumns: []*plugin.KeyColumn{
 				{Name: "bucket_name", Require: plugin.Required, CacheMatch: "exact"},
 				{Name: "prefix", Require: plugin.Optional},
+				{Name: "key", Operators: []string{"=", "~~"}, Require: plugin.Optional, CacheMatch: "exact"},
 			},
 		},
 		HydrateConfig: []plugin.HydrateConfig{
@@ -415,20 +417,53 @@ func listS3Objects(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateDa
 		}
 	}
 
+	prefix, ok := getS3ObjectListPrefix(d)
+	if !ok {
+		// The prefix and key quals can't both match any key
+		return nil, nil
+	}
+
+	// stream streams a page of objects, and returns false once no more rows
+	// are required
+	stream := func(objects []types.Object) bool {
+		for _, object := range objects {
+			d.StreamListItem(ctx, object)
+
+			// Context may get cancelled due to manual cancellation or if the limit has been reached
+			if d.RowsRemaining(ctx) == 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Objects are listed serially unless the connection allows more than one
+	// concurrent list
+	concurrency := defaultS3ObjectListConcurrency
+	if s3ObjectListConcurrency := GetConfig(d.Connection).S3ObjectListConcurrency; s3ObjectListConcurrency != nil {
+		concurrency = *s3ObjectListConcurrency
+	}
+	if concurrency <= 1 {
+		if err := listS3ObjectsWithPrefix(ctx, d, svc, bucketName, prefix, maxItems, stream); err != nil {
+			plugin.Logger(ctx).Error("aws_s3_object.ListObjectsV2", "api_error", err)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// List the objects directly under the prefix, and discover the common
+	// prefixes that the rest of the objects are under
 	input := &s3.ListObjectsV2Input{
 		Bucket:     aws.String(bucketName),
 		MaxKeys:    maxItems,
 		FetchOwner: true,
+		Delimiter:  aws.String("/"),
 	}
-
-	equalQuals := d.EqualsQuals
-	if equalQuals["prefix"] != nil {
-		if equalQuals["prefix"].GetStringValue() != "" {
-			input.Prefix = aws.String(equalQuals["prefix"].GetStringValue())
-		}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
 	}
 
-	// execute list call
+	var commonPrefixes []string
 	for {
 		// apply rate limiting
 		d.WaitForListRateLimit(ctx)
@@ -439,13 +474,93 @@ func listS3Objects(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateDa
 			return nil, err
 		}
 
-		for _, object := range objects.Contents {
-			d.StreamListItem(ctx, object)
+		if !stream(objects.Contents) {
+			return nil, nil
+		}
+		for _, commonPrefix := range objects.CommonPrefixes {
+			commonPrefixes = append(commonPrefixes, *commonPrefix.Prefix)
+		}
+		input.ContinuationToken = objects.NextContinuationToken
+		if objects.NextContinuationToken == nil {
+			break
+		}
+	}
 
-			// Context may get cancelled due to manual cancellation or if the limit has been reached
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
+	// List the objects under each common prefix concurrently. StreamListItem
+	// must not be called concurrently, so the pages are sent back to be
+	// streamed from this goroutine.
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	g, gctx := errgroup.WithContext(listCtx)
+	g.SetLimit(concurrency)
+
+	pages := make(chan []types.Object)
+	listed := make(chan error, 1)
+	go func() {
+		for _, commonPrefix := range commonPrefixes {
+			if gctx.Err() != nil {
+				break
 			}
+			commonPrefix := commonPrefix
+			g.Go(func() error {
+				return listS3ObjectsWithPrefix(gctx, d, svc, bucketName, commonPrefix, maxItems, func(objects []types.Object) bool {
+					select {
+					case pages <- objects:
+						return true
+					case <-gctx.Done():
+						return false
+					}
+				})
+			})
+		}
+		listed <- g.Wait()
+		close(pages)
+	}()
+
+	for objects := range pages {
+		if !stream(objects) {
+			// Stop the lists that are still running and wait for them to return
+			cancel()
+			<-listed
+			return nil, nil
+		}
+	}
+	if err := <-listed; err != nil {
+		plugin.Logger(ctx).Error("aws_s3_object.ListObjectsV2", "api_error", err)
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// Number of prefixes that are listed concurrently, unless the connection sets
+// s3_object_list_concurrency
+const defaultS3ObjectListConcurrency = 10
+
+// listS3ObjectsWithPrefix lists all the objects with a prefix, passing each
+// page of objects to handle until it returns false
+func listS3ObjectsWithPrefix(ctx context.Context, d *plugin.QueryData, svc *s3.Client, bucketName string, prefix string, maxItems int32, handle func(objects []types.Object) bool) error {
+	input := &s3.ListObjectsV2Input{
+		Bucket:     aws.String(bucketName),
+		MaxKeys:    maxItems,
+		FetchOwner: true,
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	// execute list call
+	for {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		objects, err := svc.ListObjectsV2(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		if !handle(objects.Contents) {
+			return nil
 		}
 		input.ContinuationToken = objects.NextContinuationToken
 		if objects.NextContinuationToken == nil {
@@ -453,7 +568,54 @@ func listS3Objects(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateDa
 		}
 	}
 
-	return nil, err
+	return nil
+}
+
+// getS3ObjectListPrefix returns the longest prefix that all keys matching the
+// prefix and key quals have, e.g. logs/2024- for key like 'logs/2024-%'. It
+// returns false if no key can match both.
+func getS3ObjectListPrefix(d *plugin.QueryData) (string, bool) {
+	prefixes := []string{d.EqualsQualString("prefix")}
+	if d.Quals["key"] != nil {
+		for _, q := range d.Quals["key"].Quals {
+			value := q.Value.GetStringValue()
+			switch q.Operator {
+			case "=":
+				prefixes = append(prefixes, value)
+			case "~~":
+				prefixes = append(prefixes, likePatternPrefix(value))
+			}
+		}
+	}
+
+	var prefix string
+	for _, p := range prefixes {
+		switch {
+		case strings.HasPrefix(p, prefix):
+			prefix = p
+		case !strings.HasPrefix(prefix, p):
+			return "", false
+		}
+	}
+	return prefix, true
+}
+
+// likePatternPrefix returns the literal text of a LIKE pattern before its
+// first wildcard, with escapes removed
+func likePatternPrefix(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '%', '_':
+			return b.String()
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+		}
+		b.WriteByte(pattern[i])
+	}
+	return b.String()
 }
 
 func getS3Object(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
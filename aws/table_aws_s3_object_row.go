@@ -0,0 +1,406 @@
+package aws
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsS3ObjectRow(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_s3_object_row",
+		Description: "AWS S3 Object Row",
+		List: &plugin.ListConfig{
+			Hydrate: listS3ObjectRows,
+			Tags:    map[string]string{"service": "s3", "action": "GetObject"},
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "bucket_name", CacheMatch: "exact"},
+				{Name: "key", Require: plugin.AnyOf, CacheMatch: "exact"},
+				{Name: "prefix", Require: plugin.AnyOf, CacheMatch: "exact"},
+				{Name: "format", Require: plugin.Optional, CacheMatch: "exact"},
+				{Name: "columns", Require: plugin.Optional, CacheMatch: "exact"},
+				{Name: "field_delimiter", Require: plugin.Optional, CacheMatch: "exact"},
+				{Name: "filters", Require: plugin.Optional, CacheMatch: "exact"},
+			},
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"NoSuchBucket", "NoSuchKey"}),
+			},
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func: getBucketLocationForObjects,
+				Tags: map[string]string{"service": "s3", "action": "GetBucketLocation"},
+			},
+		},
+		Columns: awsAccountColumns([]*plugin.Column{
+			{
+				Name:        "bucket_name",
+				Description: "The name of the bucket that contains the object.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("bucket_name"),
+			},
+			{
+				Name:        "key",
+				Description: "The key of the object that the record was read from.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "prefix",
+				Description: "The prefix of the keys of the objects to read.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("prefix"),
+			},
+			{
+				Name:        "format",
+				Description: "The format of the object. Possible values are: CSV|JSON|PARQUET. Defaults to the format of the key's extension, or of the object's content.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "columns",
+				Description: "A comma-separated list of the fields to return in each record, e.g. id,name. Defaults to all the fields.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("columns"),
+			},
+			{
+				Name:        "field_delimiter",
+				Description: "The character that separates the fields of CSV objects. Defaults to a tab for .tsv keys, or a comma.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("field_delimiter"),
+			},
+			{
+				Name:        "filters",
+				Description: "An object of field names to values, e.g. {\"status\": \"active\"}, that returned records must have. Values are compared as text, and row groups of Parquet objects that can't hold them are skipped.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromQual("filters"),
+			},
+			{
+				Name:        "row_number",
+				Description: "The position of the record in the object, starting at 1.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "record",
+				Description: "The record, as an object of its fields for CSV and Parquet objects.",
+				Type:        proto.ColumnType_JSON,
+			},
+		}),
+	}
+}
+
+type s3ObjectRow struct {
+	Key       string
+	Format    string
+	RowNumber int64
+	Record    interface{}
+}
+
+//// LIST FUNCTION
+
+func listS3ObjectRows(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	bucketName := d.EqualsQualString("bucket_name")
+
+	// Bucket location will be nil if getBucketLocationForObjects returned an error but
+	// was ignored through ignore_error_codes config arg
+	location, err := getBucketLocationForObjects(ctx, d, h)
+	if err != nil {
+		return nil, err
+	} else if location == "" {
+		return nil, nil
+	}
+
+	svc, err := S3Client(ctx, d, fmt.Sprint(location))
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_object_row.listS3ObjectRows", "get_client_error", err)
+		return nil, err
+	}
+
+	source := newS3FileSource(ctx, d, svc, bucketName, d.EqualsQualString("prefix"))
+
+	keys := []string{d.EqualsQualString("key")}
+	if keys[0] == "" {
+		keys, err = source.list()
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_s3_object_row.listS3ObjectRows", "api_error", err)
+			return nil, err
+		}
+	}
+
+	var columns []string
+	for _, column := range strings.Split(d.EqualsQualString("columns"), ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+
+	filters, err := buildS3ObjectRowFilters(d.Quals["filters"])
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_object_row.listS3ObjectRows", "filters_building_error", err)
+		return nil, err
+	}
+
+	for _, key := range keys {
+		// Skip folder placeholders
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
+
+		done, err := streamS3ObjectRows(ctx, d, source, key, columns, filters)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_s3_object_row.listS3ObjectRows", "read_error", err, "key", key)
+			return nil, err
+		}
+		if done {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// streamS3ObjectRows streams the records of a single object. It returns true
+// once no more rows are required.
+func streamS3ObjectRows(ctx context.Context, d *plugin.QueryData, source *fileSource, key string, columns []string, filters map[string]string) (bool, error) {
+	// Rows have the format qual as given, so they match it
+	format := strings.ToUpper(d.EqualsQualString("format"))
+	if format == "" {
+		format = s3ObjectRowFormatFromKey(key)
+	}
+	formatValue := func() string {
+		if qualFormat := d.EqualsQualString("format"); qualFormat != "" {
+			return qualFormat
+		}
+		return format
+	}
+
+	// Records are filtered before they are projected, since the filters may
+	// be on fields that are not returned
+	streamNumbered := func(rowNumber int64, record interface{}) bool {
+		if !matchS3ObjectRowFilters(record, filters) {
+			return true
+		}
+		d.StreamListItem(ctx, s3ObjectRow{
+			Key:       key,
+			Format:    formatValue(),
+			RowNumber: rowNumber,
+			Record:    projectS3ObjectRowColumns(record, columns),
+		})
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		return d.RowsRemaining(ctx) != 0
+	}
+	var rowNumber int64
+	stream := func(record interface{}) bool {
+		rowNumber++
+		return streamNumbered(rowNumber, record)
+	}
+
+	// Parquet objects are read with ranged requests, rather than as a stream
+	if format == "PARQUET" {
+		return readS3ObjectParquetRows(source, key, columns, filters, streamNumbered)
+	}
+
+	reader, err := source.openDecompressed(key)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	buffered := bufio.NewReader(reader)
+	if format == "" {
+		format = sniffS3ObjectRowFormat(buffered)
+	}
+
+	switch format {
+	case "CSV":
+		delimiter := d.EqualsQualString("field_delimiter")
+		if delimiter == "" && strings.Contains(strings.ToLower(key), ".tsv") {
+			delimiter = "\t"
+		}
+		return readS3ObjectCSVRows(buffered, delimiter, stream)
+	case "JSON":
+		return readS3ObjectJSONRows(buffered, stream)
+	case "PARQUET":
+		return readS3ObjectParquetRows(source, key, columns, filters, streamNumbered)
+	default:
+		return false, fmt.Errorf("aws_s3_object_row: unsupported format %q, must be one of CSV, JSON or PARQUET", format)
+	}
+}
+
+// s3ObjectRowFormatFromKey returns the format of a key's extension, ignoring
+// compression extensions, or "" if it is not known
+func s3ObjectRowFormatFromKey(key string) string {
+	name := strings.ToLower(key)
+	for _, ext := range []string{".gz", ".zst", ".zstd", ".bz2"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+
+	switch {
+	case strings.HasSuffix(name, ".parquet"):
+		return "PARQUET"
+	case strings.HasSuffix(name, ".json"), strings.HasSuffix(name, ".jsonl"), strings.HasSuffix(name, ".ndjson"):
+		return "JSON"
+	case strings.HasSuffix(name, ".csv"), strings.HasSuffix(name, ".tsv"):
+		return "CSV"
+	}
+	return ""
+}
+
+// sniffS3ObjectRowFormat returns the format of an object from its content
+func sniffS3ObjectRowFormat(reader *bufio.Reader) string {
+	start, _ := reader.Peek(512)
+	if bytes.HasPrefix(start, []byte("PAR1")) {
+		return "PARQUET"
+	}
+	trimmed := bytes.TrimLeft(start, " \t\r\n\ufeff")
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return "JSON"
+	}
+	return "CSV"
+}
+
+// readS3ObjectCSVRows reads a CSV object with a header line, whose fields
+// name the fields of each record
+func readS3ObjectCSVRows(reader io.Reader, delimiter string, stream func(record interface{}) bool) (bool, error) {
+	records := csv.NewReader(reader)
+	records.FieldsPerRecord = -1
+	records.LazyQuotes = true
+	if delimiter != "" {
+		records.Comma = []rune(delimiter)[0]
+	}
+
+	header, err := records.Read()
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	for {
+		values, err := records.Read()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		record := map[string]interface{}{}
+		for i, value := range values {
+			name := fmt.Sprintf("_%d", i+1)
+			if i < len(header) && header[i] != "" {
+				name = header[i]
+			}
+			record[name] = value
+		}
+		if !stream(record) {
+			return true, nil
+		}
+	}
+}
+
+// readS3ObjectJSONRows reads JSON lines, concatenated JSON values or a JSON
+// array, with one record per value or array element
+func readS3ObjectJSONRows(reader *bufio.Reader, stream func(record interface{}) bool) (bool, error) {
+	start, _ := reader.Peek(512)
+	isArray := bytes.HasPrefix(bytes.TrimLeft(start, " \t\r\n"), []byte("["))
+
+	decoder := json.NewDecoder(reader)
+	if isArray {
+		// Consume the opening bracket
+		if _, err := decoder.Token(); err != nil {
+			return false, err
+		}
+	}
+
+	for decoder.More() {
+		var record interface{}
+		if err := decoder.Decode(&record); err != nil {
+			return false, err
+		}
+		if !stream(record) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// projectS3ObjectRowColumns returns the requested fields of a record
+func projectS3ObjectRowColumns(record interface{}, columns []string) interface{} {
+	fields, ok := record.(map[string]interface{})
+	if !ok || len(columns) == 0 {
+		return record
+	}
+
+	projected := map[string]interface{}{}
+	for _, column := range columns {
+		if value, ok := fields[column]; ok {
+			projected[column] = value
+		}
+	}
+	return projected
+}
+
+// matchS3ObjectRowFilters returns true if the fields of a record have the
+// values of all the filters, compared as the text of a CSV field
+func matchS3ObjectRowFilters(record interface{}, filters map[string]string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	fields, ok := record.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for field, value := range filters {
+		if parquetString(fields[field]) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// buildS3ObjectRowFilters merges the objects of the filters quals into a map
+// of field name to value
+func buildS3ObjectRowFilters(qual *plugin.KeyColumnQuals) (map[string]string, error) {
+	if qual == nil {
+		return nil, nil
+	}
+
+	filters := map[string]string{}
+	for _, qual := range qual.Quals {
+		qualFilter := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(qual.Value.GetJsonbValue()), &qualFilter); err != nil {
+			return nil, fmt.Errorf("aws_s3_object_row: filters must be an object of field names to values: %v", err)
+		}
+		for field, value := range qualFilter {
+			filters[field] = parquetString(value)
+		}
+	}
+	return filters, nil
+}
+
+// readS3ObjectParquetRows reads the records of a Parquet object. Only the
+// columns of the requested and filtered fields are read, and row groups that
+// can't match the filters are skipped.
+func readS3ObjectParquetRows(source *fileSource, key string, columns []string, filters map[string]string, stream func(rowNumber int64, record interface{}) bool) (bool, error) {
+	done := false
+	err := readParquetFileWhere(source, key, columns, filters, func(rowNumber int64, record map[string]interface{}) bool {
+		done = !stream(rowNumber, record)
+		return !done
+	})
+	return done, err
+}
@@ -0,0 +1,37 @@
+package aws
+
+import "testing"
+
+func TestMatchS3ObjectRowFilters(t *testing.T) {
+	record := map[string]interface{}{
+		"status": "active",
+		"count":  float64(42),
+		"score":  1.5,
+		"tags":   map[string]interface{}{"env": "prod"},
+	}
+
+	cases := []struct {
+		name    string
+		record  interface{}
+		filters map[string]string
+		want    bool
+	}{
+		{"no filters", record, nil, true},
+		{"string", record, map[string]string{"status": "active"}, true},
+		{"whole number", record, map[string]string{"count": "42"}, true},
+		{"decimal", record, map[string]string{"score": "1.5"}, true},
+		{"nested value as JSON", record, map[string]string{"tags": `{"env":"prod"}`}, true},
+		{"missing field as empty", record, map[string]string{"owner": ""}, true},
+		{"mismatched value", record, map[string]string{"status": "Active"}, false},
+		{"one mismatched filter", record, map[string]string{"status": "active", "count": "41"}, false},
+		{"record that is not an object", []interface{}{"active"}, map[string]string{"status": "active"}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := matchS3ObjectRowFilters(c.record, c.filters); got != c.want {
+				t.Errorf("matchS3ObjectRowFilters(%v, %v) = %v, want %v", c.record, c.filters, got, c.want)
+			}
+		})
+	}
+}
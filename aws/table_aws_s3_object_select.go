@@ -0,0 +1,262 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsS3ObjectSelect(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_s3_object_select",
+		Description: "AWS S3 Object Select",
+		List: &plugin.ListConfig{
+			Hydrate: listS3ObjectSelectRecords,
+			Tags:    map[string]string{"service": "s3", "action": "GetObject"},
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "bucket_name", CacheMatch: "exact"},
+				{Name: "key", CacheMatch: "exact"},
+				{Name: "expression", CacheMatch: "exact"},
+				{Name: "input_serialization", Require: plugin.Optional, CacheMatch: "exact"},
+				{Name: "compression_type", Require: plugin.Optional, CacheMatch: "exact"},
+				{Name: "file_header_info", Require: plugin.Optional, CacheMatch: "exact"},
+				{Name: "field_delimiter", Require: plugin.Optional, CacheMatch: "exact"},
+				{Name: "json_type", Require: plugin.Optional, CacheMatch: "exact"},
+			},
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"NoSuchBucket", "NoSuchKey"}),
+			},
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func: getBucketLocationForObjects,
+				Tags: map[string]string{"service": "s3", "action": "GetBucketLocation"},
+			},
+		},
+		Columns: awsAccountColumns([]*plugin.Column{
+			{
+				Name:        "bucket_name",
+				Description: "The name of the bucket that contains the object.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("bucket_name"),
+			},
+			{
+				Name:        "key",
+				Description: "The key of the object to query.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("key"),
+			},
+			{
+				Name:        "expression",
+				Description: "The S3 Select SQL expression to run on the object, e.g. select * from s3object s where s.status = 'error'.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("expression"),
+			},
+			{
+				Name:        "input_serialization",
+				Description: "The format of the object. Possible values are: CSV|JSON|PARQUET. Defaults to the format of the key's extension.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("input_serialization"),
+			},
+			{
+				Name:        "compression_type",
+				Description: "The compression of CSV and JSON objects. Possible values are: NONE|GZIP|BZIP2. Defaults to the compression of the key's extension.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("compression_type"),
+			},
+			{
+				Name:        "file_header_info",
+				Description: "How the first line of CSV objects is used. Possible values are: USE|IGNORE|NONE. Defaults to USE, which names the fields of each record after the header.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("file_header_info"),
+			},
+			{
+				Name:        "field_delimiter",
+				Description: "The character that separates the fields of CSV objects. Defaults to a comma.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("field_delimiter"),
+			},
+			{
+				Name:        "json_type",
+				Description: "The type of JSON objects. Possible values are: DOCUMENT|LINES. Defaults to LINES.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("json_type"),
+			},
+			{
+				Name:        "record",
+				Description: "A record returned by the expression.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromValue(),
+			},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listS3ObjectSelectRecords(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// Bucket location will be nil if getBucketLocationForObjects returned an error but
+	// was ignored through ignore_error_codes config arg
+	location, err := getBucketLocationForObjects(ctx, d, h)
+	if err != nil {
+		return nil, err
+	} else if location == "" {
+		return nil, nil
+	}
+
+	svc, err := S3Client(ctx, d, fmt.Sprint(location))
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_object_select.listS3ObjectSelectRecords", "get_client_error", err)
+		return nil, err
+	}
+
+	key := d.EqualsQualString("key")
+	inputSerialization, err := buildS3SelectInputSerialization(d, key)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &s3.SelectObjectContentInput{
+		Bucket:             aws.String(d.EqualsQualString("bucket_name")),
+		Key:                aws.String(key),
+		Expression:         aws.String(d.EqualsQualString("expression")),
+		ExpressionType:     types.ExpressionTypeSql,
+		InputSerialization: inputSerialization,
+	}
+
+	err = selectS3ObjectContent(ctx, svc, input, func(record interface{}) bool {
+		d.StreamListItem(ctx, record)
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_s3_object_select.listS3ObjectSelectRecords", "api_error", err)
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// selectS3ObjectContent runs an S3 Select request with JSON output, and calls
+// handle with each record until it returns false.
+func selectS3ObjectContent(ctx context.Context, svc *s3.Client, input *s3.SelectObjectContentInput, handle func(record interface{}) bool) error {
+	input.OutputSerialization = &types.OutputSerialization{
+		JSON: &types.JSONOutput{RecordDelimiter: aws.String("\n")},
+	}
+
+	output, err := svc.SelectObjectContent(ctx, input)
+	if err != nil {
+		return err
+	}
+	stream := output.GetStream()
+	defer stream.Close()
+
+	// Records events hold chunks of the output, which may end part way
+	// through a record, so records are only parsed once complete
+	var pending []byte
+	for event := range stream.Events() {
+		records, ok := event.(*types.SelectObjectContentEventStreamMemberRecords)
+		if !ok {
+			continue
+		}
+		pending = append(pending, records.Value.Payload...)
+
+		for {
+			i := bytes.IndexByte(pending, '\n')
+			if i < 0 {
+				break
+			}
+			line := pending[:i]
+			pending = pending[i+1:]
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+
+			var record interface{}
+			if err := json.Unmarshal(line, &record); err != nil {
+				return err
+			}
+			if !handle(record) {
+				return nil
+			}
+		}
+	}
+
+	return stream.Err()
+}
+
+// buildS3SelectInputSerialization describes the format of the object from
+// the quals, defaulting to the format and compression of the key's
+// extension, e.g. .csv.gz
+func buildS3SelectInputSerialization(d *plugin.QueryData, key string) (*types.InputSerialization, error) {
+	name := strings.ToLower(key)
+
+	compression := strings.ToUpper(d.EqualsQualString("compression_type"))
+	if compression == "" {
+		switch {
+		case strings.HasSuffix(name, ".gz"):
+			compression = "GZIP"
+		case strings.HasSuffix(name, ".bz2"):
+			compression = "BZIP2"
+		default:
+			compression = "NONE"
+		}
+	}
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ".bz2")
+
+	format := strings.ToUpper(d.EqualsQualString("input_serialization"))
+	if format == "" {
+		switch {
+		case strings.HasSuffix(name, ".parquet"):
+			format = "PARQUET"
+		case strings.HasSuffix(name, ".json"), strings.HasSuffix(name, ".jsonl"), strings.HasSuffix(name, ".ndjson"):
+			format = "JSON"
+		default:
+			format = "CSV"
+		}
+	}
+
+	serialization := &types.InputSerialization{
+		CompressionType: types.CompressionType(compression),
+	}
+	switch format {
+	case "CSV":
+		serialization.CSV = &types.CSVInput{
+			FileHeaderInfo: types.FileHeaderInfoUse,
+		}
+		if fileHeaderInfo := d.EqualsQualString("file_header_info"); fileHeaderInfo != "" {
+			serialization.CSV.FileHeaderInfo = types.FileHeaderInfo(strings.ToUpper(fileHeaderInfo))
+		}
+		if fieldDelimiter := d.EqualsQualString("field_delimiter"); fieldDelimiter != "" {
+			serialization.CSV.FieldDelimiter = aws.String(fieldDelimiter)
+		}
+	case "JSON":
+		serialization.JSON = &types.JSONInput{
+			Type: types.JSONTypeLines,
+		}
+		if jsonType := d.EqualsQualString("json_type"); jsonType != "" {
+			serialization.JSON.Type = types.JSONType(strings.ToUpper(jsonType))
+		}
+	case "PARQUET":
+		// Parquet objects are compressed by column, not as a whole
+		serialization.CompressionType = types.CompressionTypeNone
+		serialization.Parquet = &types.ParquetInput{}
+	default:
+		return nil, fmt.Errorf("aws_s3_object_select: unsupported input_serialization %q, must be one of CSV, JSON or PARQUET", format)
+	}
+
+	return serialization, nil
+}
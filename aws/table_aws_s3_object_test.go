@@ -0,0 +1,24 @@
+package aws
+
+import "testing"
+
+func TestLikePatternPrefix(t *testing.T) {
+	cases := map[string]string{
+		"logs/2024/%":  "logs/2024/",
+		"logs/2024/01": "logs/2024/01",
+		"logs/_/%":     "logs/",
+		`logs\_2024/%`: "logs_2024/",
+		`100\%/data%`:  "100%/data",
+		"%.csv":        "",
+		`trailing\`:    `trailing\`,
+		"":             "",
+	}
+
+	for pattern, want := range cases {
+		t.Run(pattern, func(t *testing.T) {
+			if got := likePatternPrefix(pattern); got != want {
+				t.Errorf("likePatternPrefix(%q) = %q, want %q", pattern, got, want)
+			}
+		})
+	}
+}
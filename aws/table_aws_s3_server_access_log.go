@@ -0,0 +1,87 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsS3ServerAccessLog(_ context.Context) *plugin.Table {
+	return accessLogTable(accessLogDefinition{
+		Name:        "aws_s3_server_access_log",
+		Description: "AWS S3 Server Access Log",
+		Parse:       parseS3ServerAccessLog,
+		FilterFields: map[string]string{
+			"bucket":      "",
+			"operation":   "",
+			"http_status": "",
+			"requester":   "",
+		},
+		Columns: []*plugin.Column{
+			{Name: "bucket_owner", Type: proto.ColumnType_STRING, Description: "The canonical user ID of the owner of the source bucket."},
+			{Name: "bucket", Type: proto.ColumnType_STRING, Description: "The name of the bucket that the request was processed against."},
+			{Name: "remote_ip", Type: proto.ColumnType_IPADDR, Description: "The apparent IP address of the requester."},
+			{Name: "requester", Type: proto.ColumnType_STRING, Description: "The canonical user ID or IAM ARN of the requester, or null for unauthenticated requests."},
+			{Name: "request_id", Type: proto.ColumnType_STRING, Description: "A string generated by Amazon S3 to uniquely identify each request."},
+			{Name: "operation", Type: proto.ColumnType_STRING, Description: "The operation, e.g. REST.GET.OBJECT or S3.TRANSITION_INT.OBJECT."},
+			{Name: "key", Type: proto.ColumnType_STRING, Description: "The key of the object of the request, URL-encoded."},
+			{Name: "request_uri", Type: proto.ColumnType_STRING, Description: "The Request-URI part of the HTTP request message."},
+			{Name: "request_method", Type: proto.ColumnType_STRING, Description: "The HTTP method of the request."},
+			{Name: "request_url", Type: proto.ColumnType_STRING, Description: "The URL of the request."},
+			{Name: "request_http_version", Type: proto.ColumnType_STRING, Description: "The HTTP version of the request."},
+			{Name: "http_status", Type: proto.ColumnType_INT, Description: "The numeric HTTP status code of the response."},
+			{Name: "error_code", Type: proto.ColumnType_STRING, Description: "The Amazon S3 error code, if the request failed."},
+			{Name: "bytes_sent", Type: proto.ColumnType_INT, Description: "The number of response bytes sent, excluding HTTP protocol overhead."},
+			{Name: "object_size", Type: proto.ColumnType_INT, Description: "The total size of the object in question."},
+			{Name: "total_time", Type: proto.ColumnType_INT, Description: "The number of milliseconds that the request was in flight from the server's perspective."},
+			{Name: "turn_around_time", Type: proto.ColumnType_INT, Description: "The number of milliseconds that Amazon S3 spent processing the request."},
+			{Name: "referer", Type: proto.ColumnType_STRING, Description: "The value of the HTTP Referer header, if present."},
+			{Name: "user_agent", Type: proto.ColumnType_STRING, Description: "The value of the HTTP User-Agent header."},
+			{Name: "version_id", Type: proto.ColumnType_STRING, Description: "The version ID in the request."},
+			{Name: "host_id", Type: proto.ColumnType_STRING, Description: "The x-amz-id-2 or Amazon S3 extended request ID."},
+			{Name: "signature_version", Type: proto.ColumnType_STRING, Description: "The signature version, SigV2 or SigV4, that was used to authenticate the request."},
+			{Name: "cipher_suite", Type: proto.ColumnType_STRING, Description: "The Transport Layer Security (TLS) cipher that was negotiated for an HTTPS request."},
+			{Name: "authentication_type", Type: proto.ColumnType_STRING, Description: "The type of request authentication used. Possible values are: AuthHeader|QueryString."},
+			{Name: "host_header", Type: proto.ColumnType_STRING, Description: "The endpoint used to connect to Amazon S3."},
+			{Name: "tls_version", Type: proto.ColumnType_STRING, Description: "The Transport Layer Security (TLS) version negotiated by the client."},
+			{Name: "access_point_arn", Type: proto.ColumnType_STRING, Description: "The Amazon Resource Name (ARN) of the access point of the request."},
+			{Name: "acl_required", Type: proto.ColumnType_STRING, Description: "Whether the request required an access control list (ACL) for authorization. The value is Yes if it did."},
+		},
+	})
+}
+
+//// PARSE FUNCTIONS
+
+// The fields of an S3 server access log record, in order
+var s3ServerAccessLogFieldNames = []string{
+	"bucket_owner", "bucket", "time", "remote_ip", "requester", "request_id",
+	"operation", "key", "request_uri", "http_status", "error_code", "bytes_sent",
+	"object_size", "total_time", "turn_around_time", "referer", "user_agent",
+	"version_id", "host_id", "signature_version", "cipher_suite",
+	"authentication_type", "host_header", "tls_version", "access_point_arn", "acl_required",
+}
+
+// The time of a record, e.g. [06/Feb/2019:00:00:38 +0000]
+const s3ServerAccessLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+func parseS3ServerAccessLog(line string) (map[string]interface{}, time.Time, bool) {
+	values := splitAccessLogLine(line)
+	if len(values) < 10 {
+		return nil, time.Time{}, false
+	}
+
+	fields := accessLogFields(s3ServerAccessLogFieldNames, values)
+	timestamp, err := time.Parse(s3ServerAccessLogTimeLayout, values[2])
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	delete(fields, "time")
+
+	splitAccessLogRequest(fields, "request_uri", "request_")
+
+	return fields, timestamp, true
+}
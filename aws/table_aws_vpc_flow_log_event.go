@@ -2,14 +2,19 @@ package aws
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 
 	cloudwatchlogsv1 "github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/memoize"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
@@ -51,20 +56,34 @@ func tableAwsVpcFlowLogEvent(_ context.Context) *plugin.Table {
 			{Name: "log_group_name", Type: proto.ColumnType_STRING, Transform: transform.FromQual("log_group_name"), Description: "The name of the log group to which this event belongs."},
 			{Name: "log_stream_name", Type: proto.ColumnType_STRING, Description: "The name of the log stream to which this event belongs."},
 			{Name: "timestamp", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("Timestamp").Transform(transform.UnixMsToTimestamp), Description: "The time when the event occurred."},
-			{Name: "version", Type: proto.ColumnType_INT, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 0), Description: "The VPC Flow Logs version. If you use the default format, the version is 2. If you use a custom format, the version is the highest version among the specified fields. For example, if you specify only fields from version 2, the version is 2. If you specify a mixture of fields from versions 2, 3, and 4, the version is 4."},
-			{Name: "interface_account_id", Type: proto.ColumnType_STRING, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 1), Description: "The AWS account ID of the owner of the source network interface for which traffic is recorded. If the network interface is created by an AWS service, for example when creating a VPC endpoint or Network Load Balancer, the record may display unknown for this field."},
-			{Name: "interface_id", Type: proto.ColumnType_STRING, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 2), Description: "The ID of the network interface for which the traffic is recorded."},
-			{Name: "src_addr", Type: proto.ColumnType_IPADDR, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 3), Description: "The source address for incoming traffic, or the IPv4 or IPv6 address of the network interface for outgoing traffic on the network interface. The IPv4 address of the network interface is always its private IPv4 address. See also pkt-srcaddr."},
-			{Name: "dst_addr", Type: proto.ColumnType_IPADDR, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 4), Description: "The destination address for outgoing traffic, or the IPv4 or IPv6 address of the network interface for incoming traffic on the network interface. The IPv4 address of the network interface is always its private IPv4 address. See also pkt-dstaddr."},
-			{Name: "src_port", Type: proto.ColumnType_INT, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 5), Description: "The source port of the traffic."},
-			{Name: "dst_port", Type: proto.ColumnType_INT, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 6), Description: "The destination port of the traffic."},
-			{Name: "protocol", Type: proto.ColumnType_INT, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 7), Description: "The IANA protocol number of the traffic. For more information, see Assigned Internet Protocol Numbers."},
-			{Name: "packets", Type: proto.ColumnType_INT, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 8), Description: "The number of packets transferred during the flow."},
-			{Name: "bytes", Type: proto.ColumnType_INT, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 9), Description: "The number of bytes transferred during the flow."},
-			{Name: "start", Type: proto.ColumnType_TIMESTAMP, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 10).Transform(transform.UnixToTimestamp), Description: "The time when the first packet of the flow was received within the aggregation interval. This might be up to 60 seconds after the packet was transmitted or received on the network interface."},
-			{Name: "end", Type: proto.ColumnType_TIMESTAMP, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 11).Transform(transform.UnixToTimestamp), Description: "The time when the last packet of the flow was received within the aggregation interval. This might be up to 60 seconds after the packet was transmitted or received on the network interface."},
-			{Name: "action", Type: proto.ColumnType_STRING, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 12), Description: "The action that is associated with the traffic: ACCEPT — The recorded traffic was permitted by the security groups and network ACLs. REJECT — The recorded traffic was not permitted by the security groups or network ACLs."},
-			{Name: "log_status", Type: proto.ColumnType_STRING, Hydrate: getMessageField, Transform: transform.FromValue().TransformP(getField, 13), Description: "The logging status of the flow log: OK — Data is logging normally to the chosen destinations. NODATA — There was no network traffic to or from the network interface during the aggregation interval. SKIPDATA — Some flow log records were skipped during the aggregation interval. This may be because of an internal capacity constraint, or an internal error."},
+			{Name: "version", Type: proto.ColumnType_INT, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "version"), Description: "The VPC Flow Logs version. If you use the default format, the version is 2. If you use a custom format, the version is the highest version among the specified fields. For example, if you specify only fields from version 2, the version is 2. If you specify a mixture of fields from versions 2, 3, and 4, the version is 4."},
+			{Name: "interface_account_id", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "account-id"), Description: "The AWS account ID of the owner of the source network interface for which traffic is recorded. If the network interface is created by an AWS service, for example when creating a VPC endpoint or Network Load Balancer, the record may display unknown for this field."},
+			{Name: "interface_id", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "interface-id"), Description: "The ID of the network interface for which the traffic is recorded."},
+			{Name: "src_addr", Type: proto.ColumnType_IPADDR, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "srcaddr"), Description: "The source address for incoming traffic, or the IPv4 or IPv6 address of the network interface for outgoing traffic on the network interface. The IPv4 address of the network interface is always its private IPv4 address. See also pkt-srcaddr."},
+			{Name: "dst_addr", Type: proto.ColumnType_IPADDR, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "dstaddr"), Description: "The destination address for outgoing traffic, or the IPv4 or IPv6 address of the network interface for incoming traffic on the network interface. The IPv4 address of the network interface is always its private IPv4 address. See also pkt-dstaddr."},
+			{Name: "src_port", Type: proto.ColumnType_INT, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "srcport"), Description: "The source port of the traffic."},
+			{Name: "dst_port", Type: proto.ColumnType_INT, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "dstport"), Description: "The destination port of the traffic."},
+			{Name: "protocol", Type: proto.ColumnType_INT, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "protocol"), Description: "The IANA protocol number of the traffic. For more information, see Assigned Internet Protocol Numbers."},
+			{Name: "packets", Type: proto.ColumnType_INT, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "packets"), Description: "The number of packets transferred during the flow."},
+			{Name: "bytes", Type: proto.ColumnType_INT, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "bytes"), Description: "The number of bytes transferred during the flow."},
+			{Name: "start", Type: proto.ColumnType_TIMESTAMP, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "start").Transform(transform.UnixToTimestamp), Description: "The time when the first packet of the flow was received within the aggregation interval. This might be up to 60 seconds after the packet was transmitted or received on the network interface."},
+			{Name: "end", Type: proto.ColumnType_TIMESTAMP, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "end").Transform(transform.UnixToTimestamp), Description: "The time when the last packet of the flow was received within the aggregation interval. This might be up to 60 seconds after the packet was transmitted or received on the network interface."},
+			{Name: "action", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "action"), Description: "The action that is associated with the traffic: ACCEPT — The recorded traffic was permitted by the security groups and network ACLs. REJECT — The recorded traffic was not permitted by the security groups or network ACLs."},
+			{Name: "log_status", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "log-status"), Description: "The logging status of the flow log: OK — Data is logging normally to the chosen destinations. NODATA — There was no network traffic to or from the network interface during the aggregation interval. SKIPDATA — Some flow log records were skipped during the aggregation interval. This may be because of an internal capacity constraint, or an internal error."},
+			{Name: "vpc_id", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "vpc-id"), Description: "The ID of the VPC that contains the network interface for which the traffic is recorded. Only available if the flow log format includes it."},
+			{Name: "subnet_id", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "subnet-id"), Description: "The ID of the subnet that contains the network interface for which the traffic is recorded. Only available if the flow log format includes it."},
+			{Name: "instance_id", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "instance-id"), Description: "The ID of the instance that's associated with network interface for which the traffic is recorded, if the instance is owned by you. Only available if the flow log format includes it."},
+			{Name: "tcp_flags", Type: proto.ColumnType_INT, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "tcp-flags"), Description: "The bitmask value for the following TCP flags: FIN — 1, SYN — 2, RST — 4, SYN-ACK — 18. Flags seen during the aggregation interval are OR-ed together. Only available if the flow log format includes it."},
+			{Name: "type", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "type"), Description: "The type of traffic. Possible values are: IPv4|IPv6|EFA. Only available if the flow log format includes it."},
+			{Name: "pkt_srcaddr", Type: proto.ColumnType_IPADDR, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "pkt-srcaddr"), Description: "The packet-level (original) source IP address of the traffic. Use it with src_addr to distinguish between the IP address of an intermediate layer through which traffic flows, and the original source IP address. Only available if the flow log format includes it."},
+			{Name: "pkt_dstaddr", Type: proto.ColumnType_IPADDR, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "pkt-dstaddr"), Description: "The packet-level (original) destination IP address for the traffic. Use it with dst_addr to distinguish between the IP address of an intermediate layer through which traffic flows, and the final destination IP address. Only available if the flow log format includes it."},
+			{Name: "az_id", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "az-id"), Description: "The ID of the Availability Zone that contains the network interface for which traffic is recorded. Only available if the flow log format includes it."},
+			{Name: "sublocation_type", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "sublocation-type"), Description: "The type of sublocation that's returned in the sublocation_id field. Possible values are: wavelength|outpost|localzone. Only available if the flow log format includes it."},
+			{Name: "sublocation_id", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "sublocation-id"), Description: "The ID of the sublocation that contains the network interface for which traffic is recorded. Only available if the flow log format includes it."},
+			{Name: "pkt_src_aws_service", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "pkt-src-aws-service"), Description: "The name of the subset of IP address ranges for the pkt_srcaddr field, if the source IP address is for an AWS service. Only available if the flow log format includes it."},
+			{Name: "pkt_dst_aws_service", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "pkt-dst-aws-service"), Description: "The name of the subset of IP address ranges for the pkt_dstaddr field, if the destination IP address is for an AWS service. Only available if the flow log format includes it."},
+			{Name: "flow_direction", Type: proto.ColumnType_STRING, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "flow-direction"), Description: "The direction of the flow with respect to the interface where traffic is captured. Possible values are: ingress|egress. Only available if the flow log format includes it."},
+			{Name: "traffic_path", Type: proto.ColumnType_INT, Hydrate: getVpcFlowLogEventFields, Transform: transform.FromValue().TransformP(getField, "traffic-path"), Description: "The path that egress traffic takes to the destination, e.g. 1 — through another resource in the same VPC, 8 — through a VPC peering connection. Only available if the flow log format includes it."},
 			// Other columns
 			{Name: "event_id", Description: "The ID of the event.", Type: proto.ColumnType_STRING, Transform: transform.FromField("EventId")},
 			{Name: "filter", Description: "Filter pattern for the search.", Type: proto.ColumnType_STRING, Transform: transform.FromQual("filter")},
@@ -73,19 +92,119 @@ func tableAwsVpcFlowLogEvent(_ context.Context) *plugin.Table {
 	}
 }
 
-func getMessageField(_ context.Context, _ *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+// The default format of flow log records, used by flow logs without a custom
+// log_format
+const vpcFlowLogDefaultFormat = "${version} ${account-id} ${interface-id} ${srcaddr} ${dstaddr} ${srcport} ${dstport} ${protocol} ${packets} ${bytes} ${start} ${end} ${action} ${log-status}"
+
+// getVpcFlowLogEventFields splits the message of the event into fields, keyed
+// by their name in the log format of the flow log that writes to the log group.
+func getVpcFlowLogEventFields(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 	e := h.Item.(types.FilteredLogEvent)
-	fields := strings.Fields(*e.Message)
+	values := strings.Fields(*e.Message)
+
+	formats, err := getVpcFlowLogFormatsMemoized(ctx, d, h)
+	if err != nil {
+		return nil, err
+	}
+
+	// Several flow logs, with different formats, may write to the same log
+	// group. Use the first format that has as many fields as the record.
+	fieldNames := formats.([][]string)[0]
+	for _, names := range formats.([][]string) {
+		if len(names) == len(values) {
+			fieldNames = names
+			break
+		}
+	}
+
+	fields := map[string]string{}
+	for i, name := range fieldNames {
+		if i < len(values) {
+			fields[name] = values[i]
+		}
+	}
 	return fields, nil
 }
 
+var getVpcFlowLogFormatsMemoized = plugin.HydrateFunc(getVpcFlowLogFormatsUncached).Memoize(memoize.WithCacheKeyFunction(getVpcFlowLogFormatsCacheKey))
+
+// The flow logs are looked up per log group, and log groups are per region
+func getVpcFlowLogFormatsCacheKey(_ context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	key := fmt.Sprintf("getVpcFlowLogFormats-%s-%s", d.EqualsQualString(matrixKeyRegion), d.EqualsQualString("log_group_name"))
+	return key, nil
+}
+
+// getVpcFlowLogFormatsUncached returns the field names of the distinct log
+// formats of the flow logs that write to the log group. Log groups without
+// flow logs, e.g. of flow logs that have been deleted, and log groups whose
+// flow logs can't be described use the default format.
+func getVpcFlowLogFormatsUncached(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// Create session
+	svc, err := EC2Client(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_vpc_flow_log_event.getVpcFlowLogFormatsUncached", "connection_error", err)
+		return nil, err
+	}
+
+	input := &ec2.DescribeFlowLogsInput{
+		Filter: []ec2Types.Filter{
+			{
+				Name:   aws.String("log-group-name"),
+				Values: []string{d.EqualsQualString("log_group_name")},
+			},
+		},
+	}
+
+	var formats [][]string
+	seen := map[string]bool{}
+	paginator := ec2.NewDescribeFlowLogsPaginator(svc, input, func(o *ec2.DescribeFlowLogsPaginatorOptions) {
+		o.StopOnDuplicateToken = true
+	})
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			// The format is only needed to name the fields, so records can
+			// still be read with the default format, e.g. without the
+			// ec2:DescribeFlowLogs permission
+			plugin.Logger(ctx).Warn("aws_vpc_flow_log_event.getVpcFlowLogFormatsUncached", "api_error", err)
+			return [][]string{vpcFlowLogFormatFieldNames(vpcFlowLogDefaultFormat)}, nil
+		}
+		for _, flowLog := range output.FlowLogs {
+			format := aws.ToString(flowLog.LogFormat)
+			if format == "" || seen[format] {
+				continue
+			}
+			seen[format] = true
+			formats = append(formats, vpcFlowLogFormatFieldNames(format))
+		}
+	}
+
+	if len(formats) == 0 {
+		formats = append(formats, vpcFlowLogFormatFieldNames(vpcFlowLogDefaultFormat))
+	}
+	return formats, nil
+}
+
+// vpcFlowLogFormatFieldNames returns the field names of a log format, e.g.
+// [version vpc-id srcaddr] for "${version} ${vpc-id} ${srcaddr}"
+func vpcFlowLogFormatFieldNames(format string) []string {
+	var names []string
+	for _, field := range strings.Fields(format) {
+		names = append(names, strings.TrimSuffix(strings.TrimPrefix(field, "${"), "}"))
+	}
+	return names
+}
+
 func getField(_ context.Context, d *transform.TransformData) (interface{}, error) {
-	fields := d.Value.([]string)
-	idx := d.Param.(int)
-	if fields[idx] == "-" {
+	fields := d.Value.(map[string]string)
+	value, ok := fields[d.Param.(string)]
+	if !ok || value == "-" {
 		return nil, nil
 	}
-	return fields[idx], nil
+	return value, nil
 }
 
 func buildFilter(equalQuals plugin.KeyColumnEqualsQualMap) []string {
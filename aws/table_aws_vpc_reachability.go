@@ -0,0 +1,758 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	ec2v1 "github.com/aws/aws-sdk-go/service/ec2"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsVpcReachability(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_vpc_reachability",
+		Description: "AWS VPC Reachability",
+		List: &plugin.ListConfig{
+			Hydrate: listVpcReachability,
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "source", Require: plugin.Required, CacheMatch: "exact"},
+				{Name: "destination", Require: plugin.Required, CacheMatch: "exact"},
+				{Name: "protocol", Require: plugin.Optional, CacheMatch: "exact"},
+				{Name: "port", Require: plugin.Optional, CacheMatch: "exact"},
+			},
+		},
+		GetMatrixItemFunc: SupportedRegionMatrix(ec2v1.EndpointsID),
+		Columns: awsRegionalColumns([]*plugin.Column{
+			{
+				Name:        "source",
+				Description: "The ID of the network interface, or the IP address or CIDR block, that traffic is sent from.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("source"),
+			},
+			{
+				Name:        "destination",
+				Description: "The ID of the network interface, or the IP address or CIDR block, that traffic is sent to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("destination"),
+			},
+			{
+				Name:        "protocol",
+				Description: "The protocol of the traffic, as a name (tcp, udp, icmp, icmpv6) or protocol number. Defaults to tcp.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Protocol"),
+			},
+			{
+				Name:        "port",
+				Description: "The destination port of the traffic. Required for tcp and udp.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromQual("port"),
+			},
+			{
+				Name:        "reachable",
+				Description: "True if the security groups, network ACLs and routes on the path allow the traffic.",
+				Type:        proto.ColumnType_BOOL,
+			},
+			{
+				Name:        "blocked_by",
+				Description: "The ID of the first component on the path that blocks the traffic.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "blocked_reason",
+				Description: "Why the first blocking component blocks the traffic.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "blocked_by_rule",
+				Description: "The network ACL entry or route that blocks the traffic, if a rule rather than the lack of one blocks it.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "path",
+				Description: "The components the traffic passes through in order, including the return traffic checks for stateless network ACLs.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "source_ip",
+				Description: "The IP address of the source that is evaluated.",
+				Type:        proto.ColumnType_IPADDR,
+			},
+			{
+				Name:        "destination_ip",
+				Description: "The IP address of the destination that is evaluated.",
+				Type:        proto.ColumnType_IPADDR,
+			},
+			{
+				Name:        "source_cidr",
+				Description: "The addresses of the source that source_ip is evaluated for: the part of a source CIDR block in one subnet, or outside the subnets of the region.",
+				Type:        proto.ColumnType_CIDR,
+			},
+			{
+				Name:        "destination_cidr",
+				Description: "The addresses of the destination that destination_ip is evaluated for: the part of a destination CIDR block in one subnet, or outside the subnets of the region.",
+				Type:        proto.ColumnType_CIDR,
+			},
+			{
+				Name:        "source_vpc_id",
+				Description: "The ID of the VPC of the source, if it is in a VPC.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "destination_vpc_id",
+				Description: "The ID of the VPC of the destination, if it is in a VPC.",
+				Type:        proto.ColumnType_STRING,
+			},
+		}),
+	}
+}
+
+// vpcReachability is the result of evaluating traffic between two endpoints
+type vpcReachability struct {
+	Protocol         string
+	Reachable        bool
+	BlockedBy        string
+	BlockedReason    string
+	BlockedByRule    interface{}
+	Path             []vpcReachabilityHop
+	SourceIp         string
+	DestinationIp    string
+	SourceCidr       string
+	DestinationCidr  string
+	SourceVpcId      string
+	DestinationVpcId string
+}
+
+// vpcReachabilityHop is a component on the path of traffic
+type vpcReachabilityHop struct {
+	Component string
+	Id        string
+	Direction string
+	Allowed   bool
+	Detail    string
+	Rule      interface{} `json:",omitempty"`
+}
+
+// vpcReachabilityEndpoint is the source or destination of traffic. Ip is
+// evaluated for all the addresses of Range.
+type vpcReachabilityEndpoint struct {
+	Ip               netip.Addr
+	Range            netip.Prefix
+	NetworkInterface *types.NetworkInterface
+	Subnet           *types.Subnet
+}
+
+func (e vpcReachabilityEndpoint) vpcId() string {
+	if e.Subnet == nil {
+		return ""
+	}
+	return aws.ToString(e.Subnet.VpcId)
+}
+
+func (e vpcReachabilityEndpoint) groupIds() []string {
+	var ids []string
+	if e.NetworkInterface != nil {
+		for _, group := range e.NetworkInterface.Groups {
+			ids = append(ids, aws.ToString(group.GroupId))
+		}
+	}
+	return ids
+}
+
+func (e vpcReachabilityEndpoint) publicIp() string {
+	if e.NetworkInterface == nil || e.NetworkInterface.Association == nil {
+		return ""
+	}
+	return aws.ToString(e.NetworkInterface.Association.PublicIp)
+}
+
+// The ports that return traffic is sent to
+const (
+	vpcEphemeralPortFrom = 1024
+	vpcEphemeralPortTo   = 65535
+)
+
+//// LIST FUNCTION
+
+func listVpcReachability(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	protocol := ipProtocolNumber(d.EqualsQualString("protocol"))
+	if d.EqualsQualString("protocol") == "" {
+		protocol = "6"
+	}
+	var port int32
+	if d.EqualsQuals["port"] != nil {
+		port = int32(d.EqualsQuals["port"].GetInt64Value())
+	} else if ipProtocolHasPorts(protocol) {
+		return nil, fmt.Errorf("port must be specified for protocol %s", formatIpPermissionPorts(protocol, 0, 0))
+	}
+
+	// Create session
+	svc, err := EC2Client(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_vpc_reachability.listVpcReachability", "connection_error", err)
+		return nil, err
+	}
+
+	network := newVpcNetwork(ctx, d, svc)
+
+	sources, err := resolveVpcReachabilityEndpoints(network, d.EqualsQualString("source"))
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_vpc_reachability.listVpcReachability", "api_error", err)
+		return nil, err
+	}
+	destinations, err := resolveVpcReachabilityEndpoints(network, d.EqualsQualString("destination"))
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_vpc_reachability.listVpcReachability", "api_error", err)
+		return nil, err
+	}
+
+	// A row is returned for each part of a CIDR block that is evaluated, so
+	// some of its addresses may be reachable and others not
+	for _, source := range sources {
+		for _, destination := range destinations {
+			// Only evaluate traffic in the region of the VPCs it starts or ends in
+			if source.Subnet == nil && destination.Subnet == nil {
+				continue
+			}
+
+			result, err := evaluateVpcReachability(network, source, destination, protocol, port)
+			if err != nil {
+				plugin.Logger(ctx).Error("aws_vpc_reachability.listVpcReachability", "api_error", err)
+				return nil, err
+			}
+			result.Protocol = d.EqualsQualString("protocol")
+			if result.Protocol == "" {
+				result.Protocol = "tcp"
+			}
+			d.StreamListItem(ctx, result)
+
+			// Context can be cancelled due to manual cancellation or the limit has been hit
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// resolveVpcReachabilityEndpoints finds the network interface and subnet of
+// an endpoint. There are none for a network interface that is not in the
+// region. A CIDR block is split into the parts of it in each subnet, and the
+// first part outside them, and the first address of each part is evaluated.
+func resolveVpcReachabilityEndpoints(network *vpcNetwork, value string) ([]vpcReachabilityEndpoint, error) {
+	value = strings.TrimSpace(value)
+
+	if strings.HasPrefix(value, "eni-") {
+		networkInterface, err := network.networkInterface(value)
+		if err != nil || networkInterface == nil {
+			return nil, err
+		}
+		ip, err := netip.ParseAddr(aws.ToString(networkInterface.PrivateIpAddress))
+		if err != nil {
+			return nil, fmt.Errorf("network interface %s has no private IP address", value)
+		}
+		subnet, err := network.subnet(aws.ToString(networkInterface.SubnetId))
+		if err != nil {
+			return nil, err
+		}
+		return []vpcReachabilityEndpoint{{Ip: ip, Range: netip.PrefixFrom(ip, ip.BitLen()), NetworkInterface: networkInterface, Subnet: subnet}}, nil
+	}
+
+	block, err := netip.ParsePrefix(value)
+	if err != nil {
+		ip, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a network interface ID, IP address or CIDR block", value)
+		}
+		block = netip.PrefixFrom(ip, ip.BitLen())
+	}
+	block = block.Masked()
+
+	var ranges []netip.Prefix
+	if block.IsSingleIP() {
+		ranges = []netip.Prefix{block}
+	} else {
+		subnets, err := network.allSubnets()
+		if err != nil {
+			return nil, err
+		}
+		var subnetBlocks []netip.Prefix
+		for _, subnet := range subnets {
+			subnetBlocks = append(subnetBlocks, subnetCidrBlocks(subnet)...)
+		}
+		ranges = splitVpcReachabilityRange(block, subnetBlocks)
+	}
+
+	endpoints := make([]vpcReachabilityEndpoint, 0, len(ranges))
+	for _, addresses := range ranges {
+		endpoint := vpcReachabilityEndpoint{Ip: addresses.Addr(), Range: addresses}
+		// The security groups of a network interface only apply to its own
+		// address, so they are not evaluated for wider ranges
+		if addresses.IsSingleIP() {
+			endpoint.NetworkInterface, err = network.networkInterfaceByIp(endpoint.Ip)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if endpoint.NetworkInterface != nil {
+			endpoint.Subnet, err = network.subnet(aws.ToString(endpoint.NetworkInterface.SubnetId))
+		} else {
+			endpoint.Subnet, err = network.subnetContaining(endpoint.Ip)
+		}
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+// vpcReachabilityPath records the hops of traffic until one blocks it
+type vpcReachabilityPath struct {
+	result *vpcReachability
+}
+
+// add appends a hop to the path. It returns false if the hop blocks the
+// traffic, which is then recorded as the first block.
+func (p *vpcReachabilityPath) add(hop vpcReachabilityHop) bool {
+	p.result.Path = append(p.result.Path, hop)
+	if !hop.Allowed && p.result.Reachable {
+		p.result.Reachable = false
+		p.result.BlockedBy = hop.Id
+		p.result.BlockedReason = hop.Detail
+		p.result.BlockedByRule = hop.Rule
+	}
+	return hop.Allowed
+}
+
+func evaluateVpcReachability(network *vpcNetwork, source vpcReachabilityEndpoint, destination vpcReachabilityEndpoint, protocol string, port int32) (*vpcReachability, error) {
+	result := &vpcReachability{
+		Reachable:        true,
+		Path:             []vpcReachabilityHop{},
+		SourceIp:         source.Ip.String(),
+		DestinationIp:    destination.Ip.String(),
+		SourceCidr:       source.Range.String(),
+		DestinationCidr:  destination.Range.String(),
+		SourceVpcId:      source.vpcId(),
+		DestinationVpcId: destination.vpcId(),
+	}
+	path := &vpcReachabilityPath{result: result}
+
+	sameSubnet := source.Subnet != nil && destination.Subnet != nil &&
+		aws.ToString(source.Subnet.SubnetId) == aws.ToString(destination.Subnet.SubnetId)
+
+	returnFrom, returnTo := int32(vpcEphemeralPortFrom), int32(vpcEphemeralPortTo)
+	if !ipProtocolHasPorts(protocol) {
+		returnFrom, returnTo = port, port
+	}
+
+	// Outbound from the source
+	if source.Subnet != nil {
+		if source.NetworkInterface != nil {
+			ok, err := checkVpcSecurityGroups(network, path, source, true, protocol, port, destination)
+			if err != nil || !ok {
+				return result, err
+			}
+		}
+		if !sameSubnet {
+			ok, err := checkVpcNetworkAcl(network, path, *source.Subnet, true, protocol, port, port, destination.Ip, "outbound")
+			if err != nil || !ok {
+				return result, err
+			}
+			ok, err = checkVpcRoute(network, path, source, destination, "outbound")
+			if err != nil || !ok {
+				return result, err
+			}
+		}
+	} else {
+		// From the internet, traffic enters through an internet gateway to a
+		// public IP address
+		ok, err := checkVpcInternetIngress(network, path, destination)
+		if err != nil || !ok {
+			return result, err
+		}
+	}
+
+	// Inbound to the destination
+	if destination.Subnet != nil {
+		if !sameSubnet {
+			ok, err := checkVpcNetworkAcl(network, path, *destination.Subnet, false, protocol, port, port, source.Ip, "inbound")
+			if err != nil || !ok {
+				return result, err
+			}
+		}
+		if destination.NetworkInterface != nil {
+			ok, err := checkVpcSecurityGroups(network, path, destination, false, protocol, port, source)
+			if err != nil || !ok {
+				return result, err
+			}
+		}
+	}
+
+	// Security groups are stateful but network ACLs are not, so return
+	// traffic must also be allowed, and routed back to the source
+	if sameSubnet {
+		return result, nil
+	}
+	if destination.Subnet != nil {
+		ok, err := checkVpcNetworkAcl(network, path, *destination.Subnet, true, protocol, returnFrom, returnTo, source.Ip, "return outbound")
+		if err != nil || !ok {
+			return result, err
+		}
+		if source.Subnet == nil || source.vpcId() != destination.vpcId() {
+			ok, err = checkVpcRoute(network, path, destination, source, "return")
+			if err != nil || !ok {
+				return result, err
+			}
+		}
+	}
+	if source.Subnet != nil {
+		_, err := checkVpcNetworkAcl(network, path, *source.Subnet, false, protocol, returnFrom, returnTo, destination.Ip, "return inbound")
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+func checkVpcSecurityGroups(network *vpcNetwork, path *vpcReachabilityPath, endpoint vpcReachabilityEndpoint, egress bool, protocol string, port int32, peer vpcReachabilityEndpoint) (bool, error) {
+	groups, err := network.securityGroups(endpoint.groupIds())
+	if err != nil {
+		return false, err
+	}
+	permission, reason, err := evaluateSecurityGroups(groups, egress, protocol, port, securityGroupPeer{Ip: peer.Ip, GroupIds: peer.groupIds()}, network.prefixListCidrs)
+	if err != nil {
+		return false, err
+	}
+
+	direction := "inbound"
+	if egress {
+		direction = "outbound"
+	}
+	hop := vpcReachabilityHop{
+		Component: "security_group",
+		Id:        strings.Join(endpoint.groupIds(), ","),
+		Direction: direction,
+		Allowed:   permission != nil,
+		Detail:    fmt.Sprintf("%s on %s", reason, formatIpPermissionPorts(protocol, port, port)),
+	}
+	if permission != nil {
+		hop.Rule = permission
+	} else {
+		hop.Detail = fmt.Sprintf("no %s rule of the security groups of %s allows %s with %s", direction, aws.ToString(endpoint.NetworkInterface.NetworkInterfaceId), formatIpPermissionPorts(protocol, port, port), peer.Ip)
+	}
+	return path.add(hop), nil
+}
+
+func checkVpcNetworkAcl(network *vpcNetwork, path *vpcReachabilityPath, subnet types.Subnet, egress bool, protocol string, fromPort int32, toPort int32, peer netip.Addr, direction string) (bool, error) {
+	acl, err := network.subnetNetworkAcl(subnet)
+	if err != nil {
+		return false, err
+	}
+	if acl == nil {
+		return path.add(vpcReachabilityHop{
+			Component: "network_acl",
+			Id:        aws.ToString(subnet.SubnetId),
+			Direction: direction,
+			Allowed:   false,
+			Detail:    fmt.Sprintf("subnet %s has no network ACL", aws.ToString(subnet.SubnetId)),
+		}), nil
+	}
+
+	allowed, entry := evaluateNetworkAcl(acl.Entries, egress, protocol, fromPort, toPort, peer)
+	hop := vpcReachabilityHop{
+		Component: "network_acl",
+		Id:        aws.ToString(acl.NetworkAclId),
+		Direction: direction,
+		Allowed:   allowed,
+		Detail:    fmt.Sprintf("allows %s with %s", formatIpPermissionPorts(protocol, fromPort, toPort), peer),
+	}
+	if !allowed {
+		if entry != nil {
+			hop.Rule = entry
+			hop.Detail = fmt.Sprintf("rule %d denies %s with %s", aws.ToInt32(entry.RuleNumber), formatIpPermissionPorts(protocol, fromPort, toPort), peer)
+		} else {
+			hop.Detail = fmt.Sprintf("no rule allows %s with %s", formatIpPermissionPorts(protocol, fromPort, toPort), peer)
+		}
+	}
+	return path.add(hop), nil
+}
+
+// checkVpcRoute follows the route from the subnet of one endpoint to the
+// other, through peering connections and transit gateways
+func checkVpcRoute(network *vpcNetwork, path *vpcReachabilityPath, from vpcReachabilityEndpoint, to vpcReachabilityEndpoint, direction string) (bool, error) {
+	routeTable, err := network.subnetRouteTable(*from.Subnet)
+	if err != nil {
+		return false, err
+	}
+	if routeTable == nil {
+		return path.add(vpcReachabilityHop{
+			Component: "route_table",
+			Id:        aws.ToString(from.Subnet.SubnetId),
+			Direction: direction,
+			Allowed:   false,
+			Detail:    fmt.Sprintf("subnet %s has no route table", aws.ToString(from.Subnet.SubnetId)),
+		}), nil
+	}
+
+	route, err := selectVpcRoute(routeTable.Routes, to.Ip, network.prefixListCidrs)
+	if err != nil {
+		return false, err
+	}
+	hop := vpcReachabilityHop{
+		Component: "route_table",
+		Id:        aws.ToString(routeTable.RouteTableId),
+		Direction: direction,
+	}
+	if route == nil {
+		hop.Detail = fmt.Sprintf("no route to %s", to.Ip)
+		return path.add(hop), nil
+	}
+	hop.Rule = route
+	target := vpcRouteTarget(*route)
+	if route.State == types.RouteStateBlackhole {
+		hop.Detail = fmt.Sprintf("route to %s is a blackhole", vpcRouteDestination(*route))
+		return path.add(hop), nil
+	}
+	hop.Allowed = true
+	hop.Detail = fmt.Sprintf("routes %s to %s", vpcRouteDestination(*route), target)
+	path.add(hop)
+
+	switch {
+	case target == "local":
+		return true, nil
+
+	case strings.HasPrefix(target, "pcx-"):
+		return checkVpcPeeringConnection(network, path, target, from, to, direction)
+
+	case strings.HasPrefix(target, "tgw-"):
+		return checkVpcTransitGateway(network, path, target, from, to, direction)
+
+	case strings.HasPrefix(target, "vpce-"):
+		return checkVpcEndpoint(network, path, target, from, to, direction)
+
+	case strings.HasPrefix(target, "igw-"):
+		hop := vpcReachabilityHop{
+			Component: "internet_gateway",
+			Id:        target,
+			Direction: direction,
+		}
+		switch {
+		case to.Subnet != nil:
+			hop.Detail = fmt.Sprintf("%s is in a VPC and is not reachable through its private IP address from the internet", to.Ip)
+		case from.NetworkInterface == nil || from.publicIp() == "":
+			hop.Detail = fmt.Sprintf("%s has no public IP address", from.Ip)
+		default:
+			hop.Allowed = true
+			hop.Detail = fmt.Sprintf("sends traffic to the internet from %s", from.publicIp())
+		}
+		return path.add(hop), nil
+	}
+
+	// Other targets, such as NAT gateways, VPN gateways and appliances, lead
+	// out of the VPCs and are not followed further
+	hop = vpcReachabilityHop{
+		Component: vpcRouteTargetComponent(target),
+		Id:        target,
+		Direction: direction,
+		Allowed:   to.Subnet == nil,
+		Detail:    fmt.Sprintf("sends traffic out of %s; the path beyond it is not evaluated", from.vpcId()),
+	}
+	if to.Subnet != nil {
+		hop.Detail = fmt.Sprintf("does not lead to %s", to.vpcId())
+	}
+	return path.add(hop), nil
+}
+
+func checkVpcPeeringConnection(network *vpcNetwork, path *vpcReachabilityPath, id string, from vpcReachabilityEndpoint, to vpcReachabilityEndpoint, direction string) (bool, error) {
+	connection, err := network.vpcPeeringConnection(id)
+	if err != nil {
+		return false, err
+	}
+	hop := vpcReachabilityHop{
+		Component: "vpc_peering_connection",
+		Id:        id,
+		Direction: direction,
+	}
+	if connection == nil {
+		hop.Detail = "peering connection not found"
+		return path.add(hop), nil
+	}
+	if connection.Status == nil || connection.Status.Code != types.VpcPeeringConnectionStateReasonCodeActive {
+		hop.Detail = "peering connection is not active"
+		return path.add(hop), nil
+	}
+
+	peerVpcId := aws.ToString(connection.AccepterVpcInfo.VpcId)
+	if peerVpcId == from.vpcId() {
+		peerVpcId = aws.ToString(connection.RequesterVpcInfo.VpcId)
+	}
+	if to.Subnet != nil && to.vpcId() != peerVpcId {
+		hop.Detail = fmt.Sprintf("peers with %s, not %s", peerVpcId, to.vpcId())
+		return path.add(hop), nil
+	}
+	hop.Allowed = true
+	hop.Detail = fmt.Sprintf("peers with %s", peerVpcId)
+	return path.add(hop), nil
+}
+
+func checkVpcTransitGateway(network *vpcNetwork, path *vpcReachabilityPath, id string, from vpcReachabilityEndpoint, to vpcReachabilityEndpoint, direction string) (bool, error) {
+	route, routeTableId, err := network.transitGatewayRoute(id, from.vpcId(), to.Ip)
+	if err != nil {
+		return false, err
+	}
+	hop := vpcReachabilityHop{
+		Component: "transit_gateway",
+		Id:        id,
+		Direction: direction,
+	}
+	if routeTableId == "" {
+		hop.Detail = fmt.Sprintf("the attachment of %s is not associated with a route table", from.vpcId())
+		return path.add(hop), nil
+	}
+	hop.Id = routeTableId
+	if route == nil {
+		hop.Detail = fmt.Sprintf("no route to %s", to.Ip)
+		return path.add(hop), nil
+	}
+	hop.Rule = route
+	if route.State == types.TransitGatewayRouteStateBlackhole {
+		hop.Detail = fmt.Sprintf("route to %s is a blackhole", aws.ToString(route.DestinationCidrBlock))
+		return path.add(hop), nil
+	}
+
+	for _, attachment := range route.TransitGatewayAttachments {
+		if to.Subnet == nil || (attachment.ResourceType == types.TransitGatewayAttachmentResourceTypeVpc && aws.ToString(attachment.ResourceId) == to.vpcId()) {
+			hop.Allowed = true
+			hop.Detail = fmt.Sprintf("routes %s to %s (%s)", aws.ToString(route.DestinationCidrBlock), aws.ToString(attachment.TransitGatewayAttachmentId), aws.ToString(attachment.ResourceId))
+			return path.add(hop), nil
+		}
+	}
+	hop.Detail = fmt.Sprintf("route to %s does not lead to %s", aws.ToString(route.DestinationCidrBlock), to.vpcId())
+	return path.add(hop), nil
+}
+
+// checkVpcEndpoint follows a route to a VPC endpoint. Gateway endpoints lead
+// to the AWS service of the prefix list the route matched. Gateway Load
+// Balancer endpoints send traffic through the appliances of their service,
+// which return it to the endpoint's subnet to be routed on from there.
+func checkVpcEndpoint(network *vpcNetwork, path *vpcReachabilityPath, id string, from vpcReachabilityEndpoint, to vpcReachabilityEndpoint, direction string) (bool, error) {
+	endpoint, err := network.vpcEndpoint(id)
+	if err != nil {
+		return false, err
+	}
+	hop := vpcReachabilityHop{
+		Component: "vpc_endpoint",
+		Id:        id,
+		Direction: direction,
+	}
+	if endpoint == nil {
+		hop.Detail = "VPC endpoint not found"
+		return path.add(hop), nil
+	}
+	if !strings.EqualFold(string(endpoint.State), string(types.StateAvailable)) {
+		hop.Detail = fmt.Sprintf("VPC endpoint is %s", strings.ToLower(string(endpoint.State)))
+		return path.add(hop), nil
+	}
+	serviceName := aws.ToString(endpoint.ServiceName)
+
+	switch endpoint.VpcEndpointType {
+	case types.VpcEndpointTypeGateway:
+		if to.Subnet != nil {
+			hop.Detail = fmt.Sprintf("leads to %s, not %s", serviceName, to.vpcId())
+			return path.add(hop), nil
+		}
+		hop.Allowed = true
+		hop.Detail = fmt.Sprintf("sends traffic to %s; the endpoint policy is not evaluated", serviceName)
+		return path.add(hop), nil
+
+	case types.VpcEndpointTypeGatewayLoadBalancer:
+		// Routes that send traffic back to an endpoint it has passed through
+		// would loop
+		for _, previous := range path.result.Path {
+			if previous.Component == "vpc_endpoint" && previous.Id == id && previous.Direction == direction {
+				hop.Detail = "traffic is routed back to the endpoint in a loop"
+				return path.add(hop), nil
+			}
+		}
+		if len(endpoint.SubnetIds) == 0 {
+			hop.Detail = "VPC endpoint has no subnet"
+			return path.add(hop), nil
+		}
+		subnet, err := network.subnet(endpoint.SubnetIds[0])
+		if err != nil {
+			return false, err
+		}
+		if subnet == nil {
+			hop.Detail = fmt.Sprintf("subnet %s of the VPC endpoint not found", endpoint.SubnetIds[0])
+			return path.add(hop), nil
+		}
+
+		hop.Allowed = true
+		hop.Detail = fmt.Sprintf("sends traffic through the appliances of %s and back to %s; the appliances are not evaluated", serviceName, endpoint.SubnetIds[0])
+		path.add(hop)
+		return checkVpcRoute(network, path, vpcReachabilityEndpoint{Ip: from.Ip, Range: from.Range, NetworkInterface: from.NetworkInterface, Subnet: subnet}, to, direction)
+	}
+
+	hop.Detail = fmt.Sprintf("%s endpoints are reached through their network interfaces, not routes", endpoint.VpcEndpointType)
+	return path.add(hop), nil
+}
+
+// checkVpcInternetIngress checks that traffic from the internet can reach a
+// destination through an internet gateway
+func checkVpcInternetIngress(network *vpcNetwork, path *vpcReachabilityPath, destination vpcReachabilityEndpoint) (bool, error) {
+	hasGateway, err := network.vpcHasInternetGateway(destination.vpcId())
+	if err != nil {
+		return false, err
+	}
+	hop := vpcReachabilityHop{
+		Component: "internet_gateway",
+		Id:        destination.vpcId(),
+		Direction: "inbound",
+	}
+	switch {
+	case !hasGateway:
+		hop.Detail = fmt.Sprintf("%s has no internet gateway", destination.vpcId())
+	case destination.publicIp() == "":
+		hop.Detail = fmt.Sprintf("%s has no public IP address", destination.Ip)
+	default:
+		hop.Allowed = true
+		hop.Detail = fmt.Sprintf("receives traffic from the internet at %s", destination.publicIp())
+	}
+	return path.add(hop), nil
+}
+
+// vpcRouteTargetComponent returns the kind of component a route target ID is
+func vpcRouteTargetComponent(target string) string {
+	for prefix, component := range map[string]string{
+		"nat-":  "nat_gateway",
+		"vgw-":  "vpn_gateway",
+		"vpce-": "vpc_endpoint",
+		"eni-":  "network_interface",
+		"i-":    "instance",
+		"eigw-": "egress_only_internet_gateway",
+		"cagw-": "carrier_gateway",
+		"lgw-":  "local_gateway",
+		"arn:":  "core_network",
+		"local": "local",
+		"pcx-":  "vpc_peering_connection",
+		"tgw-":  "transit_gateway",
+		"igw-":  "internet_gateway",
+	} {
+		if strings.HasPrefix(target, prefix) {
+			return component
+		}
+	}
+	return "gateway"
+}
@@ -0,0 +1,263 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	ec2v1 "github.com/aws/aws-sdk-go/service/ec2"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsVpcRouteLookup(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_vpc_route_lookup",
+		Description: "AWS VPC Route Lookup",
+		List: &plugin.ListConfig{
+			Hydrate: listVpcRouteLookup,
+			Tags:    map[string]string{"service": "ec2", "action": "DescribeRouteTables"},
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "destination_ip", Require: plugin.Required, CacheMatch: "exact"},
+				{Name: "subnet_id", Require: plugin.AnyOf, CacheMatch: "exact"},
+				{Name: "route_table_id", Require: plugin.AnyOf, CacheMatch: "exact"},
+			},
+		},
+		GetMatrixItemFunc: SupportedRegionMatrix(ec2v1.EndpointsID),
+		Columns: awsRegionalColumns([]*plugin.Column{
+			{
+				Name:        "destination_ip",
+				Description: "The IP address to look up the route to.",
+				Type:        proto.ColumnType_IPADDR,
+				Transform:   transform.FromQual("destination_ip"),
+			},
+			{
+				Name:        "subnet_id",
+				Description: "The ID of the subnet whose route table is used.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("subnet_id"),
+			},
+			{
+				Name:        "route_table_id",
+				Description: "The ID of the route table. For a subnet, its explicitly associated route table, or the main route table of its VPC.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "vpc_id",
+				Description: "The ID of the VPC of the route table.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "route_found",
+				Description: "True if a route of the route table matches the destination IP address.",
+				Type:        proto.ColumnType_BOOL,
+			},
+			{
+				Name:        "matched_cidr_block",
+				Description: "The CIDR block of the winning route that contains the destination IP address. For a prefix list route, the entry of the prefix list.",
+				Type:        proto.ColumnType_CIDR,
+				Transform:   transform.FromField("MatchedCidrBlock").NullIfZero(),
+			},
+			{
+				Name:        "destination_cidr_block",
+				Description: "The IPv4 CIDR block of the winning route.",
+				Type:        proto.ColumnType_CIDR,
+				Transform:   transform.FromField("Route.DestinationCidrBlock"),
+			},
+			{
+				Name:        "destination_ipv6_cidr_block",
+				Description: "The IPv6 CIDR block of the winning route.",
+				Type:        proto.ColumnType_CIDR,
+				Transform:   transform.FromField("Route.DestinationIpv6CidrBlock"),
+			},
+			{
+				Name:        "destination_prefix_list_id",
+				Description: "The ID of the prefix list of the winning route.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Route.DestinationPrefixListId"),
+			},
+			{
+				Name:        "origin",
+				Description: "Describes how the winning route was created: CreateRouteTable, CreateRoute or EnableVgwRoutePropagation.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Route.Origin"),
+			},
+			{
+				Name:        "state",
+				Description: "The state of the winning route. Traffic to a blackhole route is dropped.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Route.State"),
+			},
+			{
+				Name:        "target_id",
+				Description: "The ID of the target of the winning route, such as a gateway, NAT gateway, transit gateway, peering connection or network interface.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("TargetId").NullIfZero(),
+			},
+			{
+				Name:        "target_type",
+				Description: "The kind of target of the winning route, e.g. local, internet_gateway, nat_gateway, transit_gateway or vpc_peering_connection.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("TargetType").NullIfZero(),
+			},
+			{
+				Name:        "peer_vpc_id",
+				Description: "The ID of the VPC at the other end of the peering connection, if the target is a peering connection.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("PeerVpcId").NullIfZero(),
+			},
+			{
+				Name:        "transit_gateway_route_table_id",
+				Description: "The ID of the transit gateway route table associated with the VPC's attachment, if the target is a transit gateway.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("TransitGatewayRouteTableId").NullIfZero(),
+			},
+			{
+				Name:        "transit_gateway_route",
+				Description: "The route of the transit gateway route table that the destination IP address matches, if the target is a transit gateway.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "route",
+				Description: "The winning route.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "matching_routes",
+				Description: "All the routes that match the destination IP address, in the order they are preferred.",
+				Type:        proto.ColumnType_JSON,
+			},
+		}),
+	}
+}
+
+// vpcRouteLookup is the route a route table selects for an IP address
+type vpcRouteLookup struct {
+	RouteTableId               *string
+	VpcId                      *string
+	RouteFound                 bool
+	MatchedCidrBlock           string
+	Route                      *types.Route
+	TargetId                   string
+	TargetType                 string
+	PeerVpcId                  string
+	TransitGatewayRouteTableId string
+	TransitGatewayRoute        *types.TransitGatewayRoute
+	MatchingRoutes             []vpcRouteLookupMatch
+}
+
+type vpcRouteLookupMatch struct {
+	Destination      string
+	MatchedCidrBlock string
+	Target           string
+	Origin           types.RouteOrigin
+	State            types.RouteState
+}
+
+//// LIST FUNCTION
+
+func listVpcRouteLookup(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	destination := d.EqualsQuals["destination_ip"].GetInetValue().GetAddr()
+	ip, err := netip.ParseAddr(destination)
+	if err != nil {
+		prefix, prefixErr := netip.ParsePrefix(destination)
+		if prefixErr != nil {
+			return nil, fmt.Errorf("%q is not an IP address", destination)
+		}
+		ip = prefix.Addr()
+	}
+
+	// Create session
+	svc, err := EC2Client(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_vpc_route_lookup.listVpcRouteLookup", "connection_error", err)
+		return nil, err
+	}
+
+	network := newVpcNetwork(ctx, d, svc)
+
+	var routeTable *types.RouteTable
+	if routeTableId := d.EqualsQualString("route_table_id"); routeTableId != "" {
+		routeTable, err = network.routeTable(routeTableId)
+	} else {
+		var subnet *types.Subnet
+		subnet, err = network.subnet(d.EqualsQualString("subnet_id"))
+		if err == nil && subnet != nil {
+			routeTable, err = network.subnetRouteTable(*subnet)
+		}
+	}
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_vpc_route_lookup.listVpcRouteLookup", "api_error", err)
+		return nil, err
+	}
+
+	// The subnet or route table is in another region
+	if routeTable == nil {
+		return nil, nil
+	}
+
+	matches, err := matchVpcRoutes(routeTable.Routes, ip, network.prefixListCidrs)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_vpc_route_lookup.listVpcRouteLookup", "api_error", err)
+		return nil, err
+	}
+
+	result := &vpcRouteLookup{
+		RouteTableId:   routeTable.RouteTableId,
+		VpcId:          routeTable.VpcId,
+		MatchingRoutes: []vpcRouteLookupMatch{},
+	}
+	for _, match := range matches {
+		result.MatchingRoutes = append(result.MatchingRoutes, vpcRouteLookupMatch{
+			Destination:      vpcRouteDestination(match.Route),
+			MatchedCidrBlock: match.Prefix.String(),
+			Target:           vpcRouteTarget(match.Route),
+			Origin:           match.Route.Origin,
+			State:            match.Route.State,
+		})
+	}
+
+	if len(matches) > 0 {
+		route := matches[0].Route
+		result.RouteFound = true
+		result.Route = &route
+		result.MatchedCidrBlock = matches[0].Prefix.String()
+		result.TargetId = vpcRouteTarget(route)
+		result.TargetType = vpcRouteTargetComponent(result.TargetId)
+
+		// Follow the route one hop further for targets that lead to other VPCs
+		switch {
+		case strings.HasPrefix(result.TargetId, "pcx-"):
+			connection, err := network.vpcPeeringConnection(result.TargetId)
+			if err != nil {
+				plugin.Logger(ctx).Error("aws_vpc_route_lookup.listVpcRouteLookup", "api_error", err)
+				return nil, err
+			}
+			if connection != nil {
+				result.PeerVpcId = aws.ToString(connection.AccepterVpcInfo.VpcId)
+				if result.PeerVpcId == aws.ToString(routeTable.VpcId) {
+					result.PeerVpcId = aws.ToString(connection.RequesterVpcInfo.VpcId)
+				}
+			}
+
+		case strings.HasPrefix(result.TargetId, "tgw-"):
+			result.TransitGatewayRoute, result.TransitGatewayRouteTableId, err = network.transitGatewayRoute(result.TargetId, aws.ToString(routeTable.VpcId), ip)
+			if err != nil {
+				plugin.Logger(ctx).Error("aws_vpc_route_lookup.listVpcRouteLookup", "api_error", err)
+				return nil, err
+			}
+		}
+	}
+
+	d.StreamListItem(ctx, result)
+
+	return nil, nil
+}
@@ -0,0 +1,559 @@
+package aws
+
+import (
+	"context"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+
+	ec2v1 "github.com/aws/aws-sdk-go/service/ec2"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsVpcSecurityGroupEffectiveIngress(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "aws_vpc_security_group_effective_ingress",
+		Description: "AWS VPC Security Group Effective Ingress",
+		List: &plugin.ListConfig{
+			Hydrate: listVpcSecurityGroupEffectiveIngress,
+			Tags:    map[string]string{"service": "ec2", "action": "DescribeSecurityGroupRules"},
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: shouldIgnoreErrors([]string{"InvalidGroup.NotFound", "InvalidGroupId.Malformed"}),
+			},
+			KeyColumns: []*plugin.KeyColumn{
+				{Name: "group_id", Require: plugin.Optional},
+			},
+		},
+		GetMatrixItemFunc: SupportedRegionMatrix(ec2v1.EndpointsID),
+		Columns: awsRegionalColumns([]*plugin.Column{
+			{
+				Name:        "security_group_rule_id",
+				Description: "The ID of the security group rule.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Rule.SecurityGroupRuleId"),
+			},
+			{
+				Name:        "group_id",
+				Description: "The ID of the security group.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Rule.GroupId"),
+			},
+			{
+				Name:        "ip_protocol",
+				Description: "The IP protocol name (tcp, udp, icmp, icmpv6) or number. A value of -1 indicates all protocols.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Rule.IpProtocol"),
+			},
+			{
+				Name:        "from_port",
+				Description: "The start of the port range for the TCP and UDP protocols, or an ICMP/ICMPv6 type. A value of -1 indicates all ICMP/ICMPv6 types.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("Rule.FromPort"),
+			},
+			{
+				Name:        "to_port",
+				Description: "The end of the port range for the TCP and UDP protocols, or an ICMP/ICMPv6 code. A value of -1 indicates all ICMP/ICMPv6 codes.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("Rule.ToPort"),
+			},
+			{
+				Name:        "description",
+				Description: "The security group rule description.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Rule.Description"),
+			},
+			{
+				Name:        "source_type",
+				Description: "The kind of source of the rule: cidr, ipv6_cidr, prefix_list or security_group.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "source_id",
+				Description: "The ID of the prefix list or security group that is the source of the rule.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "source_owner_id",
+				Description: "The ID of the AWS account that owns the referenced security group.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Rule.ReferencedGroupInfo.UserId"),
+			},
+			{
+				Name:        "source_cidr",
+				Description: "The CIDR block that the rule allows traffic from. For a referenced security group, the private IP address of a network interface in the group, or null if the group has no network interfaces in the region.",
+				Type:        proto.ColumnType_CIDR,
+			},
+			{
+				Name:        "source_network_interface_id",
+				Description: "The ID of the network interface in the referenced security group that the private IP address belongs to.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "open_to_internet",
+				Description: "True if the rule allows traffic from any IPv4 or IPv6 address.",
+				Type:        proto.ColumnType_BOOL,
+			},
+			{
+				Name:        "sensitive_ports",
+				Description: "The well known administration and database ports that the rule allows traffic to.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "exposes_sensitive_port",
+				Description: "True if the rule allows traffic from any address to a sensitive port.",
+				Type:        proto.ColumnType_BOOL,
+			},
+			{
+				Name:        "network_interface_ids",
+				Description: "The IDs of the network interfaces in the security group.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "instance_ids",
+				Description: "The IDs of the instances attached to the network interfaces in the security group.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "load_balancer_arns",
+				Description: "The ARNs of the load balancers that own network interfaces in the security group.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "db_instance_identifiers",
+				Description: "The identifiers of the RDS DB instances that own network interfaces in the security group.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "attached_resources",
+				Description: "The network interfaces in the security group, with the type and ID of the resource that uses each.",
+				Type:        proto.ColumnType_JSON,
+			},
+		}),
+	}
+}
+
+// securityGroupEffectiveIngress is an ingress rule expanded to one of the
+// addresses it allows traffic from
+type securityGroupEffectiveIngress struct {
+	Rule                     types.SecurityGroupRule
+	SourceType               string
+	SourceId                 *string
+	SourceCidr               *string
+	SourceNetworkInterfaceId *string
+	OpenToInternet           bool
+	SensitivePorts           []securityGroupSensitivePort
+	ExposesSensitivePort     bool
+	securityGroupAttachments
+}
+
+// securityGroupAttachments are the resources that use a security group
+type securityGroupAttachments struct {
+	NetworkInterfaceIds   []string
+	InstanceIds           []string
+	LoadBalancerArns      []string
+	DbInstanceIdentifiers []string
+	AttachedResources     []securityGroupAttachedResource
+}
+
+type securityGroupAttachedResource struct {
+	NetworkInterfaceId string
+	ResourceType       string
+	ResourceId         string `json:",omitempty"`
+}
+
+type securityGroupSensitivePort struct {
+	Port    int32
+	Service string
+}
+
+// securityGroupSensitivePorts are the well known ports of remote
+// administration, database and cache services, which shouldn't be open to
+// the internet
+var securityGroupSensitivePorts = []securityGroupSensitivePort{
+	{20, "ftp-data"},
+	{21, "ftp"},
+	{22, "ssh"},
+	{23, "telnet"},
+	{25, "smtp"},
+	{135, "msrpc"},
+	{139, "netbios"},
+	{445, "smb"},
+	{1433, "mssql"},
+	{1521, "oracle"},
+	{2375, "docker"},
+	{2376, "docker-tls"},
+	{3306, "mysql"},
+	{3389, "rdp"},
+	{5432, "postgresql"},
+	{5601, "kibana"},
+	{5900, "vnc"},
+	{5984, "couchdb"},
+	{6379, "redis"},
+	{9042, "cassandra"},
+	{9092, "kafka"},
+	{9200, "elasticsearch"},
+	{9300, "elasticsearch"},
+	{11211, "memcached"},
+	{27017, "mongodb"},
+}
+
+//// LIST FUNCTION
+
+func listVpcSecurityGroupEffectiveIngress(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	region := d.EqualsQualString(matrixKeyRegion)
+
+	// Create session
+	svc, err := EC2Client(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_vpc_security_group_effective_ingress.listVpcSecurityGroupEffectiveIngress", "connection_error", err)
+		return nil, err
+	}
+
+	commonData, err := getCommonColumns(ctx, d, h)
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_vpc_security_group_effective_ingress.listVpcSecurityGroupEffectiveIngress", "common_data_error", err)
+		return nil, err
+	}
+	commonColumnData := commonData.(*awsCommonColumnData)
+
+	network := newVpcNetwork(ctx, d, svc)
+
+	// Members of every group are needed to expand the groups rules reference
+	interfaces, err := network.networkInterfaces()
+	if err != nil {
+		plugin.Logger(ctx).Error("aws_vpc_security_group_effective_ingress.listVpcSecurityGroupEffectiveIngress", "api_error", err)
+		return nil, err
+	}
+	members := map[string][]types.NetworkInterface{}
+	hasRdsInterfaces := false
+	for _, networkInterface := range interfaces {
+		for _, group := range networkInterface.Groups {
+			members[aws.ToString(group.GroupId)] = append(members[aws.ToString(group.GroupId)], networkInterface)
+		}
+		if aws.ToString(networkInterface.RequesterId) == "amazon-rds" {
+			hasRdsInterfaces = true
+		}
+	}
+	attachments := map[string]securityGroupAttachments{}
+
+	// RDS network interfaces don't name their database, so the DB instances
+	// are listed to find them, if there are any in the region
+	var dbInstances []rdsTypes.DBInstance
+	if hasRdsInterfaces {
+		dbInstances = listSecurityGroupDbInstances(ctx, d)
+	}
+
+	input := &ec2.DescribeSecurityGroupRulesInput{}
+	if groupId := d.EqualsQualString("group_id"); groupId != "" {
+		input.Filters = []types.Filter{{Name: aws.String("group-id"), Values: []string{groupId}}}
+	}
+
+	paginator := ec2.NewDescribeSecurityGroupRulesPaginator(svc, input, func(o *ec2.DescribeSecurityGroupRulesPaginatorOptions) {
+		o.Limit = 1000
+		o.StopOnDuplicateToken = true
+	})
+
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Error("aws_vpc_security_group_effective_ingress.listVpcSecurityGroupEffectiveIngress", "api_error", err)
+			return nil, err
+		}
+
+		for _, rule := range output.SecurityGroupRules {
+			if aws.ToBool(rule.IsEgress) {
+				continue
+			}
+
+			groupId := aws.ToString(rule.GroupId)
+			if _, ok := attachments[groupId]; !ok {
+				attachments[groupId] = securityGroupAttachmentsOf(groupId, members[groupId], dbInstances, commonColumnData.Partition, region)
+			}
+
+			rows, err := expandSecurityGroupIngressRule(network, rule, members)
+			if err != nil {
+				plugin.Logger(ctx).Error("aws_vpc_security_group_effective_ingress.listVpcSecurityGroupEffectiveIngress", "api_error", err)
+				return nil, err
+			}
+
+			sensitivePorts := securityGroupRuleSensitivePorts(rule)
+			for _, row := range rows {
+				row.SensitivePorts = sensitivePorts
+				row.ExposesSensitivePort = row.OpenToInternet && len(sensitivePorts) > 0
+				row.securityGroupAttachments = attachments[groupId]
+				d.StreamListItem(ctx, row)
+
+				// Context can be cancelled due to manual cancellation or the limit has been hit
+				if d.RowsRemaining(ctx) == 0 {
+					return nil, nil
+				}
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// listSecurityGroupDbInstances returns the DB instances of the region. They
+// only name the resources that use security groups, so errors, e.g. without
+// the rds:DescribeDBInstances permission, are logged rather than returned.
+func listSecurityGroupDbInstances(ctx context.Context, d *plugin.QueryData) []rdsTypes.DBInstance {
+	svc, err := RDSClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Warn("aws_vpc_security_group_effective_ingress.listSecurityGroupDbInstances", "connection_error", err)
+		return nil
+	}
+
+	var instances []rdsTypes.DBInstance
+	paginator := rds.NewDescribeDBInstancesPaginator(svc, &rds.DescribeDBInstancesInput{}, func(o *rds.DescribeDBInstancesPaginatorOptions) {
+		o.Limit = 100
+		o.StopOnDuplicateToken = true
+	})
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		d.WaitForListRateLimit(ctx)
+
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			plugin.Logger(ctx).Warn("aws_vpc_security_group_effective_ingress.listSecurityGroupDbInstances", "api_error", err)
+			return nil
+		}
+		instances = append(instances, output.DBInstances...)
+	}
+	return instances
+}
+
+// expandSecurityGroupIngressRule returns a row for each address block that an
+// ingress rule allows traffic from
+func expandSecurityGroupIngressRule(network *vpcNetwork, rule types.SecurityGroupRule, members map[string][]types.NetworkInterface) ([]*securityGroupEffectiveIngress, error) {
+	newRow := func(sourceType string, sourceId *string, cidr string) *securityGroupEffectiveIngress {
+		row := &securityGroupEffectiveIngress{
+			Rule:       rule,
+			SourceType: sourceType,
+			SourceId:   sourceId,
+		}
+		if cidr != "" {
+			row.SourceCidr = aws.String(cidr)
+			if prefix, err := netip.ParsePrefix(cidr); err == nil && prefix.Bits() == 0 {
+				row.OpenToInternet = true
+			}
+		}
+		return row
+	}
+
+	switch {
+	case rule.CidrIpv4 != nil:
+		return []*securityGroupEffectiveIngress{newRow("cidr", nil, *rule.CidrIpv4)}, nil
+
+	case rule.CidrIpv6 != nil:
+		return []*securityGroupEffectiveIngress{newRow("ipv6_cidr", nil, *rule.CidrIpv6)}, nil
+
+	case rule.PrefixListId != nil:
+		cidrs, err := network.prefixListCidrs(*rule.PrefixListId)
+		if err != nil {
+			return nil, err
+		}
+		if len(cidrs) == 0 {
+			return []*securityGroupEffectiveIngress{newRow("prefix_list", rule.PrefixListId, "")}, nil
+		}
+		var rows []*securityGroupEffectiveIngress
+		for _, cidr := range cidrs {
+			rows = append(rows, newRow("prefix_list", rule.PrefixListId, cidr.String()))
+		}
+		return rows, nil
+
+	case rule.ReferencedGroupInfo != nil:
+		groupId := rule.ReferencedGroupInfo.GroupId
+		var rows []*securityGroupEffectiveIngress
+		for _, networkInterface := range members[aws.ToString(groupId)] {
+			for _, address := range networkInterface.PrivateIpAddresses {
+				ip, err := netip.ParseAddr(aws.ToString(address.PrivateIpAddress))
+				if err != nil {
+					continue
+				}
+				row := newRow("security_group", groupId, netip.PrefixFrom(ip, ip.BitLen()).String())
+				row.SourceNetworkInterfaceId = networkInterface.NetworkInterfaceId
+				rows = append(rows, row)
+			}
+			for _, address := range networkInterface.Ipv6Addresses {
+				ip, err := netip.ParseAddr(aws.ToString(address.Ipv6Address))
+				if err != nil {
+					continue
+				}
+				row := newRow("security_group", groupId, netip.PrefixFrom(ip, ip.BitLen()).String())
+				row.SourceNetworkInterfaceId = networkInterface.NetworkInterfaceId
+				rows = append(rows, row)
+			}
+		}
+		// Groups in other accounts or regions, or without members, are still
+		// listed so that the rule isn't hidden
+		if len(rows) == 0 {
+			rows = append(rows, newRow("security_group", groupId, ""))
+		}
+		return rows, nil
+	}
+
+	return []*securityGroupEffectiveIngress{newRow("", nil, "")}, nil
+}
+
+// securityGroupRuleSensitivePorts returns the sensitive ports in the port
+// range of a rule
+func securityGroupRuleSensitivePorts(rule types.SecurityGroupRule) []securityGroupSensitivePort {
+	protocol := ipProtocolNumber(aws.ToString(rule.IpProtocol))
+	if protocol != "-1" && !ipProtocolHasPorts(protocol) {
+		return nil
+	}
+
+	fromPort, toPort := aws.ToInt32(rule.FromPort), aws.ToInt32(rule.ToPort)
+	if protocol == "-1" || fromPort == -1 {
+		fromPort, toPort = 0, 65535
+	}
+
+	var ports []securityGroupSensitivePort
+	for _, port := range securityGroupSensitivePorts {
+		if port.Port >= fromPort && port.Port <= toPort {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// securityGroupAttachmentsOf returns the resources that use the network
+// interfaces of a security group
+func securityGroupAttachmentsOf(groupId string, interfaces []types.NetworkInterface, dbInstances []rdsTypes.DBInstance, partition string, region string) securityGroupAttachments {
+	attachments := securityGroupAttachments{
+		NetworkInterfaceIds:   []string{},
+		InstanceIds:           []string{},
+		LoadBalancerArns:      []string{},
+		DbInstanceIdentifiers: []string{},
+		AttachedResources:     []securityGroupAttachedResource{},
+	}
+	instances := map[string]bool{}
+	loadBalancers := map[string]bool{}
+
+	// The DB instances in the group
+	var groupDbInstances []rdsTypes.DBInstance
+	for _, dbInstance := range dbInstances {
+		if dbInstanceHasSecurityGroups(dbInstance, []string{groupId}) {
+			groupDbInstances = append(groupDbInstances, dbInstance)
+		}
+	}
+	hasRdsInterfaces := false
+
+	for _, networkInterface := range interfaces {
+		resource := securityGroupAttachedResource{
+			NetworkInterfaceId: aws.ToString(networkInterface.NetworkInterfaceId),
+			ResourceType:       string(networkInterface.InterfaceType),
+		}
+		description := aws.ToString(networkInterface.Description)
+
+		switch {
+		case networkInterface.Attachment != nil && networkInterface.Attachment.InstanceId != nil:
+			resource.ResourceType = "instance"
+			resource.ResourceId = *networkInterface.Attachment.InstanceId
+			instances[resource.ResourceId] = true
+
+		case strings.HasPrefix(description, "ELB "):
+			// Load balancers describe their interfaces as "ELB <name>" for classic
+			// load balancers, or "ELB <type>/<name>/<id>"
+			resource.ResourceType = "load_balancer"
+			resource.ResourceId = "arn:" + partition + ":elasticloadbalancing:" + region + ":" + aws.ToString(networkInterface.OwnerId) + ":loadbalancer/" + strings.TrimPrefix(description, "ELB ")
+			loadBalancers[resource.ResourceId] = true
+
+		case aws.ToString(networkInterface.RequesterId) == "amazon-rds":
+			resource.ResourceType = "rds"
+			resource.ResourceId = rdsNetworkInterfaceDbInstance(networkInterface, groupDbInstances)
+			hasRdsInterfaces = true
+
+		case resource.ResourceType == "interface" && networkInterface.RequesterId != nil:
+			resource.ResourceType = aws.ToString(networkInterface.RequesterId)
+		}
+
+		attachments.NetworkInterfaceIds = append(attachments.NetworkInterfaceIds, resource.NetworkInterfaceId)
+		attachments.AttachedResources = append(attachments.AttachedResources, resource)
+	}
+
+	for id := range instances {
+		attachments.InstanceIds = append(attachments.InstanceIds, id)
+	}
+	if hasRdsInterfaces {
+		for _, dbInstance := range groupDbInstances {
+			attachments.DbInstanceIdentifiers = append(attachments.DbInstanceIdentifiers, aws.ToString(dbInstance.DBInstanceIdentifier))
+		}
+	}
+	for arn := range loadBalancers {
+		attachments.LoadBalancerArns = append(attachments.LoadBalancerArns, arn)
+	}
+	sort.Strings(attachments.InstanceIds)
+	sort.Strings(attachments.LoadBalancerArns)
+	sort.Strings(attachments.DbInstanceIdentifiers)
+
+	return attachments
+}
+
+// rdsNetworkInterfaceDbInstance returns the identifier of the DB instance that
+// an RDS network interface belongs to: the only one of the DB instances with
+// all of the interface's security groups and a subnet group that includes its
+// subnet. It returns "" if none or more than one match.
+func rdsNetworkInterfaceDbInstance(networkInterface types.NetworkInterface, dbInstances []rdsTypes.DBInstance) string {
+	var groupIds []string
+	for _, group := range networkInterface.Groups {
+		groupIds = append(groupIds, aws.ToString(group.GroupId))
+	}
+
+	var match string
+	for _, dbInstance := range dbInstances {
+		if !dbInstanceHasSecurityGroups(dbInstance, groupIds) || !dbInstanceHasSubnet(dbInstance, aws.ToString(networkInterface.SubnetId)) {
+			continue
+		}
+		if match != "" {
+			return ""
+		}
+		match = aws.ToString(dbInstance.DBInstanceIdentifier)
+	}
+	return match
+}
+
+// dbInstanceHasSecurityGroups returns true if a DB instance is in all of the
+// security groups
+func dbInstanceHasSecurityGroups(dbInstance rdsTypes.DBInstance, groupIds []string) bool {
+	for _, groupId := range groupIds {
+		found := false
+		for _, group := range dbInstance.VpcSecurityGroups {
+			if aws.ToString(group.VpcSecurityGroupId) == groupId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func dbInstanceHasSubnet(dbInstance rdsTypes.DBInstance, subnetId string) bool {
+	if dbInstance.DBSubnetGroup == nil {
+		return false
+	}
+	for _, subnet := range dbInstance.DBSubnetGroup.Subnets {
+		if aws.ToString(subnet.SubnetIdentifier) == subnetId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableAwsWafv2WebAclLog(_ context.Context) *plugin.Table {
+	return accessLogTable(accessLogDefinition{
+		Name:        "aws_wafv2_web_acl_log",
+		Description: "AWS WAFv2 Web ACL Log",
+		Parse:       parseWafv2WebAclLog,
+		FilterFields: map[string]string{
+			"action":              "$.action",
+			"web_acl_id":          "$.webaclId",
+			"terminating_rule_id": "$.terminatingRuleId",
+			"client_ip":           "$.httpRequest.clientIp",
+			"country":             "$.httpRequest.country",
+			"uri":                 "$.httpRequest.uri",
+			"http_method":         "$.httpRequest.httpMethod",
+		},
+		Columns: []*plugin.Column{
+			{Name: "web_acl_id", Type: proto.ColumnType_STRING, Description: "The ARN of the web ACL that evaluated the request."},
+			{Name: "action", Type: proto.ColumnType_STRING, Description: "The terminating action that AWS WAF applied to the request. Possible values are: ALLOW|BLOCK|CAPTCHA|CHALLENGE."},
+			{Name: "terminating_rule_id", Type: proto.ColumnType_STRING, Description: "The ID of the rule that terminated the request, or Default_Action if no rule did."},
+			{Name: "terminating_rule_type", Type: proto.ColumnType_STRING, Description: "The type of the rule that terminated the request. Possible values are: RATE_BASED|REGULAR|GROUP|MANAGED_RULE_GROUP."},
+			{Name: "terminating_rule_match_details", Type: proto.ColumnType_JSON, Description: "Detailed information about the terminating rule that matched the request, for SQL injection and cross-site scripting match rules."},
+			{Name: "http_source_name", Type: proto.ColumnType_STRING, Description: "The source of the request, e.g. ALB, APIGW or CF."},
+			{Name: "http_source_id", Type: proto.ColumnType_STRING, Description: "The ID of the associated resource, e.g. the ID of a CloudFront distribution or the ARN of a load balancer."},
+			{Name: "client_ip", Type: proto.ColumnType_IPADDR, Description: "The IP address of the client sending the request."},
+			{Name: "country", Type: proto.ColumnType_STRING, Description: "The source country of the request, as an ISO 3166 alpha-2 code."},
+			{Name: "uri", Type: proto.ColumnType_STRING, Description: "The URI of the request."},
+			{Name: "args", Type: proto.ColumnType_STRING, Description: "The query string of the request."},
+			{Name: "http_method", Type: proto.ColumnType_STRING, Description: "The HTTP method of the request."},
+			{Name: "http_version", Type: proto.ColumnType_STRING, Description: "The HTTP version of the request."},
+			{Name: "request_id", Type: proto.ColumnType_STRING, Description: "The ID of the request, generated by the underlying host service."},
+			{Name: "headers", Type: proto.ColumnType_JSON, Description: "The headers of the request, as a list of name and value pairs."},
+			{Name: "labels", Type: proto.ColumnType_JSON, Description: "The labels on the request, added by the rules that evaluated it."},
+			{Name: "rule_group_list", Type: proto.ColumnType_JSON, Description: "The rule groups that acted on the request, with their matching rules."},
+			{Name: "rate_based_rule_list", Type: proto.ColumnType_JSON, Description: "The rate-based rules that acted on the request."},
+			{Name: "non_terminating_matching_rules", Type: proto.ColumnType_JSON, Description: "The non-terminating rules, e.g. rules with a COUNT action, that matched the request."},
+			{Name: "request_headers_inserted", Type: proto.ColumnType_JSON, Description: "The headers inserted for custom request handling."},
+			{Name: "response_code_sent", Type: proto.ColumnType_INT, Description: "The response code sent with a custom response."},
+			{Name: "captcha_response", Type: proto.ColumnType_JSON, Description: "The CAPTCHA action status for the request."},
+			{Name: "challenge_response", Type: proto.ColumnType_JSON, Description: "The challenge action status for the request."},
+			{Name: "ja3_fingerprint", Type: proto.ColumnType_STRING, Description: "The JA3 fingerprint of the TLS client hello of the request."},
+			{Name: "format_version", Type: proto.ColumnType_INT, Description: "The format version of the log."},
+		},
+	})
+}
+
+//// PARSE FUNCTIONS
+
+type wafv2WebAclLogRecord struct {
+	Timestamp                   int64           `json:"timestamp"`
+	FormatVersion               *int64          `json:"formatVersion"`
+	WebaclId                    *string         `json:"webaclId"`
+	TerminatingRuleId           *string         `json:"terminatingRuleId"`
+	TerminatingRuleType         *string         `json:"terminatingRuleType"`
+	TerminatingRuleMatchDetails json.RawMessage `json:"terminatingRuleMatchDetails"`
+	Action                      *string         `json:"action"`
+	HttpSourceName              *string         `json:"httpSourceName"`
+	HttpSourceId                *string         `json:"httpSourceId"`
+	RuleGroupList               json.RawMessage `json:"ruleGroupList"`
+	RateBasedRuleList           json.RawMessage `json:"rateBasedRuleList"`
+	NonTerminatingMatchingRules json.RawMessage `json:"nonTerminatingMatchingRules"`
+	RequestHeadersInserted      json.RawMessage `json:"requestHeadersInserted"`
+	ResponseCodeSent            *int64          `json:"responseCodeSent"`
+	Labels                      json.RawMessage `json:"labels"`
+	CaptchaResponse             json.RawMessage `json:"captchaResponse"`
+	ChallengeResponse           json.RawMessage `json:"challengeResponse"`
+	Ja3Fingerprint              *string         `json:"ja3Fingerprint"`
+	HttpRequest                 struct {
+		ClientIp    *string         `json:"clientIp"`
+		Country     *string         `json:"country"`
+		Headers     json.RawMessage `json:"headers"`
+		Uri         *string         `json:"uri"`
+		Args        *string         `json:"args"`
+		HttpVersion *string         `json:"httpVersion"`
+		HttpMethod  *string         `json:"httpMethod"`
+		RequestId   *string         `json:"requestId"`
+	} `json:"httpRequest"`
+}
+
+func parseWafv2WebAclLog(line string) (map[string]interface{}, time.Time, bool) {
+	var record wafv2WebAclLogRecord
+	if err := json.Unmarshal([]byte(line), &record); err != nil || record.Timestamp == 0 {
+		return nil, time.Time{}, false
+	}
+
+	fields := map[string]interface{}{}
+	for name, value := range map[string]*string{
+		"web_acl_id":            record.WebaclId,
+		"action":                record.Action,
+		"terminating_rule_id":   record.TerminatingRuleId,
+		"terminating_rule_type": record.TerminatingRuleType,
+		"http_source_name":      record.HttpSourceName,
+		"http_source_id":        record.HttpSourceId,
+		"client_ip":             record.HttpRequest.ClientIp,
+		"country":               record.HttpRequest.Country,
+		"uri":                   record.HttpRequest.Uri,
+		"args":                  record.HttpRequest.Args,
+		"http_method":           record.HttpRequest.HttpMethod,
+		"http_version":          record.HttpRequest.HttpVersion,
+		"request_id":            record.HttpRequest.RequestId,
+		"ja3_fingerprint":       record.Ja3Fingerprint,
+	} {
+		if value != nil {
+			fields[name] = *value
+		}
+	}
+	for name, value := range map[string]json.RawMessage{
+		"terminating_rule_match_details": record.TerminatingRuleMatchDetails,
+		"headers":                        record.HttpRequest.Headers,
+		"labels":                         record.Labels,
+		"rule_group_list":                record.RuleGroupList,
+		"rate_based_rule_list":           record.RateBasedRuleList,
+		"non_terminating_matching_rules": record.NonTerminatingMatchingRules,
+		"request_headers_inserted":       record.RequestHeadersInserted,
+		"captcha_response":               record.CaptchaResponse,
+		"challenge_response":             record.ChallengeResponse,
+	} {
+		var parsed interface{}
+		if len(value) > 0 && json.Unmarshal(value, &parsed) == nil && parsed != nil {
+			fields[name] = parsed
+		}
+	}
+	if record.ResponseCodeSent != nil {
+		fields["response_code_sent"] = *record.ResponseCodeSent
+	}
+	if record.FormatVersion != nil {
+		fields["format_version"] = *record.FormatVersion
+	}
+
+	return fields, time.UnixMilli(record.Timestamp), true
+}
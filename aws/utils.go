@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 	"unicode/utf8"
 
 	sagemakerTypes "github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
@@ -245,3 +246,29 @@ func readEnvVarToInt(name string, defaultVal int) int {
 	}
 	return val
 }
+
+// snakeCase converts a camel case name to snake case, with path separators
+// replaced by underscores, e.g. lineItem/UsageAccountId to
+// line_item_usage_account_id. Names already in that form are returned
+// unchanged.
+func snakeCase(header string) string {
+	var parts []string
+	for _, part := range strings.Split(header, "/") {
+		runes := []rune(part)
+		var b strings.Builder
+		for i, r := range runes {
+			if unicode.IsUpper(r) {
+				prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+				nextLower := i > 0 && i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1])
+				if prevLower || nextLower {
+					b.WriteRune('_')
+				}
+				b.WriteRune(unicode.ToLower(r))
+				continue
+			}
+			b.WriteRune(r)
+		}
+		parts = append(parts, b.String())
+	}
+	return strings.Join(parts, "_")
+}
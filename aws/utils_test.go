@@ -0,0 +1,25 @@
+package aws
+
+import "testing"
+
+func TestSnakeCase(t *testing.T) {
+	cases := map[string]string{
+		"lineItem/UsageAccountId": "line_item_usage_account_id",
+		"Size":                    "size",
+		"ETag":                    "e_tag",
+		"IsLatest":                "is_latest",
+		"IPAddress":               "ip_address",
+		"product/region":          "product_region",
+		"line_item_usage_type":    "line_item_usage_type",
+		"resourceTags/user:Name":  "resource_tags_user:name",
+		"":                        "",
+	}
+
+	for input, want := range cases {
+		t.Run(input, func(t *testing.T) {
+			if got := snakeCase(input); got != want {
+				t.Errorf("snakeCase(%q) = %q, want %q", input, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,749 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// vpcNetwork looks up the network configuration of a region's VPCs for
+// offline analysis, caching each describe call for the duration of a query.
+type vpcNetwork struct {
+	ctx context.Context
+	d   *plugin.QueryData
+	svc *ec2.Client
+
+	subnets     []types.Subnet
+	prefixLists map[string][]netip.Prefix
+}
+
+func newVpcNetwork(ctx context.Context, d *plugin.QueryData, svc *ec2.Client) *vpcNetwork {
+	return &vpcNetwork{
+		ctx:         ctx,
+		d:           d,
+		svc:         svc,
+		prefixLists: map[string][]netip.Prefix{},
+	}
+}
+
+// isEc2NotFoundError returns true for the errors describe calls return for IDs
+// that don't exist in the region
+func isEc2NotFoundError(err error) bool {
+	var ae smithy.APIError
+	if errors.As(err, &ae) {
+		return strings.HasSuffix(ae.ErrorCode(), ".NotFound") || strings.HasSuffix(ae.ErrorCode(), ".Malformed")
+	}
+	return false
+}
+
+// networkInterface returns a network interface by ID, or nil if it is not in
+// the region
+func (n *vpcNetwork) networkInterface(id string) (*types.NetworkInterface, error) {
+	n.d.WaitForListRateLimit(n.ctx)
+	output, err := n.svc.DescribeNetworkInterfaces(n.ctx, &ec2.DescribeNetworkInterfacesInput{
+		NetworkInterfaceIds: []string{id},
+	})
+	if err != nil {
+		if isEc2NotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(output.NetworkInterfaces) == 0 {
+		return nil, nil
+	}
+	return &output.NetworkInterfaces[0], nil
+}
+
+// networkInterfaceByIp returns the network interface with a private IP
+// address, or nil if there is none
+func (n *vpcNetwork) networkInterfaceByIp(ip netip.Addr) (*types.NetworkInterface, error) {
+	filter := "addresses.private-ip-address"
+	if ip.Is6() {
+		filter = "ipv6-addresses.ipv6-address"
+	}
+	interfaces, err := n.networkInterfaces(types.Filter{Name: aws.String(filter), Values: []string{ip.String()}})
+	if err != nil || len(interfaces) == 0 {
+		return nil, err
+	}
+	return &interfaces[0], nil
+}
+
+// networkInterfaces returns the network interfaces that match a filter
+func (n *vpcNetwork) networkInterfaces(filters ...types.Filter) ([]types.NetworkInterface, error) {
+	var interfaces []types.NetworkInterface
+	paginator := ec2.NewDescribeNetworkInterfacesPaginator(n.svc, &ec2.DescribeNetworkInterfacesInput{
+		Filters: filters,
+	})
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		n.d.WaitForListRateLimit(n.ctx)
+
+		output, err := paginator.NextPage(n.ctx)
+		if err != nil {
+			return nil, err
+		}
+		interfaces = append(interfaces, output.NetworkInterfaces...)
+	}
+	return interfaces, nil
+}
+
+// subnet returns a subnet by ID, or nil if it is not in the region
+func (n *vpcNetwork) subnet(id string) (*types.Subnet, error) {
+	subnets, err := n.allSubnets()
+	if err != nil {
+		return nil, err
+	}
+	for i := range subnets {
+		if aws.ToString(subnets[i].SubnetId) == id {
+			return &subnets[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// subnetContaining returns the subnet whose CIDR block contains an IP
+// address, or nil if the address is outside the region's VPCs
+func (n *vpcNetwork) subnetContaining(ip netip.Addr) (*types.Subnet, error) {
+	subnets, err := n.allSubnets()
+	if err != nil {
+		return nil, err
+	}
+	for i, subnet := range subnets {
+		for _, prefix := range subnetCidrBlocks(subnet) {
+			if prefix.Contains(ip) {
+				return &subnets[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// subnetCidrBlocks returns the IPv4 and IPv6 CIDR blocks of a subnet
+func subnetCidrBlocks(subnet types.Subnet) []netip.Prefix {
+	cidrs := []string{aws.ToString(subnet.CidrBlock)}
+	for _, association := range subnet.Ipv6CidrBlockAssociationSet {
+		cidrs = append(cidrs, aws.ToString(association.Ipv6CidrBlock))
+	}
+
+	var prefixes []netip.Prefix
+	for _, cidr := range cidrs {
+		if prefix, err := netip.ParsePrefix(cidr); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		}
+	}
+	return prefixes
+}
+
+func (n *vpcNetwork) allSubnets() ([]types.Subnet, error) {
+	if n.subnets != nil {
+		return n.subnets, nil
+	}
+
+	subnets := []types.Subnet{}
+	paginator := ec2.NewDescribeSubnetsPaginator(n.svc, &ec2.DescribeSubnetsInput{})
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		n.d.WaitForListRateLimit(n.ctx)
+
+		output, err := paginator.NextPage(n.ctx)
+		if err != nil {
+			return nil, err
+		}
+		subnets = append(subnets, output.Subnets...)
+	}
+	n.subnets = subnets
+	return subnets, nil
+}
+
+// subnetRouteTable returns the route table of a subnet, which is the main
+// route table of its VPC unless one is explicitly associated
+func (n *vpcNetwork) subnetRouteTable(subnet types.Subnet) (*types.RouteTable, error) {
+	routeTables, err := n.routeTables(types.Filter{Name: aws.String("association.subnet-id"), Values: []string{aws.ToString(subnet.SubnetId)}})
+	if err != nil {
+		return nil, err
+	}
+	if len(routeTables) == 0 {
+		routeTables, err = n.routeTables(
+			types.Filter{Name: aws.String("vpc-id"), Values: []string{aws.ToString(subnet.VpcId)}},
+			types.Filter{Name: aws.String("association.main"), Values: []string{"true"}},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(routeTables) == 0 {
+		return nil, nil
+	}
+	return &routeTables[0], nil
+}
+
+// routeTable returns a route table by ID, or nil if it is not in the region
+func (n *vpcNetwork) routeTable(id string) (*types.RouteTable, error) {
+	n.d.WaitForListRateLimit(n.ctx)
+	output, err := n.svc.DescribeRouteTables(n.ctx, &ec2.DescribeRouteTablesInput{
+		RouteTableIds: []string{id},
+	})
+	if err != nil {
+		if isEc2NotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(output.RouteTables) == 0 {
+		return nil, nil
+	}
+	return &output.RouteTables[0], nil
+}
+
+func (n *vpcNetwork) routeTables(filters ...types.Filter) ([]types.RouteTable, error) {
+	n.d.WaitForListRateLimit(n.ctx)
+	output, err := n.svc.DescribeRouteTables(n.ctx, &ec2.DescribeRouteTablesInput{
+		Filters: filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.RouteTables, nil
+}
+
+// subnetNetworkAcl returns the network ACL associated with a subnet
+func (n *vpcNetwork) subnetNetworkAcl(subnet types.Subnet) (*types.NetworkAcl, error) {
+	n.d.WaitForListRateLimit(n.ctx)
+	output, err := n.svc.DescribeNetworkAcls(n.ctx, &ec2.DescribeNetworkAclsInput{
+		Filters: []types.Filter{{Name: aws.String("association.subnet-id"), Values: []string{aws.ToString(subnet.SubnetId)}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.NetworkAcls) == 0 {
+		return nil, nil
+	}
+	return &output.NetworkAcls[0], nil
+}
+
+// securityGroups returns security groups by ID
+func (n *vpcNetwork) securityGroups(ids []string) ([]types.SecurityGroup, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	n.d.WaitForListRateLimit(n.ctx)
+	output, err := n.svc.DescribeSecurityGroups(n.ctx, &ec2.DescribeSecurityGroupsInput{
+		GroupIds: ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.SecurityGroups, nil
+}
+
+// vpcPeeringConnection returns a peering connection by ID, or nil if it is
+// not in the region
+func (n *vpcNetwork) vpcPeeringConnection(id string) (*types.VpcPeeringConnection, error) {
+	n.d.WaitForListRateLimit(n.ctx)
+	output, err := n.svc.DescribeVpcPeeringConnections(n.ctx, &ec2.DescribeVpcPeeringConnectionsInput{
+		VpcPeeringConnectionIds: []string{id},
+	})
+	if err != nil {
+		if isEc2NotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(output.VpcPeeringConnections) == 0 {
+		return nil, nil
+	}
+	return &output.VpcPeeringConnections[0], nil
+}
+
+// vpcEndpoint returns a VPC endpoint by ID, or nil if it is not in the region
+func (n *vpcNetwork) vpcEndpoint(id string) (*types.VpcEndpoint, error) {
+	n.d.WaitForListRateLimit(n.ctx)
+	output, err := n.svc.DescribeVpcEndpoints(n.ctx, &ec2.DescribeVpcEndpointsInput{
+		VpcEndpointIds: []string{id},
+	})
+	if err != nil {
+		if isEc2NotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(output.VpcEndpoints) == 0 {
+		return nil, nil
+	}
+	return &output.VpcEndpoints[0], nil
+}
+
+// vpcHasInternetGateway returns true if an internet gateway is attached to a VPC
+func (n *vpcNetwork) vpcHasInternetGateway(vpcId string) (bool, error) {
+	n.d.WaitForListRateLimit(n.ctx)
+	output, err := n.svc.DescribeInternetGateways(n.ctx, &ec2.DescribeInternetGatewaysInput{
+		Filters: []types.Filter{{Name: aws.String("attachment.vpc-id"), Values: []string{vpcId}}},
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(output.InternetGateways) > 0, nil
+}
+
+// transitGatewayRoute returns the transit gateway route that traffic from a
+// VPC to an IP address takes, and the route table it is in. The route is nil
+// if the VPC's attachment has no route for the address.
+func (n *vpcNetwork) transitGatewayRoute(transitGatewayId string, vpcId string, ip netip.Addr) (*types.TransitGatewayRoute, string, error) {
+	n.d.WaitForListRateLimit(n.ctx)
+	attachments, err := n.svc.DescribeTransitGatewayAttachments(n.ctx, &ec2.DescribeTransitGatewayAttachmentsInput{
+		Filters: []types.Filter{
+			{Name: aws.String("transit-gateway-id"), Values: []string{transitGatewayId}},
+			{Name: aws.String("resource-id"), Values: []string{vpcId}},
+		},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var routeTableId string
+	for _, attachment := range attachments.TransitGatewayAttachments {
+		if attachment.Association != nil && attachment.Association.TransitGatewayRouteTableId != nil {
+			routeTableId = *attachment.Association.TransitGatewayRouteTableId
+			break
+		}
+	}
+	if routeTableId == "" {
+		return nil, "", nil
+	}
+
+	n.d.WaitForListRateLimit(n.ctx)
+	routes, err := n.svc.SearchTransitGatewayRoutes(n.ctx, &ec2.SearchTransitGatewayRoutesInput{
+		TransitGatewayRouteTableId: aws.String(routeTableId),
+		Filters: []types.Filter{
+			{Name: aws.String("route-search.longest-prefix-match"), Values: []string{netip.PrefixFrom(ip, ip.BitLen()).String()}},
+		},
+	})
+	if err != nil {
+		return nil, routeTableId, err
+	}
+
+	// Routes are returned in no particular order, so the longest is chosen
+	var best *types.TransitGatewayRoute
+	bestBits := -1
+	for i, route := range routes.Routes {
+		prefix, err := netip.ParsePrefix(aws.ToString(route.DestinationCidrBlock))
+		if err != nil {
+			continue
+		}
+		if prefix.Bits() > bestBits {
+			best, bestBits = &routes.Routes[i], prefix.Bits()
+		}
+	}
+	return best, routeTableId, nil
+}
+
+// prefixListCidrs returns the CIDR blocks of a managed prefix list
+func (n *vpcNetwork) prefixListCidrs(id string) ([]netip.Prefix, error) {
+	if cidrs, ok := n.prefixLists[id]; ok {
+		return cidrs, nil
+	}
+
+	cidrs := []netip.Prefix{}
+	paginator := ec2.NewGetManagedPrefixListEntriesPaginator(n.svc, &ec2.GetManagedPrefixListEntriesInput{
+		PrefixListId: aws.String(id),
+	})
+	for paginator.HasMorePages() {
+		// apply rate limiting
+		n.d.WaitForListRateLimit(n.ctx)
+
+		output, err := paginator.NextPage(n.ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range output.Entries {
+			if prefix, err := netip.ParsePrefix(aws.ToString(entry.Cidr)); err == nil {
+				cidrs = append(cidrs, prefix)
+			}
+		}
+	}
+	n.prefixLists[id] = cidrs
+	return cidrs, nil
+}
+
+//// ADDRESS RANGES
+
+// splitVpcReachabilityRange splits a CIDR block into the parts of it that are
+// in each of the subnet CIDR blocks, and the first part of it that is in none
+// of them, in address order. The addresses of each part share a subnet, so
+// its network ACL and route table, or are all outside the subnets.
+func splitVpcReachabilityRange(block netip.Prefix, subnets []netip.Prefix) []netip.Prefix {
+	var ranges []netip.Prefix
+	seen := map[netip.Prefix]bool{}
+	for _, subnet := range subnets {
+		if !subnet.Overlaps(block) {
+			continue
+		}
+		// Overlapping CIDR blocks are nested, so the narrower is the overlap
+		overlap := subnet
+		if block.Bits() > subnet.Bits() {
+			overlap = block
+		}
+		if !seen[overlap] {
+			seen[overlap] = true
+			ranges = append(ranges, overlap)
+		}
+	}
+	if rest, ok := firstPrefixOutside(block, subnets); ok {
+		ranges = append(ranges, rest)
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Addr().Less(ranges[j].Addr())
+	})
+	return ranges
+}
+
+// firstPrefixOutside returns the lowest CIDR block within a block that
+// doesn't overlap any of the excluded ones, halving the block until a half
+// doesn't
+func firstPrefixOutside(block netip.Prefix, excluded []netip.Prefix) (netip.Prefix, bool) {
+	overlaps := false
+	for _, prefix := range excluded {
+		if !prefix.Overlaps(block) {
+			continue
+		}
+		if prefix.Bits() <= block.Bits() {
+			return netip.Prefix{}, false
+		}
+		overlaps = true
+	}
+	if !overlaps {
+		return block, true
+	}
+
+	lower := netip.PrefixFrom(block.Addr(), block.Bits()+1)
+	if rest, ok := firstPrefixOutside(lower, excluded); ok {
+		return rest, true
+	}
+	upper := block.Addr().AsSlice()
+	upper[block.Bits()/8] |= 0x80 >> (block.Bits() % 8)
+	upperAddr, _ := netip.AddrFromSlice(upper)
+	return firstPrefixOutside(netip.PrefixFrom(upperAddr, block.Bits()+1), excluded)
+}
+
+//// ROUTE SELECTION
+
+// vpcRouteMatch is a route whose destination contains an IP address, with
+// the CIDR block that contains it
+type vpcRouteMatch struct {
+	Route  types.Route
+	Prefix netip.Prefix
+}
+
+// selectVpcRoute returns the route of a route table that traffic to an IP
+// address takes, or nil if no route matches
+func selectVpcRoute(routes []types.Route, ip netip.Addr, prefixListCidrs func(id string) ([]netip.Prefix, error)) (*types.Route, error) {
+	matches, err := matchVpcRoutes(routes, ip, prefixListCidrs)
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+	return &matches[0].Route, nil
+}
+
+// matchVpcRoutes returns the routes of a route table that match an IP
+// address, in the order they are preferred. The most specific route wins.
+// Routes to the same destination are preferred in the order: local, static,
+// prefix list, then propagated routes.
+func matchVpcRoutes(routes []types.Route, ip netip.Addr, prefixListCidrs func(id string) ([]netip.Prefix, error)) ([]vpcRouteMatch, error) {
+	var matches []vpcRouteMatch
+	for _, route := range routes {
+		var prefixes []netip.Prefix
+		switch {
+		case route.DestinationCidrBlock != nil:
+			if prefix, err := netip.ParsePrefix(*route.DestinationCidrBlock); err == nil {
+				prefixes = append(prefixes, prefix)
+			}
+		case route.DestinationIpv6CidrBlock != nil:
+			if prefix, err := netip.ParsePrefix(*route.DestinationIpv6CidrBlock); err == nil {
+				prefixes = append(prefixes, prefix)
+			}
+		case route.DestinationPrefixListId != nil:
+			cidrs, err := prefixListCidrs(*route.DestinationPrefixListId)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = cidrs
+		}
+
+		// A prefix list matches with its most specific entry
+		var best *netip.Prefix
+		for i, prefix := range prefixes {
+			if prefix.Contains(ip) && (best == nil || prefix.Bits() > best.Bits()) {
+				best = &prefixes[i]
+			}
+		}
+		if best != nil {
+			matches = append(matches, vpcRouteMatch{Route: route, Prefix: *best})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Prefix.Bits() != matches[j].Prefix.Bits() {
+			return matches[i].Prefix.Bits() > matches[j].Prefix.Bits()
+		}
+		return vpcRouteRank(matches[i].Route) < vpcRouteRank(matches[j].Route)
+	})
+	return matches, nil
+}
+
+// vpcRouteRank orders routes to the same destination, lowest first
+func vpcRouteRank(route types.Route) int {
+	switch {
+	case aws.ToString(route.GatewayId) == "local":
+		return 0
+	case route.Origin == types.RouteOriginEnableVgwRoutePropagation:
+		return 3
+	case route.DestinationPrefixListId != nil:
+		return 2
+	}
+	return 1
+}
+
+// vpcRouteTarget returns the ID of the target of a route
+func vpcRouteTarget(route types.Route) string {
+	for _, target := range []*string{
+		route.GatewayId,
+		route.NatGatewayId,
+		route.TransitGatewayId,
+		route.VpcPeeringConnectionId,
+		route.NetworkInterfaceId,
+		route.InstanceId,
+		route.EgressOnlyInternetGatewayId,
+		route.CarrierGatewayId,
+		route.LocalGatewayId,
+		route.CoreNetworkArn,
+	} {
+		if target != nil {
+			return *target
+		}
+	}
+	return ""
+}
+
+// vpcRouteDestination returns the destination CIDR block or prefix list of a route
+func vpcRouteDestination(route types.Route) string {
+	for _, destination := range []*string{route.DestinationCidrBlock, route.DestinationIpv6CidrBlock, route.DestinationPrefixListId} {
+		if destination != nil {
+			return *destination
+		}
+	}
+	return ""
+}
+
+//// RULE EVALUATION
+
+// ipProtocolNumber returns the protocol number of a protocol name or number,
+// or "-1" for all protocols
+func ipProtocolNumber(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "", "-1", "all":
+		return "-1"
+	case "tcp":
+		return "6"
+	case "udp":
+		return "17"
+	case "icmp":
+		return "1"
+	case "icmpv6":
+		return "58"
+	}
+	return protocol
+}
+
+// ipProtocolHasPorts returns true for protocols that rules match by port
+func ipProtocolHasPorts(protocol string) bool {
+	return protocol == "6" || protocol == "17"
+}
+
+// evaluateNetworkAcl evaluates the entries of a network ACL in order of rule
+// number for traffic with a peer address, over a range of ports. It returns
+// whether all the ports are allowed, and the entry that denies any that are
+// not, which is nil for the default deny.
+func evaluateNetworkAcl(entries []types.NetworkAclEntry, egress bool, protocol string, fromPort int32, toPort int32, peer netip.Addr) (bool, *types.NetworkAclEntry) {
+	var ordered []types.NetworkAclEntry
+	for _, entry := range entries {
+		if aws.ToBool(entry.Egress) == egress {
+			ordered = append(ordered, entry)
+		}
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return aws.ToInt32(ordered[i].RuleNumber) < aws.ToInt32(ordered[j].RuleNumber)
+	})
+
+	// The first matching entry is the same for all ports between the
+	// boundaries of the entries' port ranges, so only those are evaluated
+	ports := []int32{fromPort}
+	if ipProtocolHasPorts(protocol) {
+		for _, entry := range ordered {
+			if entry.PortRange == nil {
+				continue
+			}
+			for _, port := range []int32{aws.ToInt32(entry.PortRange.From), aws.ToInt32(entry.PortRange.To) + 1} {
+				if port > fromPort && port <= toPort {
+					ports = append(ports, port)
+				}
+			}
+		}
+	}
+
+	for _, port := range ports {
+		var match *types.NetworkAclEntry
+		for i, entry := range ordered {
+			if networkAclEntryMatches(entry, protocol, port, peer) {
+				match = &ordered[i]
+				break
+			}
+		}
+		if match == nil || match.RuleAction != types.RuleActionAllow {
+			return false, match
+		}
+	}
+	return true, nil
+}
+
+func networkAclEntryMatches(entry types.NetworkAclEntry, protocol string, port int32, peer netip.Addr) bool {
+	cidr := aws.ToString(entry.CidrBlock)
+	if peer.Is6() {
+		cidr = aws.ToString(entry.Ipv6CidrBlock)
+	}
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil || !prefix.Contains(peer) {
+		return false
+	}
+
+	entryProtocol := aws.ToString(entry.Protocol)
+	if entryProtocol == "-1" {
+		return true
+	}
+	if entryProtocol != protocol {
+		return false
+	}
+	if ipProtocolHasPorts(protocol) && entry.PortRange != nil {
+		return port >= aws.ToInt32(entry.PortRange.From) && port <= aws.ToInt32(entry.PortRange.To)
+	}
+	return true
+}
+
+// securityGroupPeer is the other end of traffic that security group rules
+// are evaluated for
+type securityGroupPeer struct {
+	Ip       netip.Addr
+	GroupIds []string
+}
+
+// evaluateSecurityGroups returns the first rule of the security groups that
+// allows traffic with a peer on a port, with a description of why it
+// matches, or nil if none do
+func evaluateSecurityGroups(groups []types.SecurityGroup, egress bool, protocol string, port int32, peer securityGroupPeer, prefixListCidrs func(id string) ([]netip.Prefix, error)) (*types.IpPermission, string, error) {
+	for _, group := range groups {
+		permissions := group.IpPermissions
+		if egress {
+			permissions = group.IpPermissionsEgress
+		}
+		for i, permission := range permissions {
+			if !ipPermissionMatchesPort(permission, protocol, port) {
+				continue
+			}
+			reason, err := ipPermissionMatchesPeer(permission, peer, prefixListCidrs)
+			if err != nil {
+				return nil, "", err
+			}
+			if reason != "" {
+				return &permissions[i], fmt.Sprintf("%s allows %s", aws.ToString(group.GroupId), reason), nil
+			}
+		}
+	}
+	return nil, "", nil
+}
+
+func ipPermissionMatchesPort(permission types.IpPermission, protocol string, port int32) bool {
+	permissionProtocol := ipProtocolNumber(aws.ToString(permission.IpProtocol))
+	if permissionProtocol == "-1" {
+		return true
+	}
+	if permissionProtocol != protocol {
+		return false
+	}
+	if !ipProtocolHasPorts(protocol) || permission.FromPort == nil || permission.ToPort == nil {
+		return true
+	}
+	return port >= *permission.FromPort && port <= *permission.ToPort
+}
+
+// ipPermissionMatchesPeer returns a description of the source or
+// destination of a rule that matches the peer, or "" if none do
+func ipPermissionMatchesPeer(permission types.IpPermission, peer securityGroupPeer, prefixListCidrs func(id string) ([]netip.Prefix, error)) (string, error) {
+	if peer.Ip.IsValid() {
+		for _, ipRange := range permission.IpRanges {
+			if prefix, err := netip.ParsePrefix(aws.ToString(ipRange.CidrIp)); err == nil && prefix.Contains(peer.Ip) {
+				return aws.ToString(ipRange.CidrIp), nil
+			}
+		}
+		for _, ipRange := range permission.Ipv6Ranges {
+			if prefix, err := netip.ParsePrefix(aws.ToString(ipRange.CidrIpv6)); err == nil && prefix.Contains(peer.Ip) {
+				return aws.ToString(ipRange.CidrIpv6), nil
+			}
+		}
+		for _, prefixList := range permission.PrefixListIds {
+			cidrs, err := prefixListCidrs(aws.ToString(prefixList.PrefixListId))
+			if err != nil {
+				return "", err
+			}
+			for _, prefix := range cidrs {
+				if prefix.Contains(peer.Ip) {
+					return fmt.Sprintf("%s (%s)", aws.ToString(prefixList.PrefixListId), prefix), nil
+				}
+			}
+		}
+	}
+	for _, pair := range permission.UserIdGroupPairs {
+		for _, groupId := range peer.GroupIds {
+			if aws.ToString(pair.GroupId) == groupId {
+				return groupId, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// formatIpPermissionPorts describes the protocol and ports of a rule, e.g.
+// tcp/22 or all
+func formatIpPermissionPorts(protocol string, fromPort int32, toPort int32) string {
+	name := protocol
+	switch protocol {
+	case "-1":
+		return "all"
+	case "6":
+		name = "tcp"
+	case "17":
+		name = "udp"
+	case "1":
+		return "icmp"
+	case "58":
+		return "icmpv6"
+	}
+	if !ipProtocolHasPorts(protocol) {
+		return name
+	}
+	if fromPort == toPort {
+		return name + "/" + strconv.Itoa(int(fromPort))
+	}
+	return fmt.Sprintf("%s/%d-%d", name, fromPort, toPort)
+}
@@ -0,0 +1,234 @@
+package aws
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestMatchVpcRoutes(t *testing.T) {
+	prefixLists := map[string][]netip.Prefix{
+		"pl-1": {netip.MustParsePrefix("10.1.0.0/16")},
+		"pl-2": {netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("10.2.3.0/24")},
+	}
+	prefixListCidrs := func(id string) ([]netip.Prefix, error) {
+		if cidrs, ok := prefixLists[id]; ok {
+			return cidrs, nil
+		}
+		return nil, errors.New("prefix list not found: " + id)
+	}
+
+	cases := []struct {
+		name    string
+		routes  []types.Route
+		ip      string
+		targets []string
+		wantErr bool
+	}{
+		{
+			name: "longest prefix wins",
+			routes: []types.Route{
+				{DestinationCidrBlock: aws.String("0.0.0.0/0"), GatewayId: aws.String("igw-1")},
+				{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local")},
+				{DestinationCidrBlock: aws.String("10.0.1.0/24"), VpcPeeringConnectionId: aws.String("pcx-1")},
+			},
+			ip:      "10.0.1.5",
+			targets: []string{"pcx-1", "local", "igw-1"},
+		},
+		{
+			name: "same destination prefers local, static, prefix list, then propagated",
+			routes: []types.Route{
+				{DestinationCidrBlock: aws.String("10.1.0.0/16"), GatewayId: aws.String("vgw-1"), Origin: types.RouteOriginEnableVgwRoutePropagation},
+				{DestinationPrefixListId: aws.String("pl-1"), TransitGatewayId: aws.String("tgw-1")},
+				{DestinationCidrBlock: aws.String("10.1.0.0/16"), NatGatewayId: aws.String("nat-1")},
+				{DestinationCidrBlock: aws.String("10.1.0.0/16"), GatewayId: aws.String("local")},
+			},
+			ip:      "10.1.2.3",
+			targets: []string{"local", "nat-1", "tgw-1", "vgw-1"},
+		},
+		{
+			name: "prefix list matches with its most specific entry",
+			routes: []types.Route{
+				{DestinationCidrBlock: aws.String("10.2.0.0/16"), NatGatewayId: aws.String("nat-1")},
+				{DestinationPrefixListId: aws.String("pl-2"), TransitGatewayId: aws.String("tgw-1")},
+			},
+			ip:      "10.2.3.4",
+			targets: []string{"tgw-1", "nat-1"},
+		},
+		{
+			name: "IPv6 routes",
+			routes: []types.Route{
+				{DestinationIpv6CidrBlock: aws.String("::/0"), EgressOnlyInternetGatewayId: aws.String("eigw-1")},
+				{DestinationIpv6CidrBlock: aws.String("2001:db8::/56"), GatewayId: aws.String("local")},
+				{DestinationCidrBlock: aws.String("0.0.0.0/0"), GatewayId: aws.String("igw-1")},
+			},
+			ip:      "2001:db8::1",
+			targets: []string{"local", "eigw-1"},
+		},
+		{
+			name: "no matching route",
+			routes: []types.Route{
+				{DestinationCidrBlock: aws.String("10.0.0.0/8"), GatewayId: aws.String("local")},
+			},
+			ip: "192.168.0.1",
+		},
+		{
+			name: "prefix list lookup error",
+			routes: []types.Route{
+				{DestinationPrefixListId: aws.String("pl-missing"), TransitGatewayId: aws.String("tgw-1")},
+			},
+			ip:      "10.0.0.1",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			matches, err := matchVpcRoutes(tc.routes, netip.MustParseAddr(tc.ip), prefixListCidrs)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("matchVpcRoutes() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			var targets []string
+			for _, match := range matches {
+				targets = append(targets, vpcRouteTarget(match.Route))
+			}
+			if !reflect.DeepEqual(targets, tc.targets) {
+				t.Errorf("matchVpcRoutes() targets = %v, want %v", targets, tc.targets)
+			}
+		})
+	}
+}
+
+func TestEvaluateNetworkAcl(t *testing.T) {
+	entry := func(ruleNumber int32, egress bool, action types.RuleAction, protocol string, cidr string, from int32, to int32) types.NetworkAclEntry {
+		e := types.NetworkAclEntry{
+			RuleNumber: aws.Int32(ruleNumber),
+			Egress:     aws.Bool(egress),
+			RuleAction: action,
+			Protocol:   aws.String(protocol),
+			CidrBlock:  aws.String(cidr),
+		}
+		if protocol == "6" || protocol == "17" {
+			e.PortRange = &types.PortRange{From: aws.Int32(from), To: aws.Int32(to)}
+		}
+		return e
+	}
+
+	cases := []struct {
+		name         string
+		entries      []types.NetworkAclEntry
+		egress       bool
+		protocol     string
+		fromPort     int32
+		toPort       int32
+		peer         string
+		allowed      bool
+		deniedByRule int32
+	}{
+		{
+			name:     "allowed by an entry covering the port",
+			entries:  []types.NetworkAclEntry{entry(100, false, types.RuleActionAllow, "6", "0.0.0.0/0", 0, 65535)},
+			protocol: "6", fromPort: 80, toPort: 80, peer: "203.0.113.1",
+			allowed: true,
+		},
+		{
+			name: "lower rule number denies a port inside the range",
+			entries: []types.NetworkAclEntry{
+				entry(200, false, types.RuleActionAllow, "-1", "0.0.0.0/0", 0, 0),
+				entry(100, false, types.RuleActionDeny, "6", "0.0.0.0/0", 22, 22),
+			},
+			protocol: "6", fromPort: 20, toPort: 25, peer: "203.0.113.1",
+			deniedByRule: 100,
+		},
+		{
+			name:     "range extends past the allowed ports into the default deny",
+			entries:  []types.NetworkAclEntry{entry(100, false, types.RuleActionAllow, "6", "0.0.0.0/0", 1000, 2000)},
+			protocol: "6", fromPort: 1500, toPort: 2500, peer: "203.0.113.1",
+		},
+		{
+			name: "range split across adjacent allow entries",
+			entries: []types.NetworkAclEntry{
+				entry(100, false, types.RuleActionAllow, "6", "0.0.0.0/0", 1000, 2000),
+				entry(110, false, types.RuleActionAllow, "6", "0.0.0.0/0", 2001, 3000),
+			},
+			protocol: "6", fromPort: 1500, toPort: 2500, peer: "203.0.113.1",
+			allowed: true,
+		},
+		{
+			name: "entries of the other direction are ignored",
+			entries: []types.NetworkAclEntry{
+				entry(100, true, types.RuleActionDeny, "-1", "0.0.0.0/0", 0, 0),
+				entry(200, false, types.RuleActionAllow, "-1", "0.0.0.0/0", 0, 0),
+			},
+			protocol: "17", fromPort: 53, toPort: 53, peer: "203.0.113.1",
+			allowed: true,
+		},
+		{
+			name:     "peer outside the entry CIDR block",
+			entries:  []types.NetworkAclEntry{entry(100, false, types.RuleActionAllow, "-1", "10.0.0.0/8", 0, 0)},
+			protocol: "6", fromPort: 443, toPort: 443, peer: "192.168.1.1",
+		},
+		{
+			name:     "ports are not split for protocols without ports",
+			entries:  []types.NetworkAclEntry{entry(100, true, types.RuleActionAllow, "1", "0.0.0.0/0", 0, 0)},
+			egress:   true,
+			protocol: "1", fromPort: -1, toPort: -1, peer: "203.0.113.1",
+			allowed: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			allowed, denied := evaluateNetworkAcl(tc.entries, tc.egress, tc.protocol, tc.fromPort, tc.toPort, netip.MustParseAddr(tc.peer))
+			if allowed != tc.allowed {
+				t.Errorf("evaluateNetworkAcl() allowed = %v, want %v", allowed, tc.allowed)
+			}
+			var deniedByRule int32
+			if denied != nil {
+				deniedByRule = aws.ToInt32(denied.RuleNumber)
+			}
+			if deniedByRule != tc.deniedByRule {
+				t.Errorf("evaluateNetworkAcl() denied by rule %d, want %d", deniedByRule, tc.deniedByRule)
+			}
+		})
+	}
+}
+
+func TestSplitVpcReachabilityRange(t *testing.T) {
+	subnets := []netip.Prefix{
+		netip.MustParsePrefix("10.0.1.0/24"),
+		netip.MustParsePrefix("10.0.0.0/24"),
+		netip.MustParsePrefix("10.1.0.0/24"),
+		netip.MustParsePrefix("2600:1f18::/64"),
+	}
+
+	cases := []struct {
+		name  string
+		block string
+		want  []string
+	}{
+		{"block within a subnet", "10.0.1.16/28", []string{"10.0.1.16/28"}},
+		{"block equal to a subnet", "10.0.0.0/24", []string{"10.0.0.0/24"}},
+		{"block covering subnets", "10.0.0.0/16", []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/23"}},
+		{"block covering subnets from the start", "10.0.0.0/23", []string{"10.0.0.0/24", "10.0.1.0/24"}},
+		{"block outside the subnets", "192.168.0.0/16", []string{"192.168.0.0/16"}},
+		{"any address", "0.0.0.0/0", []string{"0.0.0.0/5", "10.0.0.0/24", "10.0.1.0/24", "10.1.0.0/24"}},
+		{"IPv6 block", "2600:1f18::/56", []string{"2600:1f18::/64", "2600:1f18:0:1::/64"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, prefix := range splitVpcReachabilityRange(netip.MustParsePrefix(tc.block), subnets) {
+				got = append(got, prefix.String())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("splitVpcReachabilityRange(%s) = %v, want %v", tc.block, got, tc.want)
+			}
+		})
+	}
+}